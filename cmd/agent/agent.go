@@ -0,0 +1,142 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/superedge/orin-device-system/pkg/agent/api"
+	"github.com/superedge/orin-device-system/pkg/agent/server"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"k8s.io/klog/v2"
+)
+
+var (
+	Version string // injected via ldflags at build time
+
+	listen        string
+	config        server.Config
+	healthCommand string
+	rebootCommand string
+	dlaLoadFiles  string
+	tokenFile     string
+	tlsCertFile   string
+	tlsKeyFile    string
+	clientCAFile  string
+)
+
+func InitFlag() {
+	flag.StringVar(&listen, "listen", fmt.Sprintf(":%d", api.DefaultPort), "address to serve soc agent api")
+	flag.StringVar(&config.BoardSerial, "board-serial", "", "serial number of the carrier board")
+	flag.IntVar(&config.SocIndex, "soc-index", 0, "index of this soc on the board")
+	flag.StringVar(&config.JetPackVersion, "jetpack-version", "", "jetpack version reported in identity")
+	flag.StringVar(&config.Workspace, "workspace", "", "directory cleared by clear-workspace command, soc is unhealthy if it is missing, empty means disable")
+	flag.StringVar(&healthCommand, "health-command", "", "space separated command, soc is unhealthy if it fails")
+	flag.StringVar(&rebootCommand, "reboot-command", "", "space separated command run by reboot command, e.g. 'systemctl reboot', empty means disable")
+	flag.StringVar(&config.ReleaseFile, "release-file", server.DefaultReleaseFile, "file to read L4T version from")
+	flag.StringVar(&config.GPULoadFile, "gpu-load-file", server.DefaultGPULoadFile, "sysfs file of gpu load in per mille")
+	flag.StringVar(&dlaLoadFiles, "dla-load-files", "", "comma separated name=path of dla load files in per mille, e.g. dla0=/sys/devices/platform/15880000.nvdla0/load")
+	flag.StringVar(&config.SysRoot, "sys-root", server.DefaultSysRoot, "sysfs root to read thermal zones and power monitors from")
+	flag.StringVar(&tokenFile, "token-file", "", "file of the shared token which authorizes execute, execute is disabled without token file or client ca file")
+	flag.StringVar(&tlsCertFile, "tls-cert-file", "", "certificate file to serve tls, empty means plaintext")
+	flag.StringVar(&tlsKeyFile, "tls-key-file", "", "private key file of tls-cert-file")
+	flag.StringVar(&clientCAFile, "client-ca-file", "", "ca file to verify client certificates, a verified client is authorized to execute, needs tls-cert-file")
+}
+
+// serverOptions return grpc options of tls and execute authorization
+func serverOptions() ([]grpc.ServerOption, error) {
+	auth := &server.ExecuteAuth{ClientCert: clientCAFile != ""}
+	if tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return nil, err
+		}
+		if auth.Token = strings.TrimSpace(string(data)); auth.Token == "" {
+			return nil, fmt.Errorf("token file %s is empty", tokenFile)
+		}
+	}
+	opts := []grpc.ServerOption{grpc.UnaryInterceptor(auth.Interceptor)}
+	if tlsCertFile == "" {
+		if clientCAFile != "" {
+			return nil, fmt.Errorf("client-ca-file needs tls-cert-file")
+		}
+		if auth.Token != "" {
+			klog.InfoS("token is sent in plaintext, set tls-cert-file to serve tls")
+		}
+		return opts, nil
+	}
+	cert, err := tls.LoadX509KeyPair(tlsCertFile, tlsKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	if clientCAFile != "" {
+		data, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificate found in %s", clientCAFile)
+		}
+		// clients without certificate may still read identity and status
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return append(opts, grpc.Creds(credentials.NewTLS(tlsConfig))), nil
+}
+
+func main() {
+	InitFlag()
+	klog.InitFlags(nil)
+	flag.Parse()
+	defer klog.Flush()
+
+	config.AgentVersion = Version
+	config.HealthCommand = strings.Fields(healthCommand)
+	config.RebootCommand = strings.Fields(rebootCommand)
+	config.DLALoadFiles = make(map[string]string)
+	for _, kv := range strings.Split(dlaLoadFiles, ",") {
+		if kv == "" {
+			continue
+		}
+		name, path := kv, ""
+		if i := strings.Index(kv, "="); i > 0 {
+			name, path = kv[:i], kv[i+1:]
+		}
+		if path == "" {
+			klog.Fatalf("invalid dla load file %q, expect name=path", kv)
+		}
+		config.DLALoadFiles[name] = path
+	}
+
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		klog.Fatal(err)
+	}
+	opts, err := serverOptions()
+	if err != nil {
+		klog.Fatal(err)
+	}
+	s := grpc.NewServer(opts...)
+	api.RegisterAgentServer(s, server.New(&config))
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		<-ch
+		s.GracefulStop()
+	}()
+	klog.InfoS("soc agent serving", "address", listen, "board", config.BoardSerial, "soc", config.SocIndex)
+	if err := s.Serve(listener); err != nil {
+		klog.Fatal(err)
+	}
+}
@@ -1,19 +1,27 @@
 package main
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"flag"
+	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
-	v1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/klog/v2"
 
+	"github.com/superedge/orin-device-system/pkg/agent/api"
+	"github.com/superedge/orin-device-system/pkg/device/checkpoint"
+	"github.com/superedge/orin-device-system/pkg/device/hook"
 	"github.com/superedge/orin-device-system/pkg/device/kubeapis"
+	"github.com/superedge/orin-device-system/pkg/device/metrics"
 	"github.com/superedge/orin-device-system/pkg/device/plugin"
 	"github.com/superedge/orin-device-system/pkg/device/provider"
 )
@@ -23,6 +31,26 @@ var (
 	nodeName             string
 	deviceProvider       string
 	deviceProviderConfig string
+	cleanupOnExit        bool
+	metricsPort          int
+	debugAddress         string
+	checkpointPath       string
+	deviceLocator        string
+	fallbackLocator      string
+	podLookup            plugin.PodLookupConfig
+	auditPeriod          time.Duration
+	apiSocket            string
+	hookConfig           string
+	powerIdleTimeout     time.Duration
+	agentPort            int
+	agentPeriod          time.Duration
+	agentCAFile          string
+	telemetry            plugin.TelemetryConfig
+)
+
+const (
+	cleanupCommand       = "cleanup"
+	debugAllocationsPath = "/debug/allocations"
 )
 
 func InitFlag() {
@@ -30,6 +58,26 @@ func InitFlag() {
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "kubeconfig path")
 	flag.StringVar(&deviceProvider, "provider", "file", "device provider current support 'file'")
 	flag.StringVar(&deviceProviderConfig, "provider-config", "", "device provider config file path")
+	flag.IntVar(&metricsPort, "metrics-port", 9410, "port to serve /metrics, /healthz and /readyz, 0 means disable")
+	flag.StringVar(&debugAddress, "debug-address", "127.0.0.1:9411", "address to serve "+debugAllocationsPath+", it lists every pod of the node, empty means disable")
+	flag.StringVar(&checkpointPath, "checkpoint-path", checkpoint.DefaultCheckpointPath, "node local allocation checkpoint file path, empty means disable")
+	flag.DurationVar(&powerIdleTimeout, "power-idle-timeout", 0, "how long a board stays idle before powered off by provider power control, 0 means disable")
+	flag.IntVar(&agentPort, "agent-port", 0, fmt.Sprintf("port of agent on every soc ip for identity and health, agent listens on %d by default, 0 means disable", api.DefaultPort))
+	flag.DurationVar(&agentPeriod, "agent-period", plugin.DefaultAgentPeriod, "period of polling soc agents")
+	flag.StringVar(&agentCAFile, "agent-ca-file", "", "ca file to verify agents serving tls, empty means plaintext")
+	flag.DurationVar(&telemetry.TelemetryPeriod, "telemetry-period", 0, fmt.Sprintf("period of collecting soc telemetry, e.g. %v, 0 means disable", plugin.DefaultTelemetryPeriod))
+	flag.StringVar(&telemetry.TelemetrySource, "telemetry-source", plugin.TelemetrySourceAgent, "where to collect soc telemetry, 'agent' needs agent-port, 'http' needs telemetry-url")
+	flag.StringVar(&telemetry.TelemetryURL, "telemetry-url", "", "http endpoint of soc telemetry json, {ip} is replaced by soc ip, e.g. http://{ip}:9421/telemetry")
+	flag.BoolVar(&telemetry.TelemetryAnnotation, "telemetry-annotation", false, "publish compact soc telemetry summary to node annotation for the scheduler")
+	flag.StringVar(&deviceLocator, "device-locator", kubeapis.LocatorPodResources, "how to find pod of allocated devices, 'podresources', 'podresources-v1alpha1', 'podresources-direct' or 'checkpoint'")
+	flag.StringVar(&fallbackLocator, "fallback-device-locator", kubeapis.LocatorCheckpoint, "locator used when device-locator failed, empty means no fallback")
+	flag.DurationVar(&podLookup.PodWaitTimeout, "pod-wait-timeout", plugin.DefaultPodWaitTimeout, "how long PreStartContainer re-reads informer for pod bind annotation, negative means no retry")
+	flag.DurationVar(&podLookup.PodWaitInterval, "pod-wait-interval", plugin.DefaultPodWaitInterval, "interval of re-reading informer for pod bind annotation")
+	flag.DurationVar(&podLookup.APIServerTimeout, "apiserver-timeout", plugin.DefaultAPIServerTimeout, "timeout of reading pod from api server after informer retry, negative means never read api server")
+	flag.DurationVar(&auditPeriod, "audit-period", plugin.DefaultAuditPeriod, "period of comparing kubelet allocations with pod bind annotations and provider inventory, 0 means disable")
+	flag.StringVar(&apiSocket, "api-socket", plugin.DefaultAPISocket, "node local api socket for pods to query their allocation, empty means disable")
+	flag.StringVar(&hookConfig, "hook-config", "", "lifecycle hooks config file run on soc allocation and release, empty means disable")
+	flag.BoolVar(&cleanupOnExit, "cleanup-on-exit", false, "remove orin extra resources and plugin sockets from node when plugin exit, injected configs are kept for running pods")
 
 }
 
@@ -65,7 +113,14 @@ func ExitSignal() <-chan os.Signal {
 func main() {
 	InitFlag()
 	klog.InitFlags(nil)
-	flag.Parse()
+	// support both `orin-device-plugin cleanup --flags` and `orin-device-plugin --flags cleanup`
+	args := os.Args[1:]
+	cleanup := len(args) > 0 && args[0] == cleanupCommand
+	if cleanup {
+		args = args[1:]
+	}
+	flag.CommandLine.Parse(args)
+	cleanup = cleanup || flag.Arg(0) == cleanupCommand
 	defer klog.Flush()
 
 	clientSet, err := BuildClientSet(kubeconfig)
@@ -73,6 +128,14 @@ func main() {
 		klog.Fatal(err.Error())
 		return
 	}
+	// orin-device-plugin cleanup, uninstall orin device from node and exit
+	if cleanup {
+		if err := plugin.Cleanup(clientSet, nodeName, true, checkpointPath); err != nil {
+			klog.Fatalln(err.Error())
+		}
+		klog.InfoS("cleanup orin device on node", "node", nodeName)
+		return
+	}
 	sitter := kubeapis.NewSitter(clientSet, nodeName)
 	go sitter.Start()
 
@@ -93,19 +156,101 @@ func main() {
 		klog.Fatalln("failed to create device provider")
 		return
 	}
+	var store *checkpoint.Store
+	if checkpointPath != "" {
+		if store, err = checkpoint.NewStore(checkpointPath); err != nil {
+			klog.Fatalln(err.Error())
+			return
+		}
+	}
+	locator, err := kubeapis.NewDeviceLocator(deviceLocator, sitter)
+	if err != nil {
+		klog.Fatalln(err.Error())
+		return
+	}
+	if fallbackLocator != "" && fallbackLocator != deviceLocator {
+		fallback, err := kubeapis.NewDeviceLocator(fallbackLocator, sitter)
+		if err != nil {
+			klog.Fatalln(err.Error())
+			return
+		}
+		locator = &kubeapis.FallbackDeviceLocator{Primary: locator, Fallback: fallback}
+	}
+	var hooks *hook.Config
+	if hookConfig != "" {
+		if hooks, err = hook.Load(hookConfig); err != nil {
+			klog.Fatalln(err.Error())
+			return
+		}
+	}
+	var agentTLS *tls.Config
+	if agentCAFile != "" {
+		data, err := os.ReadFile(agentCAFile)
+		if err != nil {
+			klog.Fatalln(err.Error())
+			return
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			klog.Fatalf("no certificate found in %s", agentCAFile)
+			return
+		}
+		agentTLS = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
 	odc := &plugin.OrinDeviceConfig{
-		Sitter:         sitter,
-		DeviceProvider: p,
-		DeviceLocator:  make(map[v1.ResourceName]kubeapis.DeviceLocator),
-		NodeName:       nodeName,
-		ClientSet:      clientSet,
+		Sitter:           sitter,
+		DeviceProvider:   p,
+		DeviceLocator:    locator,
+		NodeName:         nodeName,
+		ClientSet:        clientSet,
+		Recorder:         kubeapis.NewEventRecorder(clientSet, "orin-device-plugin", nodeName),
+		Checkpoint:       store,
+		PodLookupConfig:  podLookup,
+		AuditPeriod:      auditPeriod,
+		Hooks:            hooks,
+		PowerIdleTimeout: powerIdleTimeout,
+		AgentPort:        agentPort,
+		AgentPeriod:      agentPeriod,
+		AgentTLS:         agentTLS,
+		TelemetryConfig:  telemetry,
 	}
 	plug, err := plugin.NewOrinDevicePlugin(odc)
 	if err != nil {
 		klog.Fatalln(err.Error())
 		return
 	}
-	plug.Run(make(chan struct{}))
+	stop := make(chan struct{})
+	plug.Run(stop)
+	if metricsPort != 0 {
+		go func() {
+			if err := metrics.Serve(metricsPort, plug.Ready); err != nil {
+				klog.ErrorS(err, "metrics server exit")
+			}
+		}()
+	}
+	if debugAddress != "" {
+		go func() {
+			if err := metrics.ServeDebug(debugAddress, map[string]http.HandlerFunc{
+				debugAllocationsPath: plug.ServeAllocations,
+			}); err != nil {
+				klog.ErrorS(err, "debug server exit")
+			}
+		}()
+	}
+	if apiSocket != "" {
+		go func() {
+			if err := plug.ServeNodeAPI(apiSocket, stop); err != nil {
+				klog.ErrorS(err, "node api server exit")
+			}
+		}()
+	}
 	klog.Info("start to run orin device plugin")
 	<-ExitSignal()
+	close(stop)
+	if cleanupOnExit {
+		// keep configs of pods still running on node
+		if err := plugin.Cleanup(clientSet, nodeName, false, ""); err != nil {
+			klog.ErrorS(err, "cleanup orin device on exit error", "node", nodeName)
+		}
+	}
 }
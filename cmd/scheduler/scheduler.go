@@ -7,8 +7,10 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/superedge/orin-device-system/pkg/scheduler/leader"
 	"github.com/superedge/orin-device-system/pkg/scheduler/manager"
 	"github.com/superedge/orin-device-system/pkg/scheduler/routes"
 
@@ -23,9 +25,16 @@ const (
 )
 
 var (
-	kubeconfig string
-	port       int
-	threadness int
+	kubeconfig    string
+	port          int
+	threadness    int
+	defaultPolicy string
+	groupTimeout  time.Duration
+	assumeTTL     time.Duration
+
+	leaderElect          bool
+	leaderElectNamespace string
+	leaderElectName      string
 )
 
 var (
@@ -39,6 +48,12 @@ func InitFlag() {
 	flag.StringVar(&kubeconfig, "kubeconfig", "", "path to kubeconfig")
 	flag.IntVar(&port, "port", 80, "port to orin extend scheduler")
 	flag.IntVar(&threadness, "threadness", 4, "thread for cache controller")
+	flag.StringVar(&defaultPolicy, "default-policy", manager.AllocatorPolicyBinPack, "allocation policy of pods without policy annotation on pod or namespace, name or name@version")
+	flag.DurationVar(&groupTimeout, "group-timeout", manager.DefaultGroupTimeout, "how long members of a pod group are waited for and orins are reserved for them")
+	flag.DurationVar(&assumeTTL, "assume-ttl", manager.DefaultAssumeTTL, "how long orins of a bound pod are held before informer sees it bound")
+	flag.BoolVar(&leaderElect, "leader-elect", false, "elect a leader among replicas with a lease, only the leader binds pods and is ready")
+	flag.StringVar(&leaderElectNamespace, "leader-elect-namespace", "kube-system", "namespace of the leader election lease")
+	flag.StringVar(&leaderElectName, "leader-elect-name", "orin-device-scheduler", "name of the leader election lease")
 }
 
 func main() {
@@ -57,23 +72,53 @@ func main() {
 	AddVersion(router)
 
 	scache := manager.NewScheduleCache()
+	scache.AssumeTTL = assumeTTL
 
 	mng := manager.NewManager(scache, clientset)
+	if err := mng.Policies.SetDefault(defaultPolicy); err != nil {
+		klog.Fatalf("invalid default policy: %v", err)
+	}
+	recorder := manager.NewEventRecorder(clientset)
+	mng.Recorder = recorder
+	mng.GroupTimeout = groupTimeout
 
 	routes.AddPredicate(router, routes.NewPredicate("orin-system", mng))
 
 	routes.AddPrioritize(router, routes.NewPrioritize("orin-system", mng))
 
-	routes.AddBind(router, routes.NewBind("orin-system", mng))
+	bind := routes.NewBind("orin-system", mng)
+	ready := func() error { return nil }
+	var elector *leader.Elector
+	if leaderElect {
+		// identity is pod name in cluster
+		identity, err := os.Hostname()
+		if err != nil {
+			klog.Fatalf("failed to get leader election identity: %v", err)
+		}
+		elector, err = leader.NewElector(clientset, leaderElectNamespace, leaderElectName, identity)
+		if err != nil {
+			klog.Fatalf("failed to init leader election: %v", err)
+		}
+		bind.IsLeader = elector.IsLeader
+		ready = elector.Ready
+	}
+	routes.AddBind(router, bind)
+
+	routes.AddPreempt(router, routes.NewPreempt("orin-system", mng))
 
 	// build controller
 	stopCh := SetupSignalHandler()
 
-	controller, err := manager.NewController(clientset, mng, stopCh)
+	controller, err := manager.NewController(clientset, mng, recorder, stopCh)
 	if err != nil {
 		klog.Fatal(err)
 	}
 	go controller.Run(threadness, stopCh)
+	if elector != nil {
+		// campaign after caches synced, standbys keep them warm
+		go elector.Run(stopCh)
+	}
+	routes.AddHealth(router, ready)
 
 	klog.Infof("info: server starting on the port :%d", port)
 	if err := http.ListenAndServe(fmt.Sprintf(":%d", port), router); err != nil {
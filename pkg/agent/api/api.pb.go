@@ -0,0 +1,2751 @@
+// Code generated by protoc-gen-gogo. DO NOT EDIT.
+// source: api.proto
+
+package api
+
+import (
+	context "context"
+	encoding_binary "encoding/binary"
+	fmt "fmt"
+	_ "github.com/gogo/protobuf/gogoproto"
+	proto "github.com/gogo/protobuf/proto"
+	github_com_gogo_protobuf_sortkeys "github.com/gogo/protobuf/sortkeys"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	io "io"
+	math "math"
+	math_bits "math/bits"
+	reflect "reflect"
+	strings "strings"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
+
+type Empty struct {
+}
+
+func (m *Empty) Reset()      { *m = Empty{} }
+func (*Empty) ProtoMessage() {}
+func (*Empty) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{0}
+}
+func (m *Empty) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Empty) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Empty.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Empty) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Empty.Merge(m, src)
+}
+func (m *Empty) XXX_Size() int {
+	return m.Size()
+}
+func (m *Empty) XXX_DiscardUnknown() {
+	xxx_messageInfo_Empty.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Empty proto.InternalMessageInfo
+
+// Identity is who the soc is
+type Identity struct {
+	BoardSerial    string `protobuf:"bytes,1,opt,name=board_serial,json=boardSerial,proto3" json:"boardSerial"`
+	SocIndex       int32  `protobuf:"varint,2,opt,name=soc_index,json=socIndex,proto3" json:"socIndex"`
+	Hostname       string `protobuf:"bytes,3,opt,name=hostname,proto3" json:"hostname"`
+	L4TVersion     string `protobuf:"bytes,4,opt,name=l4t_version,json=l4tVersion,proto3" json:"l4tVersion,omitempty"`
+	JetPackVersion string `protobuf:"bytes,5,opt,name=jetpack_version,json=jetpackVersion,proto3" json:"jetpackVersion,omitempty"`
+	AgentVersion   string `protobuf:"bytes,6,opt,name=agent_version,json=agentVersion,proto3" json:"agentVersion,omitempty"`
+}
+
+func (m *Identity) Reset()      { *m = Identity{} }
+func (*Identity) ProtoMessage() {}
+func (*Identity) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{1}
+}
+func (m *Identity) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Identity) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Identity.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Identity) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Identity.Merge(m, src)
+}
+func (m *Identity) XXX_Size() int {
+	return m.Size()
+}
+func (m *Identity) XXX_DiscardUnknown() {
+	xxx_messageInfo_Identity.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Identity proto.InternalMessageInfo
+
+func (m *Identity) GetBoardSerial() string {
+	if m != nil {
+		return m.BoardSerial
+	}
+	return ""
+}
+
+func (m *Identity) GetSocIndex() int32 {
+	if m != nil {
+		return m.SocIndex
+	}
+	return 0
+}
+
+func (m *Identity) GetHostname() string {
+	if m != nil {
+		return m.Hostname
+	}
+	return ""
+}
+
+func (m *Identity) GetL4TVersion() string {
+	if m != nil {
+		return m.L4TVersion
+	}
+	return ""
+}
+
+func (m *Identity) GetJetPackVersion() string {
+	if m != nil {
+		return m.JetPackVersion
+	}
+	return ""
+}
+
+func (m *Identity) GetAgentVersion() string {
+	if m != nil {
+		return m.AgentVersion
+	}
+	return ""
+}
+
+// Utilization is the resource usage of soc, percents are 0 to 100
+type Utilization struct {
+	CPUPercent       float64 `protobuf:"fixed64,1,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpuPercent"`
+	GPUPercent       float64 `protobuf:"fixed64,2,opt,name=gpu_percent,json=gpuPercent,proto3" json:"gpuPercent"`
+	MemoryUsedBytes  uint64  `protobuf:"varint,3,opt,name=memory_used_bytes,json=memoryUsedBytes,proto3" json:"memoryUsedBytes"`
+	MemoryTotalBytes uint64  `protobuf:"varint,4,opt,name=memory_total_bytes,json=memoryTotalBytes,proto3" json:"memoryTotalBytes"`
+}
+
+func (m *Utilization) Reset()      { *m = Utilization{} }
+func (*Utilization) ProtoMessage() {}
+func (*Utilization) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{2}
+}
+func (m *Utilization) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Utilization) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Utilization.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Utilization) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Utilization.Merge(m, src)
+}
+func (m *Utilization) XXX_Size() int {
+	return m.Size()
+}
+func (m *Utilization) XXX_DiscardUnknown() {
+	xxx_messageInfo_Utilization.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Utilization proto.InternalMessageInfo
+
+func (m *Utilization) GetCPUPercent() float64 {
+	if m != nil {
+		return m.CPUPercent
+	}
+	return 0
+}
+
+func (m *Utilization) GetGPUPercent() float64 {
+	if m != nil {
+		return m.GPUPercent
+	}
+	return 0
+}
+
+func (m *Utilization) GetMemoryUsedBytes() uint64 {
+	if m != nil {
+		return m.MemoryUsedBytes
+	}
+	return 0
+}
+
+func (m *Utilization) GetMemoryTotalBytes() uint64 {
+	if m != nil {
+		return m.MemoryTotalBytes
+	}
+	return 0
+}
+
+// Status is the health and utilization of soc
+type Status struct {
+	Healthy bool `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy"`
+	// Message tell why soc is unhealthy
+	Message     string       `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Utilization *Utilization `protobuf:"bytes,3,opt,name=utilization,proto3" json:"utilization,omitempty"`
+}
+
+func (m *Status) Reset()      { *m = Status{} }
+func (*Status) ProtoMessage() {}
+func (*Status) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{3}
+}
+func (m *Status) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Status) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Status.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Status) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Status.Merge(m, src)
+}
+func (m *Status) XXX_Size() int {
+	return m.Size()
+}
+func (m *Status) XXX_DiscardUnknown() {
+	xxx_messageInfo_Status.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Status proto.InternalMessageInfo
+
+func (m *Status) GetHealthy() bool {
+	if m != nil {
+		return m.Healthy
+	}
+	return false
+}
+
+func (m *Status) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func (m *Status) GetUtilization() *Utilization {
+	if m != nil {
+		return m.Utilization
+	}
+	return nil
+}
+
+// Telemetry is the utilization, temperature and power of soc like tegrastats
+// reports, it is also the body of http telemetry endpoints
+type Telemetry struct {
+	CPUPercent float64 `protobuf:"fixed64,1,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpuPercent"`
+	GPUPercent float64 `protobuf:"fixed64,2,opt,name=gpu_percent,json=gpuPercent,proto3" json:"gpuPercent"`
+	// DLAPercent is utilization of every dla engine, e.g. dla0
+	DLAPercent       map[string]float64 `protobuf:"bytes,3,rep,name=dla_percent,json=dlaPercent,proto3" json:"dlaPercent,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+	MemoryUsedBytes  uint64             `protobuf:"varint,4,opt,name=memory_used_bytes,json=memoryUsedBytes,proto3" json:"memoryUsedBytes"`
+	MemoryTotalBytes uint64             `protobuf:"varint,5,opt,name=memory_total_bytes,json=memoryTotalBytes,proto3" json:"memoryTotalBytes"`
+	// TemperatureCelsius is temperature of every thermal zone, e.g. gpu-thermal
+	TemperatureCelsius map[string]float64 `protobuf:"bytes,6,rep,name=temperature_celsius,json=temperatureCelsius,proto3" json:"temperatureCelsius,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+	// PowerMilliwatts is power of every rail, e.g. VDD_GPU_SOC
+	PowerMilliwatts map[string]float64 `protobuf:"bytes,7,rep,name=power_milliwatts,json=powerMilliwatts,proto3" json:"powerMilliwatts,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"fixed64,2,opt,name=value,proto3"`
+}
+
+func (m *Telemetry) Reset()      { *m = Telemetry{} }
+func (*Telemetry) ProtoMessage() {}
+func (*Telemetry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{4}
+}
+func (m *Telemetry) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *Telemetry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_Telemetry.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *Telemetry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Telemetry.Merge(m, src)
+}
+func (m *Telemetry) XXX_Size() int {
+	return m.Size()
+}
+func (m *Telemetry) XXX_DiscardUnknown() {
+	xxx_messageInfo_Telemetry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Telemetry proto.InternalMessageInfo
+
+func (m *Telemetry) GetCPUPercent() float64 {
+	if m != nil {
+		return m.CPUPercent
+	}
+	return 0
+}
+
+func (m *Telemetry) GetGPUPercent() float64 {
+	if m != nil {
+		return m.GPUPercent
+	}
+	return 0
+}
+
+func (m *Telemetry) GetDLAPercent() map[string]float64 {
+	if m != nil {
+		return m.DLAPercent
+	}
+	return nil
+}
+
+func (m *Telemetry) GetMemoryUsedBytes() uint64 {
+	if m != nil {
+		return m.MemoryUsedBytes
+	}
+	return 0
+}
+
+func (m *Telemetry) GetMemoryTotalBytes() uint64 {
+	if m != nil {
+		return m.MemoryTotalBytes
+	}
+	return 0
+}
+
+func (m *Telemetry) GetTemperatureCelsius() map[string]float64 {
+	if m != nil {
+		return m.TemperatureCelsius
+	}
+	return nil
+}
+
+func (m *Telemetry) GetPowerMilliwatts() map[string]float64 {
+	if m != nil {
+		return m.PowerMilliwatts
+	}
+	return nil
+}
+
+type CommandRequest struct {
+	Name string            `protobuf:"bytes,1,opt,name=name,proto3" json:"name"`
+	Args map[string]string `protobuf:"bytes,2,rep,name=args,proto3" json:"args,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+}
+
+func (m *CommandRequest) Reset()      { *m = CommandRequest{} }
+func (*CommandRequest) ProtoMessage() {}
+func (*CommandRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{5}
+}
+func (m *CommandRequest) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CommandRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CommandRequest.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CommandRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommandRequest.Merge(m, src)
+}
+func (m *CommandRequest) XXX_Size() int {
+	return m.Size()
+}
+func (m *CommandRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommandRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommandRequest proto.InternalMessageInfo
+
+func (m *CommandRequest) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *CommandRequest) GetArgs() map[string]string {
+	if m != nil {
+		return m.Args
+	}
+	return nil
+}
+
+type CommandResponse struct {
+	Output string `protobuf:"bytes,1,opt,name=output,proto3" json:"output,omitempty"`
+}
+
+func (m *CommandResponse) Reset()      { *m = CommandResponse{} }
+func (*CommandResponse) ProtoMessage() {}
+func (*CommandResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_00212fb1f9d3bf1c, []int{6}
+}
+func (m *CommandResponse) XXX_Unmarshal(b []byte) error {
+	return m.Unmarshal(b)
+}
+func (m *CommandResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	if deterministic {
+		return xxx_messageInfo_CommandResponse.Marshal(b, m, deterministic)
+	} else {
+		b = b[:cap(b)]
+		n, err := m.MarshalToSizedBuffer(b)
+		if err != nil {
+			return nil, err
+		}
+		return b[:n], nil
+	}
+}
+func (m *CommandResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CommandResponse.Merge(m, src)
+}
+func (m *CommandResponse) XXX_Size() int {
+	return m.Size()
+}
+func (m *CommandResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_CommandResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CommandResponse proto.InternalMessageInfo
+
+func (m *CommandResponse) GetOutput() string {
+	if m != nil {
+		return m.Output
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*Empty)(nil), "orin.agent.v1.Empty")
+	proto.RegisterType((*Identity)(nil), "orin.agent.v1.Identity")
+	proto.RegisterType((*Utilization)(nil), "orin.agent.v1.Utilization")
+	proto.RegisterType((*Status)(nil), "orin.agent.v1.Status")
+	proto.RegisterType((*Telemetry)(nil), "orin.agent.v1.Telemetry")
+	proto.RegisterMapType((map[string]float64)(nil), "orin.agent.v1.Telemetry.DlaPercentEntry")
+	proto.RegisterMapType((map[string]float64)(nil), "orin.agent.v1.Telemetry.PowerMilliwattsEntry")
+	proto.RegisterMapType((map[string]float64)(nil), "orin.agent.v1.Telemetry.TemperatureCelsiusEntry")
+	proto.RegisterType((*CommandRequest)(nil), "orin.agent.v1.CommandRequest")
+	proto.RegisterMapType((map[string]string)(nil), "orin.agent.v1.CommandRequest.ArgsEntry")
+	proto.RegisterType((*CommandResponse)(nil), "orin.agent.v1.CommandResponse")
+}
+
+func init() { proto.RegisterFile("api.proto", fileDescriptor_00212fb1f9d3bf1c) }
+
+var fileDescriptor_00212fb1f9d3bf1c = []byte{
+	// 938 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x56, 0xcb, 0x6e, 0xdb, 0x46,
+	0x14, 0x15, 0x65, 0x3d, 0x2f, 0x1d, 0xcb, 0x99, 0xc8, 0x0d, 0x23, 0x38, 0xa4, 0x41, 0xa0, 0xad,
+	0x02, 0xa4, 0x4a, 0xeb, 0x1a, 0x68, 0x11, 0x20, 0x75, 0x4d, 0xc7, 0x10, 0x12, 0xa4, 0xa8, 0xc1,
+	0xd8, 0x0d, 0xd0, 0x8d, 0x30, 0xa6, 0x06, 0x32, 0x6b, 0xbe, 0xc2, 0x19, 0xda, 0x51, 0x57, 0x05,
+	0xfa, 0x03, 0xe9, 0xdf, 0xb4, 0x7f, 0x10, 0x74, 0x95, 0x65, 0x56, 0x44, 0x23, 0xef, 0xf8, 0x01,
+	0x5d, 0x17, 0x1c, 0x52, 0x24, 0x25, 0x59, 0x2d, 0x8c, 0x2e, 0xb2, 0x11, 0x66, 0xce, 0xbd, 0xe7,
+	0xcc, 0xbd, 0x67, 0x66, 0x38, 0x82, 0x26, 0xf6, 0xcc, 0x9e, 0xe7, 0xbb, 0xcc, 0x45, 0x37, 0x5c,
+	0xdf, 0x74, 0x7a, 0x78, 0x44, 0x1c, 0xd6, 0x3b, 0xff, 0xa2, 0xd3, 0x1e, 0xb9, 0x23, 0x97, 0x47,
+	0x1e, 0xc4, 0xa3, 0x24, 0x49, 0xad, 0x43, 0xf5, 0xc0, 0xf6, 0xd8, 0x58, 0xfd, 0xbb, 0x0c, 0x8d,
+	0x27, 0x43, 0xe2, 0x30, 0x93, 0x8d, 0xd1, 0x36, 0xac, 0x9e, 0xb8, 0xd8, 0x1f, 0x0e, 0x28, 0xf1,
+	0x4d, 0x6c, 0x49, 0xc2, 0x96, 0xd0, 0x6d, 0x6a, 0xad, 0x28, 0x54, 0x44, 0x8e, 0x3f, 0xe7, 0xb0,
+	0x5e, 0x9c, 0xa0, 0x7b, 0xd0, 0xa4, 0xae, 0x31, 0x30, 0x9d, 0x21, 0x79, 0x25, 0x95, 0xb7, 0x84,
+	0x6e, 0x55, 0x5b, 0x8d, 0x42, 0xa5, 0x41, 0x5d, 0xe3, 0x49, 0x8c, 0xe9, 0xd9, 0x08, 0x75, 0xa1,
+	0x71, 0xea, 0x52, 0xe6, 0x60, 0x9b, 0x48, 0x2b, 0x5c, 0x9a, 0x67, 0x4e, 0x31, 0x3d, 0x1b, 0xa1,
+	0x3e, 0x88, 0xd6, 0x0e, 0x1b, 0x9c, 0x13, 0x9f, 0x9a, 0xae, 0x23, 0x55, 0x78, 0xf2, 0x27, 0x93,
+	0x50, 0x81, 0x67, 0x3b, 0x47, 0x3f, 0x24, 0x68, 0x14, 0x2a, 0x6d, 0x6b, 0x87, 0xa5, 0xb3, 0xfb,
+	0xae, 0x6d, 0x32, 0x12, 0xb7, 0xa4, 0x43, 0x8e, 0xa2, 0x17, 0xd0, 0xfa, 0x89, 0x30, 0x0f, 0x1b,
+	0x67, 0x99, 0x58, 0x95, 0x8b, 0xf5, 0x26, 0xa1, 0xb2, 0xf6, 0x94, 0xb0, 0x43, 0x6c, 0x9c, 0xe5,
+	0x82, 0x52, 0x9a, 0xbc, 0x28, 0xba, 0x36, 0x1b, 0x41, 0xbb, 0x70, 0x83, 0x5b, 0x9c, 0xc9, 0xd6,
+	0xb8, 0x6c, 0x27, 0x0a, 0x95, 0x8f, 0x78, 0x60, 0x51, 0x62, 0xb5, 0x88, 0xab, 0xaf, 0xcb, 0x20,
+	0x1e, 0x33, 0xd3, 0x32, 0x7f, 0xc6, 0x2c, 0x16, 0x7c, 0x04, 0xa2, 0xe1, 0x05, 0x03, 0x8f, 0xf8,
+	0x06, 0x71, 0x18, 0xb7, 0x5e, 0xd0, 0x36, 0xe3, 0x96, 0xf7, 0x0f, 0x8f, 0x0f, 0x13, 0x34, 0x0a,
+	0x15, 0x30, 0xbc, 0x20, 0x9d, 0xe9, 0x85, 0x71, 0x4c, 0x1f, 0x15, 0xe8, 0xe5, 0x9c, 0xde, 0x9f,
+	0xa1, 0x8f, 0x0a, 0xf4, 0x7c, 0x8c, 0x76, 0xe1, 0xa6, 0x4d, 0x6c, 0xd7, 0x1f, 0x0f, 0x02, 0x4a,
+	0x86, 0x83, 0x93, 0x31, 0x23, 0x94, 0xef, 0x51, 0x45, 0xbb, 0x15, 0x85, 0x4a, 0x2b, 0x09, 0x1e,
+	0x53, 0x32, 0xd4, 0xe2, 0x90, 0x3e, 0x0f, 0x20, 0x0d, 0x50, 0x2a, 0xc0, 0x5c, 0x86, 0xad, 0x54,
+	0xa1, 0xc2, 0x15, 0xda, 0x51, 0xa8, 0xac, 0x27, 0xd1, 0xa3, 0x38, 0x98, 0x48, 0x2c, 0x20, 0xea,
+	0x1f, 0x02, 0xd4, 0x9e, 0x33, 0xcc, 0x02, 0x8a, 0x3e, 0x86, 0xfa, 0x29, 0xc1, 0x16, 0x3b, 0x1d,
+	0x73, 0x27, 0x1a, 0x9a, 0x18, 0x85, 0xca, 0x14, 0xd2, 0xa7, 0x03, 0xf4, 0x00, 0xea, 0x36, 0xa1,
+	0x14, 0x8f, 0x08, 0xef, 0xb8, 0xa9, 0x6d, 0x44, 0xa1, 0x72, 0x33, 0x85, 0x0a, 0xd6, 0x4f, 0xb3,
+	0xd0, 0x0b, 0x10, 0x83, 0xdc, 0x74, 0xde, 0xa1, 0xb8, 0xdd, 0xe9, 0xcd, 0x5c, 0x99, 0x5e, 0x61,
+	0x5b, 0xb4, 0x3b, 0x51, 0xa8, 0x6c, 0x14, 0x28, 0x05, 0xd1, 0xa2, 0x92, 0xfa, 0x67, 0x0d, 0x9a,
+	0x47, 0xc4, 0x22, 0x36, 0x61, 0xfe, 0xf8, 0x03, 0x6f, 0xe6, 0x4b, 0x10, 0x87, 0x16, 0xce, 0xe8,
+	0x2b, 0x5b, 0x2b, 0x5d, 0x71, 0xbb, 0x3b, 0xd7, 0x64, 0x56, 0x6c, 0xef, 0xb1, 0x85, 0x53, 0xe6,
+	0x81, 0xc3, 0xfc, 0x71, 0x72, 0xcf, 0x1e, 0x3f, 0xdb, 0xcb, 0x17, 0x6a, 0x0f, 0xb3, 0x94, 0xe2,
+	0x3d, 0xcb, 0xd1, 0xab, 0xcf, 0x4f, 0xe5, 0x7f, 0x9f, 0x9f, 0xea, 0x75, 0xce, 0x0f, 0xfa, 0x55,
+	0x80, 0x5b, 0x71, 0x6d, 0xc4, 0xc7, 0x2c, 0xf0, 0xc9, 0xc0, 0x20, 0x16, 0x35, 0x03, 0x2a, 0xd5,
+	0xb8, 0x01, 0x9f, 0x2f, 0x35, 0xe0, 0x28, 0xe7, 0xec, 0x27, 0x94, 0xc4, 0x88, 0xad, 0x28, 0x54,
+	0x36, 0xd9, 0x42, 0xb0, 0x60, 0x01, 0x5a, 0x8c, 0xa2, 0x0b, 0x58, 0xf7, 0xdc, 0x0b, 0xe2, 0x0f,
+	0x6c, 0xd3, 0xb2, 0xcc, 0x0b, 0xcc, 0x18, 0x95, 0xea, 0xbc, 0x82, 0xcf, 0x96, 0x56, 0x70, 0x18,
+	0x13, 0xbe, 0xcb, 0xf2, 0x93, 0xe5, 0xef, 0x46, 0xa1, 0x72, 0xc7, 0x9b, 0x8d, 0x14, 0xd6, 0x6e,
+	0xcd, 0x85, 0x3a, 0x8f, 0xa0, 0x35, 0xb7, 0x95, 0x68, 0x1d, 0x56, 0xce, 0x48, 0x72, 0x85, 0x9a,
+	0x7a, 0x3c, 0x44, 0x6d, 0xa8, 0x9e, 0x63, 0x2b, 0x48, 0xee, 0x8b, 0xa0, 0x27, 0x93, 0x87, 0xe5,
+	0xaf, 0x85, 0xce, 0x01, 0xdc, 0x5e, 0x62, 0xc4, 0xb5, 0x64, 0x34, 0x68, 0x5f, 0xd5, 0xcd, 0x75,
+	0x34, 0xd4, 0xdf, 0x05, 0x58, 0xdb, 0x77, 0x6d, 0x1b, 0x3b, 0x43, 0x9d, 0xbc, 0x0c, 0x08, 0x65,
+	0x68, 0x13, 0x2a, 0xfc, 0xdd, 0x48, 0x9e, 0xa4, 0x46, 0x14, 0x2a, 0x7c, 0xae, 0xf3, 0x5f, 0xf4,
+	0x3d, 0x54, 0xb0, 0x3f, 0xa2, 0x52, 0x99, 0xfb, 0xfc, 0xe9, 0x9c, 0xcf, 0xb3, 0x52, 0xbd, 0x3d,
+	0x7f, 0x94, 0x3a, 0x8c, 0xa2, 0x50, 0x59, 0x8b, 0x89, 0x05, 0x5b, 0xb9, 0x50, 0xe7, 0x2b, 0x68,
+	0x66, 0x69, 0xff, 0x55, 0x7a, 0xb3, 0x58, 0xfa, 0x2e, 0xb4, 0xb2, 0xe5, 0xa8, 0xe7, 0x3a, 0x94,
+	0xa0, 0xfb, 0x50, 0x73, 0x03, 0xe6, 0x05, 0x2c, 0x2d, 0x9e, 0x1f, 0xe7, 0x04, 0x29, 0xac, 0x9b,
+	0xe6, 0x6c, 0xff, 0x56, 0x86, 0xea, 0x5e, 0x5c, 0x39, 0xfa, 0x06, 0xc4, 0x3e, 0x61, 0xd9, 0xe3,
+	0xdc, 0x9e, 0xeb, 0x8a, 0xbf, 0xdf, 0x9d, 0xdb, 0x73, 0xe8, 0x34, 0x5d, 0x2d, 0xa1, 0x87, 0xd0,
+	0xec, 0x13, 0x96, 0x7e, 0x50, 0xaf, 0x66, 0x6f, 0xcc, 0xa1, 0x49, 0xb2, 0x5a, 0x42, 0xdf, 0xc2,
+	0x6a, 0x9f, 0xb0, 0xfc, 0x83, 0x76, 0x35, 0x5d, 0x5a, 0x76, 0xa0, 0xd5, 0x12, 0x7a, 0x0a, 0xf5,
+	0x83, 0x57, 0xc4, 0x08, 0x18, 0x41, 0x77, 0xff, 0x75, 0x3f, 0x3a, 0xf2, 0xb2, 0x70, 0xe2, 0x9f,
+	0x5a, 0xd2, 0xee, 0xbd, 0x79, 0x2f, 0x0b, 0xef, 0xde, 0xcb, 0xa5, 0x5f, 0x26, 0xb2, 0xf0, 0x66,
+	0x22, 0x0b, 0x6f, 0x27, 0xb2, 0xf0, 0xd7, 0x44, 0x16, 0x5e, 0x5f, 0xca, 0xa5, 0xb7, 0x97, 0x72,
+	0xe9, 0xdd, 0xa5, 0x5c, 0xfa, 0x71, 0x05, 0x7b, 0xe6, 0x49, 0x8d, 0xff, 0xbf, 0xf9, 0xf2, 0x9f,
+	0x01, 0x00, 0x9d, 0x21, 0xa3, 0x7b, 0x11, 0x09, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConn
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion4
+
+// AgentClient is the client API for Agent service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type AgentClient interface {
+	// GetIdentity return board serial, soc index and software versions
+	GetIdentity(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Identity, error)
+	// GetStatus return health and utilization
+	GetStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Status, error)
+	// GetTelemetry return utilization, temperature and power
+	GetTelemetry(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Telemetry, error)
+	// Execute run a command like clear-workspace, unknown command return
+	// InvalidArgument, it needs authorization
+	Execute(ctx context.Context, in *CommandRequest, opts ...grpc.CallOption) (*CommandResponse, error)
+}
+
+type agentClient struct {
+	cc *grpc.ClientConn
+}
+
+func NewAgentClient(cc *grpc.ClientConn) AgentClient {
+	return &agentClient{cc}
+}
+
+func (c *agentClient) GetIdentity(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Identity, error) {
+	out := new(Identity)
+	err := c.cc.Invoke(ctx, "/orin.agent.v1.Agent/GetIdentity", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) GetStatus(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Status, error) {
+	out := new(Status)
+	err := c.cc.Invoke(ctx, "/orin.agent.v1.Agent/GetStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) GetTelemetry(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*Telemetry, error) {
+	out := new(Telemetry)
+	err := c.cc.Invoke(ctx, "/orin.agent.v1.Agent/GetTelemetry", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *agentClient) Execute(ctx context.Context, in *CommandRequest, opts ...grpc.CallOption) (*CommandResponse, error) {
+	out := new(CommandResponse)
+	err := c.cc.Invoke(ctx, "/orin.agent.v1.Agent/Execute", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AgentServer is the server API for Agent service.
+type AgentServer interface {
+	// GetIdentity return board serial, soc index and software versions
+	GetIdentity(context.Context, *Empty) (*Identity, error)
+	// GetStatus return health and utilization
+	GetStatus(context.Context, *Empty) (*Status, error)
+	// GetTelemetry return utilization, temperature and power
+	GetTelemetry(context.Context, *Empty) (*Telemetry, error)
+	// Execute run a command like clear-workspace, unknown command return
+	// InvalidArgument, it needs authorization
+	Execute(context.Context, *CommandRequest) (*CommandResponse, error)
+}
+
+// UnimplementedAgentServer can be embedded to have forward compatible implementations.
+type UnimplementedAgentServer struct {
+}
+
+func (*UnimplementedAgentServer) GetIdentity(ctx context.Context, req *Empty) (*Identity, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetIdentity not implemented")
+}
+func (*UnimplementedAgentServer) GetStatus(ctx context.Context, req *Empty) (*Status, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (*UnimplementedAgentServer) GetTelemetry(ctx context.Context, req *Empty) (*Telemetry, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTelemetry not implemented")
+}
+func (*UnimplementedAgentServer) Execute(ctx context.Context, req *CommandRequest) (*CommandResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Execute not implemented")
+}
+
+func RegisterAgentServer(s *grpc.Server, srv AgentServer) {
+	s.RegisterService(&_Agent_serviceDesc, srv)
+}
+
+func _Agent_GetIdentity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).GetIdentity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orin.agent.v1.Agent/GetIdentity",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).GetIdentity(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orin.agent.v1.Agent/GetStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).GetStatus(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_GetTelemetry_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).GetTelemetry(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orin.agent.v1.Agent/GetTelemetry",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).GetTelemetry(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Agent_Execute_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AgentServer).Execute(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/orin.agent.v1.Agent/Execute",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AgentServer).Execute(ctx, req.(*CommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Agent_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "orin.agent.v1.Agent",
+	HandlerType: (*AgentServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetIdentity",
+			Handler:    _Agent_GetIdentity_Handler,
+		},
+		{
+			MethodName: "GetStatus",
+			Handler:    _Agent_GetStatus_Handler,
+		},
+		{
+			MethodName: "GetTelemetry",
+			Handler:    _Agent_GetTelemetry_Handler,
+		},
+		{
+			MethodName: "Execute",
+			Handler:    _Agent_Execute_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api.proto",
+}
+
+func (m *Empty) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Empty) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Empty) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	return len(dAtA) - i, nil
+}
+
+func (m *Identity) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Identity) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Identity) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.AgentVersion) > 0 {
+		i -= len(m.AgentVersion)
+		copy(dAtA[i:], m.AgentVersion)
+		i = encodeVarintApi(dAtA, i, uint64(len(m.AgentVersion)))
+		i--
+		dAtA[i] = 0x32
+	}
+	if len(m.JetPackVersion) > 0 {
+		i -= len(m.JetPackVersion)
+		copy(dAtA[i:], m.JetPackVersion)
+		i = encodeVarintApi(dAtA, i, uint64(len(m.JetPackVersion)))
+		i--
+		dAtA[i] = 0x2a
+	}
+	if len(m.L4TVersion) > 0 {
+		i -= len(m.L4TVersion)
+		copy(dAtA[i:], m.L4TVersion)
+		i = encodeVarintApi(dAtA, i, uint64(len(m.L4TVersion)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.Hostname) > 0 {
+		i -= len(m.Hostname)
+		copy(dAtA[i:], m.Hostname)
+		i = encodeVarintApi(dAtA, i, uint64(len(m.Hostname)))
+		i--
+		dAtA[i] = 0x1a
+	}
+	if m.SocIndex != 0 {
+		i = encodeVarintApi(dAtA, i, uint64(m.SocIndex))
+		i--
+		dAtA[i] = 0x10
+	}
+	if len(m.BoardSerial) > 0 {
+		i -= len(m.BoardSerial)
+		copy(dAtA[i:], m.BoardSerial)
+		i = encodeVarintApi(dAtA, i, uint64(len(m.BoardSerial)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Utilization) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Utilization) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Utilization) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.MemoryTotalBytes != 0 {
+		i = encodeVarintApi(dAtA, i, uint64(m.MemoryTotalBytes))
+		i--
+		dAtA[i] = 0x20
+	}
+	if m.MemoryUsedBytes != 0 {
+		i = encodeVarintApi(dAtA, i, uint64(m.MemoryUsedBytes))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.GPUPercent != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.GPUPercent))))
+		i--
+		dAtA[i] = 0x11
+	}
+	if m.CPUPercent != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.CPUPercent))))
+		i--
+		dAtA[i] = 0x9
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Status) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Status) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Status) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if m.Utilization != nil {
+		{
+			size, err := m.Utilization.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintApi(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x1a
+	}
+	if len(m.Message) > 0 {
+		i -= len(m.Message)
+		copy(dAtA[i:], m.Message)
+		i = encodeVarintApi(dAtA, i, uint64(len(m.Message)))
+		i--
+		dAtA[i] = 0x12
+	}
+	if m.Healthy {
+		i--
+		if m.Healthy {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x8
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *Telemetry) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *Telemetry) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *Telemetry) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.PowerMilliwatts) > 0 {
+		for k := range m.PowerMilliwatts {
+			v := m.PowerMilliwatts[k]
+			baseI := i
+			i -= 8
+			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(v))))
+			i--
+			dAtA[i] = 0x11
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintApi(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintApi(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x3a
+		}
+	}
+	if len(m.TemperatureCelsius) > 0 {
+		for k := range m.TemperatureCelsius {
+			v := m.TemperatureCelsius[k]
+			baseI := i
+			i -= 8
+			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(v))))
+			i--
+			dAtA[i] = 0x11
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintApi(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintApi(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x32
+		}
+	}
+	if m.MemoryTotalBytes != 0 {
+		i = encodeVarintApi(dAtA, i, uint64(m.MemoryTotalBytes))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.MemoryUsedBytes != 0 {
+		i = encodeVarintApi(dAtA, i, uint64(m.MemoryUsedBytes))
+		i--
+		dAtA[i] = 0x20
+	}
+	if len(m.DLAPercent) > 0 {
+		for k := range m.DLAPercent {
+			v := m.DLAPercent[k]
+			baseI := i
+			i -= 8
+			encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(v))))
+			i--
+			dAtA[i] = 0x11
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintApi(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintApi(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x1a
+		}
+	}
+	if m.GPUPercent != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.GPUPercent))))
+		i--
+		dAtA[i] = 0x11
+	}
+	if m.CPUPercent != 0 {
+		i -= 8
+		encoding_binary.LittleEndian.PutUint64(dAtA[i:], uint64(math.Float64bits(float64(m.CPUPercent))))
+		i--
+		dAtA[i] = 0x9
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *CommandRequest) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CommandRequest) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CommandRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Args) > 0 {
+		for k := range m.Args {
+			v := m.Args[k]
+			baseI := i
+			i -= len(v)
+			copy(dAtA[i:], v)
+			i = encodeVarintApi(dAtA, i, uint64(len(v)))
+			i--
+			dAtA[i] = 0x12
+			i -= len(k)
+			copy(dAtA[i:], k)
+			i = encodeVarintApi(dAtA, i, uint64(len(k)))
+			i--
+			dAtA[i] = 0xa
+			i = encodeVarintApi(dAtA, i, uint64(baseI-i))
+			i--
+			dAtA[i] = 0x12
+		}
+	}
+	if len(m.Name) > 0 {
+		i -= len(m.Name)
+		copy(dAtA[i:], m.Name)
+		i = encodeVarintApi(dAtA, i, uint64(len(m.Name)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func (m *CommandResponse) Marshal() (dAtA []byte, err error) {
+	size := m.Size()
+	dAtA = make([]byte, size)
+	n, err := m.MarshalToSizedBuffer(dAtA[:size])
+	if err != nil {
+		return nil, err
+	}
+	return dAtA[:n], nil
+}
+
+func (m *CommandResponse) MarshalTo(dAtA []byte) (int, error) {
+	size := m.Size()
+	return m.MarshalToSizedBuffer(dAtA[:size])
+}
+
+func (m *CommandResponse) MarshalToSizedBuffer(dAtA []byte) (int, error) {
+	i := len(dAtA)
+	_ = i
+	var l int
+	_ = l
+	if len(m.Output) > 0 {
+		i -= len(m.Output)
+		copy(dAtA[i:], m.Output)
+		i = encodeVarintApi(dAtA, i, uint64(len(m.Output)))
+		i--
+		dAtA[i] = 0xa
+	}
+	return len(dAtA) - i, nil
+}
+
+func encodeVarintApi(dAtA []byte, offset int, v uint64) int {
+	offset -= sovApi(v)
+	base := offset
+	for v >= 1<<7 {
+		dAtA[offset] = uint8(v&0x7f | 0x80)
+		v >>= 7
+		offset++
+	}
+	dAtA[offset] = uint8(v)
+	return base
+}
+func (m *Empty) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	return n
+}
+
+func (m *Identity) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.BoardSerial)
+	if l > 0 {
+		n += 1 + l + sovApi(uint64(l))
+	}
+	if m.SocIndex != 0 {
+		n += 1 + sovApi(uint64(m.SocIndex))
+	}
+	l = len(m.Hostname)
+	if l > 0 {
+		n += 1 + l + sovApi(uint64(l))
+	}
+	l = len(m.L4TVersion)
+	if l > 0 {
+		n += 1 + l + sovApi(uint64(l))
+	}
+	l = len(m.JetPackVersion)
+	if l > 0 {
+		n += 1 + l + sovApi(uint64(l))
+	}
+	l = len(m.AgentVersion)
+	if l > 0 {
+		n += 1 + l + sovApi(uint64(l))
+	}
+	return n
+}
+
+func (m *Utilization) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.CPUPercent != 0 {
+		n += 9
+	}
+	if m.GPUPercent != 0 {
+		n += 9
+	}
+	if m.MemoryUsedBytes != 0 {
+		n += 1 + sovApi(uint64(m.MemoryUsedBytes))
+	}
+	if m.MemoryTotalBytes != 0 {
+		n += 1 + sovApi(uint64(m.MemoryTotalBytes))
+	}
+	return n
+}
+
+func (m *Status) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.Healthy {
+		n += 2
+	}
+	l = len(m.Message)
+	if l > 0 {
+		n += 1 + l + sovApi(uint64(l))
+	}
+	if m.Utilization != nil {
+		l = m.Utilization.Size()
+		n += 1 + l + sovApi(uint64(l))
+	}
+	return n
+}
+
+func (m *Telemetry) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	if m.CPUPercent != 0 {
+		n += 9
+	}
+	if m.GPUPercent != 0 {
+		n += 9
+	}
+	if len(m.DLAPercent) > 0 {
+		for k, v := range m.DLAPercent {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovApi(uint64(len(k))) + 1 + 8
+			n += mapEntrySize + 1 + sovApi(uint64(mapEntrySize))
+		}
+	}
+	if m.MemoryUsedBytes != 0 {
+		n += 1 + sovApi(uint64(m.MemoryUsedBytes))
+	}
+	if m.MemoryTotalBytes != 0 {
+		n += 1 + sovApi(uint64(m.MemoryTotalBytes))
+	}
+	if len(m.TemperatureCelsius) > 0 {
+		for k, v := range m.TemperatureCelsius {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovApi(uint64(len(k))) + 1 + 8
+			n += mapEntrySize + 1 + sovApi(uint64(mapEntrySize))
+		}
+	}
+	if len(m.PowerMilliwatts) > 0 {
+		for k, v := range m.PowerMilliwatts {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovApi(uint64(len(k))) + 1 + 8
+			n += mapEntrySize + 1 + sovApi(uint64(mapEntrySize))
+		}
+	}
+	return n
+}
+
+func (m *CommandRequest) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Name)
+	if l > 0 {
+		n += 1 + l + sovApi(uint64(l))
+	}
+	if len(m.Args) > 0 {
+		for k, v := range m.Args {
+			_ = k
+			_ = v
+			mapEntrySize := 1 + len(k) + sovApi(uint64(len(k))) + 1 + len(v) + sovApi(uint64(len(v)))
+			n += mapEntrySize + 1 + sovApi(uint64(mapEntrySize))
+		}
+	}
+	return n
+}
+
+func (m *CommandResponse) Size() (n int) {
+	if m == nil {
+		return 0
+	}
+	var l int
+	_ = l
+	l = len(m.Output)
+	if l > 0 {
+		n += 1 + l + sovApi(uint64(l))
+	}
+	return n
+}
+
+func sovApi(x uint64) (n int) {
+	return (math_bits.Len64(x|1) + 6) / 7
+}
+func sozApi(x uint64) (n int) {
+	return sovApi(uint64((x << 1) ^ uint64((int64(x) >> 63))))
+}
+func (this *Empty) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Empty{`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Identity) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Identity{`,
+		`BoardSerial:` + fmt.Sprintf("%v", this.BoardSerial) + `,`,
+		`SocIndex:` + fmt.Sprintf("%v", this.SocIndex) + `,`,
+		`Hostname:` + fmt.Sprintf("%v", this.Hostname) + `,`,
+		`L4TVersion:` + fmt.Sprintf("%v", this.L4TVersion) + `,`,
+		`JetPackVersion:` + fmt.Sprintf("%v", this.JetPackVersion) + `,`,
+		`AgentVersion:` + fmt.Sprintf("%v", this.AgentVersion) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Utilization) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Utilization{`,
+		`CPUPercent:` + fmt.Sprintf("%v", this.CPUPercent) + `,`,
+		`GPUPercent:` + fmt.Sprintf("%v", this.GPUPercent) + `,`,
+		`MemoryUsedBytes:` + fmt.Sprintf("%v", this.MemoryUsedBytes) + `,`,
+		`MemoryTotalBytes:` + fmt.Sprintf("%v", this.MemoryTotalBytes) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Status) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&Status{`,
+		`Healthy:` + fmt.Sprintf("%v", this.Healthy) + `,`,
+		`Message:` + fmt.Sprintf("%v", this.Message) + `,`,
+		`Utilization:` + strings.Replace(this.Utilization.String(), "Utilization", "Utilization", 1) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *Telemetry) String() string {
+	if this == nil {
+		return "nil"
+	}
+	keysForDLAPercent := make([]string, 0, len(this.DLAPercent))
+	for k, _ := range this.DLAPercent {
+		keysForDLAPercent = append(keysForDLAPercent, k)
+	}
+	github_com_gogo_protobuf_sortkeys.Strings(keysForDLAPercent)
+	mapStringForDLAPercent := "map[string]float64{"
+	for _, k := range keysForDLAPercent {
+		mapStringForDLAPercent += fmt.Sprintf("%v: %v,", k, this.DLAPercent[k])
+	}
+	mapStringForDLAPercent += "}"
+	keysForTemperatureCelsius := make([]string, 0, len(this.TemperatureCelsius))
+	for k, _ := range this.TemperatureCelsius {
+		keysForTemperatureCelsius = append(keysForTemperatureCelsius, k)
+	}
+	github_com_gogo_protobuf_sortkeys.Strings(keysForTemperatureCelsius)
+	mapStringForTemperatureCelsius := "map[string]float64{"
+	for _, k := range keysForTemperatureCelsius {
+		mapStringForTemperatureCelsius += fmt.Sprintf("%v: %v,", k, this.TemperatureCelsius[k])
+	}
+	mapStringForTemperatureCelsius += "}"
+	keysForPowerMilliwatts := make([]string, 0, len(this.PowerMilliwatts))
+	for k, _ := range this.PowerMilliwatts {
+		keysForPowerMilliwatts = append(keysForPowerMilliwatts, k)
+	}
+	github_com_gogo_protobuf_sortkeys.Strings(keysForPowerMilliwatts)
+	mapStringForPowerMilliwatts := "map[string]float64{"
+	for _, k := range keysForPowerMilliwatts {
+		mapStringForPowerMilliwatts += fmt.Sprintf("%v: %v,", k, this.PowerMilliwatts[k])
+	}
+	mapStringForPowerMilliwatts += "}"
+	s := strings.Join([]string{`&Telemetry{`,
+		`CPUPercent:` + fmt.Sprintf("%v", this.CPUPercent) + `,`,
+		`GPUPercent:` + fmt.Sprintf("%v", this.GPUPercent) + `,`,
+		`DLAPercent:` + mapStringForDLAPercent + `,`,
+		`MemoryUsedBytes:` + fmt.Sprintf("%v", this.MemoryUsedBytes) + `,`,
+		`MemoryTotalBytes:` + fmt.Sprintf("%v", this.MemoryTotalBytes) + `,`,
+		`TemperatureCelsius:` + mapStringForTemperatureCelsius + `,`,
+		`PowerMilliwatts:` + mapStringForPowerMilliwatts + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *CommandRequest) String() string {
+	if this == nil {
+		return "nil"
+	}
+	keysForArgs := make([]string, 0, len(this.Args))
+	for k, _ := range this.Args {
+		keysForArgs = append(keysForArgs, k)
+	}
+	github_com_gogo_protobuf_sortkeys.Strings(keysForArgs)
+	mapStringForArgs := "map[string]string{"
+	for _, k := range keysForArgs {
+		mapStringForArgs += fmt.Sprintf("%v: %v,", k, this.Args[k])
+	}
+	mapStringForArgs += "}"
+	s := strings.Join([]string{`&CommandRequest{`,
+		`Name:` + fmt.Sprintf("%v", this.Name) + `,`,
+		`Args:` + mapStringForArgs + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func (this *CommandResponse) String() string {
+	if this == nil {
+		return "nil"
+	}
+	s := strings.Join([]string{`&CommandResponse{`,
+		`Output:` + fmt.Sprintf("%v", this.Output) + `,`,
+		`}`,
+	}, "")
+	return s
+}
+func valueToStringApi(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	if rv.IsNil() {
+		return "nil"
+	}
+	pv := reflect.Indirect(rv).Interface()
+	return fmt.Sprintf("*%v", pv)
+}
+func (m *Empty) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Empty: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Empty: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		default:
+			iNdEx = preIndex
+			skippy, err := skipApi(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthApi
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Identity) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Identity: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Identity: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BoardSerial", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthApi
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.BoardSerial = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SocIndex", wireType)
+			}
+			m.SocIndex = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.SocIndex |= int32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Hostname", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthApi
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Hostname = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field L4TVersion", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthApi
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.L4TVersion = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 5:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field JetPackVersion", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthApi
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.JetPackVersion = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AgentVersion", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthApi
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.AgentVersion = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipApi(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthApi
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Utilization) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Utilization: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Utilization: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CPUPercent", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.CPUPercent = float64(math.Float64frombits(v))
+		case 2:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GPUPercent", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.GPUPercent = float64(math.Float64frombits(v))
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MemoryUsedBytes", wireType)
+			}
+			m.MemoryUsedBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MemoryUsedBytes |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MemoryTotalBytes", wireType)
+			}
+			m.MemoryTotalBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MemoryTotalBytes |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipApi(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthApi
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Status) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Status: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Status: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Healthy", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Healthy = bool(v != 0)
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Message", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthApi
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Message = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Utilization", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthApi
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Utilization == nil {
+				m.Utilization = &Utilization{}
+			}
+			if err := m.Utilization.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipApi(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthApi
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Telemetry) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: Telemetry: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: Telemetry: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CPUPercent", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.CPUPercent = float64(math.Float64frombits(v))
+		case 2:
+			if wireType != 1 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GPUPercent", wireType)
+			}
+			var v uint64
+			if (iNdEx + 8) > l {
+				return io.ErrUnexpectedEOF
+			}
+			v = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+			iNdEx += 8
+			m.GPUPercent = float64(math.Float64frombits(v))
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field DLAPercent", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthApi
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.DLAPercent == nil {
+				m.DLAPercent = make(map[string]float64)
+			}
+			var mapkey string
+			var mapvalue float64
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowApi
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowApi
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthApi
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthApi
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var mapvaluetemp uint64
+					if (iNdEx + 8) > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvaluetemp = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+					iNdEx += 8
+					mapvalue = math.Float64frombits(mapvaluetemp)
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipApi(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if (skippy < 0) || (iNdEx+skippy) < 0 {
+						return ErrInvalidLengthApi
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.DLAPercent[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MemoryUsedBytes", wireType)
+			}
+			m.MemoryUsedBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MemoryUsedBytes |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field MemoryTotalBytes", wireType)
+			}
+			m.MemoryTotalBytes = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.MemoryTotalBytes |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 6:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TemperatureCelsius", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthApi
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.TemperatureCelsius == nil {
+				m.TemperatureCelsius = make(map[string]float64)
+			}
+			var mapkey string
+			var mapvalue float64
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowApi
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowApi
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthApi
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthApi
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var mapvaluetemp uint64
+					if (iNdEx + 8) > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvaluetemp = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+					iNdEx += 8
+					mapvalue = math.Float64frombits(mapvaluetemp)
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipApi(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if (skippy < 0) || (iNdEx+skippy) < 0 {
+						return ErrInvalidLengthApi
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.TemperatureCelsius[mapkey] = mapvalue
+			iNdEx = postIndex
+		case 7:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field PowerMilliwatts", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthApi
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.PowerMilliwatts == nil {
+				m.PowerMilliwatts = make(map[string]float64)
+			}
+			var mapkey string
+			var mapvalue float64
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowApi
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowApi
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthApi
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthApi
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var mapvaluetemp uint64
+					if (iNdEx + 8) > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvaluetemp = uint64(encoding_binary.LittleEndian.Uint64(dAtA[iNdEx:]))
+					iNdEx += 8
+					mapvalue = math.Float64frombits(mapvaluetemp)
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipApi(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if (skippy < 0) || (iNdEx+skippy) < 0 {
+						return ErrInvalidLengthApi
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.PowerMilliwatts[mapkey] = mapvalue
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipApi(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthApi
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CommandRequest) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CommandRequest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CommandRequest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Name", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthApi
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Name = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Args", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthApi
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.Args == nil {
+				m.Args = make(map[string]string)
+			}
+			var mapkey string
+			var mapvalue string
+			for iNdEx < postIndex {
+				entryPreIndex := iNdEx
+				var wire uint64
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowApi
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					wire |= uint64(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				fieldNum := int32(wire >> 3)
+				if fieldNum == 1 {
+					var stringLenmapkey uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowApi
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapkey |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapkey := int(stringLenmapkey)
+					if intStringLenmapkey < 0 {
+						return ErrInvalidLengthApi
+					}
+					postStringIndexmapkey := iNdEx + intStringLenmapkey
+					if postStringIndexmapkey < 0 {
+						return ErrInvalidLengthApi
+					}
+					if postStringIndexmapkey > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapkey = string(dAtA[iNdEx:postStringIndexmapkey])
+					iNdEx = postStringIndexmapkey
+				} else if fieldNum == 2 {
+					var stringLenmapvalue uint64
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowApi
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						stringLenmapvalue |= uint64(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					intStringLenmapvalue := int(stringLenmapvalue)
+					if intStringLenmapvalue < 0 {
+						return ErrInvalidLengthApi
+					}
+					postStringIndexmapvalue := iNdEx + intStringLenmapvalue
+					if postStringIndexmapvalue < 0 {
+						return ErrInvalidLengthApi
+					}
+					if postStringIndexmapvalue > l {
+						return io.ErrUnexpectedEOF
+					}
+					mapvalue = string(dAtA[iNdEx:postStringIndexmapvalue])
+					iNdEx = postStringIndexmapvalue
+				} else {
+					iNdEx = entryPreIndex
+					skippy, err := skipApi(dAtA[iNdEx:])
+					if err != nil {
+						return err
+					}
+					if (skippy < 0) || (iNdEx+skippy) < 0 {
+						return ErrInvalidLengthApi
+					}
+					if (iNdEx + skippy) > postIndex {
+						return io.ErrUnexpectedEOF
+					}
+					iNdEx += skippy
+				}
+			}
+			m.Args[mapkey] = mapvalue
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipApi(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthApi
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *CommandResponse) Unmarshal(dAtA []byte) error {
+	l := len(dAtA)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= uint64(b&0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CommandResponse: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CommandResponse: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Output", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				stringLen |= uint64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthApi
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex < 0 {
+				return ErrInvalidLengthApi
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Output = string(dAtA[iNdEx:postIndex])
+			iNdEx = postIndex
+		default:
+			iNdEx = preIndex
+			skippy, err := skipApi(dAtA[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if (skippy < 0) || (iNdEx+skippy) < 0 {
+				return ErrInvalidLengthApi
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func skipApi(dAtA []byte) (n int, err error) {
+	l := len(dAtA)
+	iNdEx := 0
+	depth := 0
+	for iNdEx < l {
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return 0, ErrIntOverflowApi
+			}
+			if iNdEx >= l {
+				return 0, io.ErrUnexpectedEOF
+			}
+			b := dAtA[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		wireType := int(wire & 0x7)
+		switch wireType {
+		case 0:
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				iNdEx++
+				if dAtA[iNdEx-1] < 0x80 {
+					break
+				}
+			}
+		case 1:
+			iNdEx += 8
+		case 2:
+			var length int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return 0, ErrIntOverflowApi
+				}
+				if iNdEx >= l {
+					return 0, io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				length |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if length < 0 {
+				return 0, ErrInvalidLengthApi
+			}
+			iNdEx += length
+		case 3:
+			depth++
+		case 4:
+			if depth == 0 {
+				return 0, ErrUnexpectedEndOfGroupApi
+			}
+			depth--
+		case 5:
+			iNdEx += 4
+		default:
+			return 0, fmt.Errorf("proto: illegal wireType %d", wireType)
+		}
+		if iNdEx < 0 {
+			return 0, ErrInvalidLengthApi
+		}
+		if depth == 0 {
+			return iNdEx, nil
+		}
+	}
+	return 0, io.ErrUnexpectedEOF
+}
+
+var (
+	ErrInvalidLengthApi        = fmt.Errorf("proto: negative length found during unmarshaling")
+	ErrIntOverflowApi          = fmt.Errorf("proto: integer overflow")
+	ErrUnexpectedEndOfGroupApi = fmt.Errorf("proto: unexpected end of group")
+)
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"context"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// ServiceName is the grpc service of soc agent
+	ServiceName = "orin.agent.v1.Agent"
+	// ExecuteMethod is the full grpc method of Execute
+	ExecuteMethod = "/" + ServiceName + "/Execute"
+
+	// AuthorizationKey is the metadata key of the shared token authorizing
+	// Execute, its value is BearerPrefix and the token
+	AuthorizationKey = "authorization"
+	BearerPrefix     = "Bearer "
+
+	// DefaultPort is the port soc agent listens on
+	DefaultPort = 9420
+
+	// CommandClearWorkspace remove everything in the agent workspace
+	CommandClearWorkspace = "clear-workspace"
+	// CommandReboot reboot the soc after the response is sent
+	CommandReboot = "reboot"
+)
+
+// WithToken return context carrying the shared token to authorize Execute
+func WithToken(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, AuthorizationKey, BearerPrefix+token)
+}
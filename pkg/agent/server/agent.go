@@ -0,0 +1,307 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/agent/api"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+const (
+	DefaultReleaseFile = "/etc/nv_tegra_release"
+	DefaultGPULoadFile = "/sys/devices/gpu.0/load"
+	DefaultProcRoot    = "/proc"
+	DefaultSysRoot     = "/sys"
+
+	healthCommandTimeout = 10 * time.Second
+	rebootDelay          = time.Second
+)
+
+// releasePattern match L4T version in nv_tegra_release, e.g.
+// "# R35 (release), REVISION: 3.1, GCID: ..."
+var releasePattern = regexp.MustCompile(`R(\d+) \(release\), REVISION: ([\d.]+)`)
+
+type Config struct {
+	BoardSerial    string
+	SocIndex       int
+	JetPackVersion string
+	AgentVersion   string
+	// Workspace is cleared by CommandClearWorkspace, and soc is unhealthy if
+	// it is missing
+	Workspace string
+	// HealthCommand mark soc unhealthy if it fails, empty means not checked
+	HealthCommand []string
+	RebootCommand []string
+
+	ReleaseFile string
+	GPULoadFile string
+	// DLALoadFiles is load file in per mille of every dla engine
+	DLALoadFiles map[string]string
+	ProcRoot     string
+	// SysRoot has thermal zones and ina3221 power monitors
+	SysRoot string
+}
+
+// Agent serve api.AgentServer on soc
+type Agent struct {
+	config *Config
+
+	lock sync.Mutex
+	// last cpu total and idle jiffies of /proc/stat
+	lastTotal, lastIdle uint64
+}
+
+func New(c *Config) *Agent {
+	if c.ReleaseFile == "" {
+		c.ReleaseFile = DefaultReleaseFile
+	}
+	if c.GPULoadFile == "" {
+		c.GPULoadFile = DefaultGPULoadFile
+	}
+	if c.ProcRoot == "" {
+		c.ProcRoot = DefaultProcRoot
+	}
+	if c.SysRoot == "" {
+		c.SysRoot = DefaultSysRoot
+	}
+	return &Agent{config: c}
+}
+
+func (a *Agent) GetIdentity(ctx context.Context, _ *api.Empty) (*api.Identity, error) {
+	hostname, _ := os.Hostname()
+	return &api.Identity{
+		BoardSerial:    a.config.BoardSerial,
+		SocIndex:       int32(a.config.SocIndex),
+		Hostname:       hostname,
+		L4TVersion:     a.l4tVersion(),
+		JetPackVersion: a.config.JetPackVersion,
+		AgentVersion:   a.config.AgentVersion,
+	}, nil
+}
+
+func (a *Agent) l4tVersion() string {
+	data, err := os.ReadFile(a.config.ReleaseFile)
+	if err != nil {
+		return ""
+	}
+	m := releasePattern.FindStringSubmatch(string(data))
+	if m == nil {
+		return ""
+	}
+	return m[1] + "." + m[2]
+}
+
+func (a *Agent) GetStatus(ctx context.Context, _ *api.Empty) (*api.Status, error) {
+	s := &api.Status{Healthy: true, Utilization: a.utilization()}
+	if a.config.Workspace != "" {
+		if _, err := os.Stat(a.config.Workspace); err != nil {
+			s.Healthy, s.Message = false, fmt.Sprintf("workspace unavailable: %v", err)
+			return s, nil
+		}
+	}
+	if len(a.config.HealthCommand) != 0 {
+		ctx, cancel := context.WithTimeout(ctx, healthCommandTimeout)
+		defer cancel()
+		out, err := exec.CommandContext(ctx, a.config.HealthCommand[0], a.config.HealthCommand[1:]...).CombinedOutput()
+		if err != nil {
+			s.Healthy, s.Message = false, fmt.Sprintf("health command failed: %v: %s", err, strings.TrimSpace(string(out)))
+		}
+	}
+	return s, nil
+}
+
+// utilization read usage from proc and sysfs, unreadable values are zero
+func (a *Agent) utilization() *api.Utilization {
+	u := &api.Utilization{}
+	if total, idle, err := readCPUStat(filepath.Join(a.config.ProcRoot, "stat")); err == nil {
+		a.lock.Lock()
+		// cpu usage is between two calls, the first call is since boot
+		if dt := total - a.lastTotal; total > a.lastTotal && dt > 0 {
+			u.CPUPercent = float64(dt-(idle-a.lastIdle)) * 100 / float64(dt)
+		}
+		a.lastTotal, a.lastIdle = total, idle
+		a.lock.Unlock()
+	}
+	if total, available, err := readMemInfo(filepath.Join(a.config.ProcRoot, "meminfo")); err == nil {
+		u.MemoryTotalBytes, u.MemoryUsedBytes = total, total-available
+	}
+	if load, err := readFloat(a.config.GPULoadFile); err == nil {
+		u.GPUPercent = load / 10
+	}
+	return u
+}
+
+// readFloat read a sysfs file of one number
+func readFloat(path string) (float64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(strings.TrimSpace(string(data)), 64)
+}
+
+func (a *Agent) GetTelemetry(ctx context.Context, _ *api.Empty) (*api.Telemetry, error) {
+	u := a.utilization()
+	t := &api.Telemetry{
+		CPUPercent:         u.CPUPercent,
+		GPUPercent:         u.GPUPercent,
+		MemoryUsedBytes:    u.MemoryUsedBytes,
+		MemoryTotalBytes:   u.MemoryTotalBytes,
+		DLAPercent:         map[string]float64{},
+		TemperatureCelsius: a.temperatures(),
+		PowerMilliwatts:    a.power(),
+	}
+	for name, path := range a.config.DLALoadFiles {
+		if load, err := readFloat(path); err == nil {
+			t.DLAPercent[name] = load / 10
+		}
+	}
+	return t, nil
+}
+
+// temperatures read every thermal zone, temp is in millidegree
+func (a *Agent) temperatures() map[string]float64 {
+	res := map[string]float64{}
+	zones, _ := filepath.Glob(filepath.Join(a.config.SysRoot, "class/thermal/thermal_zone*"))
+	for _, zone := range zones {
+		name, err := os.ReadFile(filepath.Join(zone, "type"))
+		if err != nil {
+			continue
+		}
+		if temp, err := readFloat(filepath.Join(zone, "temp")); err == nil {
+			res[strings.TrimSpace(string(name))] = temp / 1000
+		}
+	}
+	return res
+}
+
+// power read every rail of ina3221 monitors, voltage is in mV and current is
+// in mA
+func (a *Agent) power() map[string]float64 {
+	res := map[string]float64{}
+	labels, _ := filepath.Glob(filepath.Join(a.config.SysRoot, "bus/i2c/drivers/ina3221/*/hwmon/hwmon*/in*_label"))
+	for _, label := range labels {
+		name, err := os.ReadFile(label)
+		if err != nil {
+			continue
+		}
+		// in1_label has in1_input and curr1_input
+		channel := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(label), "in"), "_label")
+		dir := filepath.Dir(label)
+		voltage, err := readFloat(filepath.Join(dir, "in"+channel+"_input"))
+		if err != nil {
+			continue
+		}
+		current, err := readFloat(filepath.Join(dir, "curr"+channel+"_input"))
+		if err != nil {
+			continue
+		}
+		res[strings.TrimSpace(string(name))] = voltage * current / 1000
+	}
+	return res
+}
+
+// readCPUStat return total and idle jiffies of all cpus
+func readCPUStat(path string) (uint64, uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 || fields[0] != "cpu" {
+			continue
+		}
+		var total, idle uint64
+		for i, v := range fields[1:] {
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return 0, 0, err
+			}
+			total += n
+			// idle and iowait
+			if i == 3 || i == 4 {
+				idle += n
+			}
+		}
+		return total, idle, nil
+	}
+	return 0, 0, fmt.Errorf("no cpu line in %s", path)
+}
+
+// readMemInfo return total and available memory in bytes
+func readMemInfo(path string) (uint64, uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer f.Close()
+	values := map[string]uint64{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		if n, err := strconv.ParseUint(fields[1], 10, 64); err == nil {
+			values[strings.TrimSuffix(fields[0], ":")] = n * 1024
+		}
+	}
+	total, ok := values["MemTotal"]
+	if !ok {
+		return 0, 0, fmt.Errorf("no MemTotal in %s", path)
+	}
+	return total, values["MemAvailable"], nil
+}
+
+func (a *Agent) Execute(ctx context.Context, req *api.CommandRequest) (*api.CommandResponse, error) {
+	klog.InfoS("execute command", "command", req.Name, "args", req.Args)
+	switch req.Name {
+	case api.CommandClearWorkspace:
+		return a.clearWorkspace()
+	case api.CommandReboot:
+		if len(a.config.RebootCommand) == 0 {
+			return nil, status.Error(codes.FailedPrecondition, "reboot command not configured")
+		}
+		// reboot after the response is sent
+		time.AfterFunc(rebootDelay, func() {
+			if out, err := exec.Command(a.config.RebootCommand[0], a.config.RebootCommand[1:]...).CombinedOutput(); err != nil {
+				klog.ErrorS(err, "reboot error", "output", string(out))
+			}
+		})
+		return &api.CommandResponse{Output: "rebooting"}, nil
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown command %q", req.Name)
+	}
+}
+
+func (a *Agent) clearWorkspace() (*api.CommandResponse, error) {
+	if a.config.Workspace == "" {
+		return nil, status.Error(codes.FailedPrecondition, "workspace not configured")
+	}
+	entries, err := os.ReadDir(a.config.Workspace)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	for _, e := range entries {
+		if err := os.RemoveAll(filepath.Join(a.config.Workspace, e.Name())); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+	return &api.CommandResponse{Output: fmt.Sprintf("%d entries removed", len(entries))}, nil
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/superedge/orin-device-system/pkg/agent/api"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// startAgent serve agent on localhost and return a client of it
+func startAgent(t *testing.T, c *Config, opts ...grpc.ServerOption) api.AgentClient {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer(opts...)
+	api.RegisterAgentServer(s, New(c))
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithInsecure())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return api.NewAgentClient(conn)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAgent(t *testing.T) {
+	dir := t.TempDir()
+	proc := filepath.Join(dir, "proc")
+	workspace := filepath.Join(dir, "workspace")
+	for _, d := range []string{proc, filepath.Join(workspace, "job")} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(dir, "nv_tegra_release"), "# R35 (release), REVISION: 3.1, GCID: 32827747, BOARD: t186ref, EABI: aarch64\n")
+	writeFile(t, filepath.Join(dir, "load"), "455\n")
+	writeFile(t, filepath.Join(proc, "stat"), "cpu  100 0 100 700 100 0 0 0 0 0\ncpu0 100 0 100 700 100 0 0 0 0 0\n")
+	writeFile(t, filepath.Join(proc, "meminfo"), "MemTotal:       32000 kB\nMemFree:        1000 kB\nMemAvailable:   8000 kB\n")
+	client := startAgent(t, &Config{
+		BoardSerial:    "1424621019234",
+		SocIndex:       2,
+		JetPackVersion: "5.1.1",
+		Workspace:      workspace,
+		HealthCommand:  []string{"test", "-d", filepath.Join(workspace, "job")},
+		ReleaseFile:    filepath.Join(dir, "nv_tegra_release"),
+		GPULoadFile:    filepath.Join(dir, "load"),
+		ProcRoot:       proc,
+	})
+	ctx := context.Background()
+
+	identity, err := client.GetIdentity(ctx, &api.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if identity.BoardSerial != "1424621019234" || identity.SocIndex != 2 || identity.L4TVersion != "35.3.1" || identity.JetPackVersion != "5.1.1" {
+		t.Fatalf("unexpected identity %+v", identity)
+	}
+
+	s, err := client.GetStatus(ctx, &api.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := s.Utilization
+	if !s.Healthy || u.CPUPercent != 20 || u.GPUPercent != 45.5 || u.MemoryTotalBytes != 32000*1024 || u.MemoryUsedBytes != 24000*1024 {
+		t.Fatalf("unexpected status %+v, utilization %+v", s, u)
+	}
+
+	res, err := client.Execute(ctx, &api.CommandRequest{Name: api.CommandClearWorkspace})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(workspace); len(entries) != 0 || res.Output != "1 entries removed" {
+		t.Fatalf("workspace not cleared, output %q", res.Output)
+	}
+	// health command fails after the job directory is removed
+	if s, err = client.GetStatus(ctx, &api.Empty{}); err != nil || s.Healthy || s.Message == "" {
+		t.Fatalf("expect unhealthy with message, actual %+v, %v", s, err)
+	}
+
+	if _, err := client.Execute(ctx, &api.CommandRequest{Name: "format"}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expect unknown command InvalidArgument, actual %v", err)
+	}
+	if _, err := client.Execute(ctx, &api.CommandRequest{Name: api.CommandReboot}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expect reboot without command FailedPrecondition, actual %v", err)
+	}
+}
+
+func TestAgentTelemetry(t *testing.T) {
+	dir := t.TempDir()
+	sys := filepath.Join(dir, "sys")
+	zone := filepath.Join(sys, "class/thermal/thermal_zone1")
+	hwmon := filepath.Join(sys, "bus/i2c/drivers/ina3221/1-0040/hwmon/hwmon3")
+	for _, d := range []string{zone, hwmon} {
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(t, filepath.Join(zone, "type"), "gpu-thermal\n")
+	writeFile(t, filepath.Join(zone, "temp"), "61500\n")
+	writeFile(t, filepath.Join(hwmon, "in1_label"), "VDD_GPU_SOC\n")
+	writeFile(t, filepath.Join(hwmon, "in1_input"), "5000\n")
+	writeFile(t, filepath.Join(hwmon, "curr1_input"), "1200\n")
+	// channel 2 has no current
+	writeFile(t, filepath.Join(hwmon, "in2_label"), "VDD_CPU_CV\n")
+	writeFile(t, filepath.Join(hwmon, "in2_input"), "5000\n")
+	writeFile(t, filepath.Join(dir, "dla0"), "300\n")
+	client := startAgent(t, &Config{
+		SysRoot:      sys,
+		ProcRoot:     filepath.Join(dir, "proc"),
+		GPULoadFile:  filepath.Join(dir, "gpu"),
+		DLALoadFiles: map[string]string{"dla0": filepath.Join(dir, "dla0"), "dla1": filepath.Join(dir, "dla1")},
+	})
+
+	telemetry, err := client.GetTelemetry(context.Background(), &api.Empty{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &api.Telemetry{
+		DLAPercent:         map[string]float64{"dla0": 30},
+		TemperatureCelsius: map[string]float64{"gpu-thermal": 61.5},
+		PowerMilliwatts:    map[string]float64{"VDD_GPU_SOC": 6000},
+	}
+	if !reflect.DeepEqual(telemetry, expect) {
+		t.Fatalf("expect telemetry %+v, actual %+v", expect, telemetry)
+	}
+}
+
+func TestExecuteAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		auth       *ExecuteAuth
+		token      string
+		expectCode codes.Code
+	}{
+		{name: "disabled", auth: &ExecuteAuth{}, token: "secret", expectCode: codes.PermissionDenied},
+		{name: "no token", auth: &ExecuteAuth{Token: "secret"}, expectCode: codes.Unauthenticated},
+		{name: "wrong token", auth: &ExecuteAuth{Token: "secret"}, token: "guess", expectCode: codes.Unauthenticated},
+		{name: "plaintext without client cert", auth: &ExecuteAuth{ClientCert: true}, expectCode: codes.Unauthenticated},
+		{name: "token", auth: &ExecuteAuth{Token: "secret"}, token: "secret", expectCode: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := startAgent(t, &Config{Workspace: t.TempDir()}, grpc.UnaryInterceptor(tt.auth.Interceptor))
+			ctx := context.Background()
+			// read only methods are not checked
+			if _, err := client.GetIdentity(ctx, &api.Empty{}); err != nil {
+				t.Fatalf("GetIdentity() error = %v", err)
+			}
+			if tt.token != "" {
+				ctx = api.WithToken(ctx, tt.token)
+			}
+			if _, err := client.Execute(ctx, &api.CommandRequest{Name: api.CommandClearWorkspace}); status.Code(err) != tt.expectCode {
+				t.Errorf("Execute() error = %v, expect %s", err, tt.expectCode)
+			}
+		})
+	}
+}
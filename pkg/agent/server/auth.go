@@ -0,0 +1,66 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/superedge/orin-device-system/pkg/agent/api"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"k8s.io/klog/v2"
+)
+
+// ExecuteAuth authorize callers of Execute, which can wipe or reboot the soc,
+// by the shared Token or a client certificate verified by the tls server.
+// Execute is refused if neither is configured, other methods are read only
+// and not checked
+type ExecuteAuth struct {
+	// Token is the shared token expected in api.AuthorizationKey metadata,
+	// empty means token is not accepted
+	Token string
+	// ClientCert accept callers with a verified client certificate
+	ClientCert bool
+}
+
+// Interceptor is the grpc unary interceptor checking Execute
+func (e *ExecuteAuth) Interceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if info.FullMethod != api.ExecuteMethod {
+		return handler(ctx, req)
+	}
+	if err := e.authorize(ctx); err != nil {
+		caller := ""
+		if p, ok := peer.FromContext(ctx); ok {
+			caller = p.Addr.String()
+		}
+		klog.InfoS("execute refused", "caller", caller, "err", err)
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (e *ExecuteAuth) authorize(ctx context.Context) error {
+	if e.Token == "" && !e.ClientCert {
+		return status.Error(codes.PermissionDenied, "execute is disabled, agent has no token or client ca")
+	}
+	if e.ClientCert {
+		if p, ok := peer.FromContext(ctx); ok {
+			if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) != 0 {
+				return nil
+			}
+		}
+	}
+	if e.Token != "" {
+		md, _ := metadata.FromIncomingContext(ctx)
+		for _, v := range md.Get(api.AuthorizationKey) {
+			if subtle.ConstantTimeCompare([]byte(v), []byte(api.BearerPrefix+e.Token)) == 1 {
+				return nil
+			}
+		}
+	}
+	return status.Error(codes.Unauthenticated, "execute needs a valid token or client certificate")
+}
@@ -1,10 +1,62 @@
 package common
 
 const (
+	ExtendResouceTypePrefix      = "superedge.io/device-"
 	ExtendResouceTypeBoard       = "superedge.io/device-board"
 	ExtendResouceTypeBoardPrefix = "superedge.io/device-board-"
 	ExtendResouceTypeOrinPrefix  = "superedge.io/device-orin-"
+	// ExtendResouceTypeOrin is a count of any orins on one board, the
+	// scheduler extender chooses which
+	ExtendResouceTypeOrin = "superedge.io/device-orin"
 
 	AnnotationPodBindToBoard    = "superedge.io/pod-bind-board"
 	AnnotationPodBindOrinPolicy = "superedge.io/pod-bind-orin-policy"
+	// AnnotationPodBindOrins is comma separated orins of the bound board
+	// chosen by scheduler extender for ExtendResouceTypeOrin request
+	AnnotationPodBindOrins = "superedge.io/pod-bind-orins"
+	// AnnotationPodRequestBoards is json list of orin requests of a pod on
+	// different boards, like [{"count":4},{"orins":[1]}]
+	AnnotationPodRequestBoards = "superedge.io/pod-request-boards"
+	// AnnotationPodBindBoards is json map of board id to orins of a pod with
+	// AnnotationPodRequestBoards, written by scheduler extender
+	AnnotationPodBindBoards = "superedge.io/pod-bind-boards"
+	// AnnotationPodGroup is the name of pod group in namespace, members of a
+	// group land on one node or one board, or none of them starts
+	AnnotationPodGroup = "superedge.io/pod-group"
+	// AnnotationPodGroupMinMember is the number of members a group needs
+	AnnotationPodGroupMinMember = "superedge.io/pod-group-min-member"
+	// AnnotationPodGroupScope is node or board, node by default
+	AnnotationPodGroupScope = "superedge.io/pod-group-scope"
+	// AnnotationNamespaceOrinPolicy is the default allocation policy of pods
+	// in namespace without AnnotationPodBindOrinPolicy
+	AnnotationNamespaceOrinPolicy = "superedge.io/orin-policy"
+
+	// AnnotationNodeOrinAudit is the summary of device plugin audit findings
+	AnnotationNodeOrinAudit = "superedge.io/orin-audit"
+	// AnnotationNodeOrinQuarantine is comma separated devices marked unhealthy
+	// by failed hooks, operator removes a device from it to clear
+	AnnotationNodeOrinQuarantine = "superedge.io/orin-quarantine"
+	// AnnotationNodeOrinBoardPower is json map of board id to power state,
+	// written by device plugin, boards not in it are powered on
+	AnnotationNodeOrinBoardPower = "superedge.io/orin-board-power"
+	// AnnotationNodeOrinWakeBoardPrefix with board id is written by scheduler
+	// extender to ask device plugin powering on the board
+	AnnotationNodeOrinWakeBoardPrefix = "superedge.io/orin-wake-board-"
+	// AnnotationNodeOrinTelemetry is json map of device id to its compact
+	// telemetry summary, written by device plugin
+	AnnotationNodeOrinTelemetry = "superedge.io/orin-telemetry"
+	// AnnotationNodeOrinInterconnect is json map of board id to links between
+	// its orins, published by device plugin from the provider
+	AnnotationNodeOrinInterconnect = "superedge.io/orin-interconnect"
+
+	BoardPowerOn     = "on"
+	BoardPowerOff    = "off"
+	BoardPowerWaking = "waking"
+	// BoardPowerPoweringOff is published before switching power off, so
+	// scheduler extender stops binding pods to the board
+	BoardPowerPoweringOff = "powering-off"
+
+	// PodConditionOrinConfigured is set by device plugin after every orin
+	// attr injected into pod, it can be used as readiness gate
+	PodConditionOrinConfigured = "superedge.io/OrinConfigured"
 )
@@ -0,0 +1,269 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/device/types"
+	"k8s.io/klog/v2"
+)
+
+const DefaultCheckpointPath = "/var/lib/orin-device/checkpoint"
+
+// Allocation is devices allocated by kubelet, but the pod is unknown until
+// PreStartContainer
+type Allocation struct {
+	Device *types.Device `json:"device"`
+	Time   time.Time     `json:"time"`
+}
+
+// podRecord is devices of every container of a pod
+type podRecord struct {
+	Namespace  string          `json:"namespace"`
+	Name       string          `json:"name"`
+	Containers json.RawMessage `json:"containers"`
+}
+
+type checkpointData struct {
+	// Pods is pod key to PodInfo val, written by old versions which key pods
+	// by namespace/name
+	Pods map[string]json.RawMessage `json:"pods"`
+	// PodRecords is pod uid to its record, a pod recreated with the same
+	// name is another pod
+	PodRecords  map[string]*podRecord  `json:"podRecords,omitempty"`
+	Allocations map[string]*Allocation `json:"allocations"`
+}
+
+type checkpointFile struct {
+	Data     checkpointData `json:"data"`
+	Checksum uint32         `json:"checksum"`
+}
+
+// Store is a node local record of which pod got which orin devices, every
+// change is written to file atomically, so it survives plugin crash
+type Store struct {
+	path string
+
+	lock sync.RWMutex
+	// pods is keyed by RecordKey
+	pods        map[string]*types.PodInfo
+	allocations map[string]*Allocation
+}
+
+// NewStore load checkpoint from path, a corrupted checkpoint will be moved
+// aside and the store starts empty
+func NewStore(path string) (*Store, error) {
+	s := &Store{
+		path:        path,
+		pods:        make(map[string]*types.PodInfo),
+		allocations: make(map[string]*Allocation),
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	if err := s.load(); err != nil {
+		klog.ErrorS(err, "load checkpoint error, start with empty checkpoint", "path", path)
+		if err := os.Rename(path, path+".corrupted"); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		s.pods = make(map[string]*types.PodInfo)
+		s.allocations = make(map[string]*Allocation)
+	}
+	return s, nil
+}
+
+func (s *Store) load() error {
+	raw, err := os.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	cf := &checkpointFile{}
+	if err := json.Unmarshal(raw, cf); err != nil {
+		return err
+	}
+	data, err := json.Marshal(cf.Data)
+	if err != nil {
+		return err
+	}
+	if sum := crc32.ChecksumIEEE(data); sum != cf.Checksum {
+		return fmt.Errorf("checkpoint checksum mismatch, expect %d, actual %d", cf.Checksum, sum)
+	}
+	for key, val := range cf.Data.Pods {
+		pi, err := types.NewPIFromRaw([]byte(key), val)
+		if err != nil {
+			return err
+		}
+		s.pods[key] = pi
+	}
+	for uid, r := range cf.Data.PodRecords {
+		pi := types.NewPI(r.Namespace, r.Name)
+		pi.UID = uid
+		if err := pi.SetVal(r.Containers); err != nil {
+			return err
+		}
+		s.pods[uid] = pi
+	}
+	for key, a := range cf.Data.Allocations {
+		s.allocations[key] = a
+	}
+	return nil
+}
+
+// save write checkpoint to a temp file and rename it, caller must hold lock
+func (s *Store) save() error {
+	cd := checkpointData{
+		Pods:        make(map[string]json.RawMessage),
+		PodRecords:  make(map[string]*podRecord, len(s.pods)),
+		Allocations: s.allocations,
+	}
+	for key, pi := range s.pods {
+		if pi.UID == "" {
+			cd.Pods[key] = pi.Val()
+			continue
+		}
+		cd.PodRecords[pi.UID] = &podRecord{Namespace: pi.Namespace, Name: pi.Name, Containers: pi.Val()}
+	}
+	data, err := json.Marshal(cd)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(&checkpointFile{Data: cd, Checksum: crc32.ChecksumIEEE(data)})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return err
+	}
+	// make rename durable
+	if dir, err := os.Open(filepath.Dir(s.path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
+
+// RecordKey return key of pod in store, pod uid, or namespace/name of pods
+// recorded by old versions
+func RecordKey(pi *types.PodInfo) string {
+	if pi.UID != "" {
+		return pi.UID
+	}
+	return string(pi.Key())
+}
+
+func allocationKey(device *types.Device) string {
+	return fmt.Sprintf("%s:%s", device.ResourceName, device.Hash)
+}
+
+// AddAllocation record devices allocated by kubelet
+func (s *Store) AddAllocation(device *types.Device) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.allocations[allocationKey(device)] = &Allocation{Device: device, Time: time.Now()}
+	return s.save()
+}
+
+// AddPodDevice record devices of pod container, and remove the allocation of
+// the same devices
+func (s *Store) AddPodDevice(uid, namespace, name, container string, device *types.Device) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	pi, ok := s.pods[uid]
+	if !ok {
+		pi = types.NewPI(namespace, name)
+		pi.UID = uid
+		s.pods[uid] = pi
+	}
+	pi.AddDevice(container, device)
+	delete(s.allocations, allocationKey(device))
+	return s.save()
+}
+
+// DeletePod remove pod of key from checkpoint, it does nothing if pod not
+// exists, key is pod uid or RecordKey
+func (s *Store) DeletePod(key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if _, ok := s.pods[key]; !ok {
+		return nil
+	}
+	delete(s.pods, key)
+	return s.save()
+}
+
+// PruneAllocations remove allocations which never get a pod before deadline
+func (s *Store) PruneAllocations(deadline time.Time) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	pruned := false
+	for key, a := range s.allocations {
+		if a.Time.Before(deadline) {
+			delete(s.allocations, key)
+			pruned = true
+		}
+	}
+	if !pruned {
+		return nil
+	}
+	return s.save()
+}
+
+// Pods return a copy of all pods in checkpoint, sorted by key
+func (s *Store) Pods() []*types.PodInfo {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	keys := make([]string, 0, len(s.pods))
+	for key := range s.pods {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	res := make([]*types.PodInfo, 0, len(keys))
+	for _, key := range keys {
+		pi := s.pods[key]
+		cp := types.NewPI(pi.Namespace, pi.Name)
+		cp.UID = pi.UID
+		if err := cp.SetVal(pi.Val()); err == nil {
+			res = append(res, cp)
+		}
+	}
+	return res
+}
+
+// Allocations return a copy of all allocations which pod is unknown
+func (s *Store) Allocations() []*Allocation {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	res := make([]*Allocation, 0, len(s.allocations))
+	for _, a := range s.allocations {
+		cp := *a
+		res = append(res, &cp)
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Time.Before(res[j].Time) })
+	return res
+}
@@ -0,0 +1,140 @@
+package checkpoint
+
+import (
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/device/types"
+)
+
+func TestStore(t *testing.T) {
+	cpPath := path.Join(t.TempDir(), "checkpoint")
+	s, err := NewStore(cpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d1 := types.NewDevice([]string{"0-1"}, "superedge.io/device-orin-1")
+	d2 := types.NewDevice([]string{"0-2"}, "superedge.io/device-orin-2")
+	d3 := types.NewDevice([]string{"1-1"}, "superedge.io/device-orin-1")
+
+	for _, d := range []*types.Device{d1, d2, d3} {
+		if err := s.AddAllocation(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.AddPodDevice("uid-1", "default", "pod-1", "test", d1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddPodDevice("uid-1", "default", "pod-1", "test", d2); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddPodDevice("uid-2", "default", "pod-2", "test", d3); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Allocations()) != 0 {
+		t.Errorf("allocations should be removed after pod device added, actual %v", s.Allocations())
+	}
+
+	// reload from file
+	s, err = NewStore(cpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pods := s.Pods()
+	if len(pods) != 2 {
+		t.Fatalf("expect 2 pods, actual %v", pods)
+	}
+	if !reflect.DeepEqual(pods[0].DeviceIDs(), []string{"0-1", "0-2"}) {
+		t.Errorf("pod %s devices is not same, expect %v, actual %v", pods[0].Key(), []string{"0-1", "0-2"}, pods[0].DeviceIDs())
+	}
+
+	if err := s.DeletePod("uid-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddAllocation(d1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PruneAllocations(time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	s, err = NewStore(cpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Pods()) != 1 || len(s.Allocations()) != 0 {
+		t.Errorf("expect 1 pod and 0 allocation, actual %v, %v", s.Pods(), s.Allocations())
+	}
+}
+
+func TestStoreRecreatedPod(t *testing.T) {
+	cpPath := path.Join(t.TempDir(), "checkpoint")
+	s, err := NewStore(cpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d1 := types.NewDevice([]string{"0-1"}, "superedge.io/device-orin-1")
+	d2 := types.NewDevice([]string{"1-2"}, "superedge.io/device-orin-2")
+	// statefulset pod recreated with the same name on other devices
+	if err := s.AddPodDevice("uid-old", "default", "web-0", "test", d1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddPodDevice("uid-new", "default", "web-0", "test", d2); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.DeletePod("uid-old"); err != nil {
+		t.Fatal(err)
+	}
+	s, err = NewStore(cpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pods := s.Pods()
+	if len(pods) != 1 || pods[0].UID != "uid-new" || !reflect.DeepEqual(pods[0].DeviceIDs(), []string{"1-2"}) {
+		t.Errorf("expect only devices of new pod, actual %+v", pods)
+	}
+}
+
+func TestLegacyStore(t *testing.T) {
+	cpPath := path.Join(t.TempDir(), "checkpoint")
+	// written by versions which key pods by namespace/name
+	data := `{"pods":{"default/pod-1":{"test":[{"Hash":"h","List":["0-1"],"ResourceName":"superedge.io/device-orin-1"}]}},"allocations":{}}`
+	if err := os.WriteFile(cpPath, []byte(fmt.Sprintf(`{"data":%s,"checksum":%d}`, data, crc32.ChecksumIEEE([]byte(data)))), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStore(cpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pods := s.Pods()
+	if len(pods) != 1 || pods[0].UID != "" || RecordKey(pods[0]) != "default/pod-1" {
+		t.Fatalf("expect legacy pod loaded, actual %+v", pods)
+	}
+	if err := s.DeletePod(RecordKey(pods[0])); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Pods()) != 0 {
+		t.Errorf("expect legacy pod deleted, actual %v", s.Pods())
+	}
+}
+
+func TestCorruptedStore(t *testing.T) {
+	cpPath := path.Join(t.TempDir(), "checkpoint")
+	if err := os.WriteFile(cpPath, []byte(`{"data":{"pods":{"default/pod-1":{}}},"checksum":1}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := NewStore(cpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Pods()) != 0 {
+		t.Errorf("corrupted checkpoint should be ignored, actual %v", s.Pods())
+	}
+	if _, err := os.Stat(cpPath + ".corrupted"); err != nil {
+		t.Errorf("corrupted checkpoint should be moved aside: %v", err)
+	}
+}
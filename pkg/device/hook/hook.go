@@ -0,0 +1,222 @@
+package hook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"k8s.io/klog/v2"
+)
+
+type Phase string
+
+const (
+	// PhaseAllocate hooks run before PreStartContainer returns
+	PhaseAllocate Phase = "allocate"
+	// PhaseRelease hooks run after pod holding the soc terminated
+	PhaseRelease Phase = "release"
+)
+
+type FailurePolicy string
+
+const (
+	// FailurePolicyIgnore only report the failure
+	FailurePolicyIgnore FailurePolicy = "Ignore"
+	// FailurePolicyFail fail PreStartContainer of allocate hook
+	FailurePolicyFail FailurePolicy = "Fail"
+	// FailurePolicyQuarantine fail like FailurePolicyFail, and mark soc
+	// unhealthy until operator clears it
+	FailurePolicyQuarantine FailurePolicy = "Quarantine"
+
+	DefaultTimeout = 30 * time.Second
+)
+
+// HTTPAction is a http call, url and body are templates
+type HTTPAction struct {
+	Method  string            `yaml:"method"`
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+	Body    string            `yaml:"body"`
+}
+
+// Hook is an exec command or http call, every arg of exec and fields of http
+// are text/template rendered with Data
+type Hook struct {
+	Name          string        `yaml:"name"`
+	Exec          []string      `yaml:"exec"`
+	HTTP          *HTTPAction   `yaml:"http"`
+	Timeout       time.Duration `yaml:"timeout"`
+	Retries       int           `yaml:"retries"`
+	FailurePolicy FailurePolicy `yaml:"failurePolicy"`
+}
+
+type Config struct {
+	Allocate []*Hook `yaml:"allocate"`
+	Release  []*Hook `yaml:"release"`
+}
+
+// Data is the template data of hook
+type Data struct {
+	Namespace string
+	Pod       string
+	Device    string
+	BoardID   int
+	SocID     int
+	Board     map[string]interface{}
+	Soc       map[string]interface{}
+}
+
+// Load read hook config from yaml file
+func Load(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Config{}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+	for _, h := range append(append([]*Hook{}, c.Allocate...), c.Release...) {
+		if err := h.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Hooks return hooks of phase
+func (c *Config) Hooks(phase Phase) []*Hook {
+	if c == nil {
+		return nil
+	}
+	if phase == PhaseAllocate {
+		return c.Allocate
+	}
+	return c.Release
+}
+
+// Validate check hook and fill defaults
+func (h *Hook) Validate() error {
+	if h.Name == "" {
+		return fmt.Errorf("hook name is empty")
+	}
+	if (len(h.Exec) == 0) == (h.HTTP == nil) {
+		return fmt.Errorf("hook %s must have exactly one of exec and http", h.Name)
+	}
+	if h.HTTP != nil && h.HTTP.URL == "" {
+		return fmt.Errorf("hook %s http url is empty", h.Name)
+	}
+	switch h.FailurePolicy {
+	case "":
+		h.FailurePolicy = FailurePolicyFail
+	case FailurePolicyIgnore, FailurePolicyFail, FailurePolicyQuarantine:
+	default:
+		return fmt.Errorf("hook %s has unknown failure policy %s", h.Name, h.FailurePolicy)
+	}
+	if h.Timeout <= 0 {
+		h.Timeout = DefaultTimeout
+	}
+	if h.Retries < 0 {
+		h.Retries = 0
+	}
+	return nil
+}
+
+// Run run hook with retries, every attempt is bounded by hook timeout
+func (h *Hook) Run(ctx context.Context, data *Data) error {
+	var err error
+	for attempt := 0; attempt <= h.Retries; attempt++ {
+		if attempt > 0 {
+			klog.V(4).InfoS("retry hook", "hook", h.Name, "device", data.Device, "attempt", attempt, "err", err)
+			select {
+			case <-time.After(time.Duration(attempt) * time.Second):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err = h.runOnce(ctx, data); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("hook %s failed after %d attempts: %v", h.Name, h.Retries+1, err)
+}
+
+func (h *Hook) runOnce(ctx context.Context, data *Data) error {
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+	if len(h.Exec) != 0 {
+		args := make([]string, 0, len(h.Exec))
+		for _, a := range h.Exec {
+			rendered, err := render(a, data)
+			if err != nil {
+				return err
+			}
+			args = append(args, rendered)
+		}
+		out, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("exec %v error: %v, output: %s", args, err, truncate(string(out)))
+		}
+		return nil
+	}
+	url, err := render(h.HTTP.URL, data)
+	if err != nil {
+		return err
+	}
+	body, err := render(h.HTTP.Body, data)
+	if err != nil {
+		return err
+	}
+	method := h.HTTP.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range h.HTTP.Headers {
+		rendered, err := render(v, data)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(k, rendered)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		out, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s returned %d: %s", method, url, resp.StatusCode, truncate(string(out)))
+	}
+	return nil
+}
+
+func render(text string, data *Data) (string, error) {
+	t, err := template.New("hook").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+	buf := &bytes.Buffer{}
+	if err := t.Execute(buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func truncate(s string) string {
+	if len(s) > 512 {
+		return s[:512] + "..."
+	}
+	return s
+}
@@ -0,0 +1,113 @@
+package hook
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			config: `
+allocate:
+  - name: reset
+    exec: ["/bin/sh", "-c", "echo {{.Soc.ip}}"]
+    timeout: 10s
+    retries: 1
+    failurePolicy: Quarantine
+release:
+  - name: clear
+    http:
+      url: http://{{.Soc.ip}}/clear
+`,
+		},
+		{name: "no action", config: "allocate:\n  - name: reset\n", wantErr: true},
+		{name: "unknown policy", config: "release:\n  - name: r\n    exec: [true]\n    failurePolicy: Maybe\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "hooks.yaml")
+			if err := os.WriteFile(path, []byte(tt.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+			c, err := Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if h := c.Hooks(PhaseAllocate)[0]; h.Timeout != 10*time.Second || h.FailurePolicy != FailurePolicyQuarantine {
+				t.Errorf("unexpected allocate hook %+v", h)
+			}
+			if h := c.Hooks(PhaseRelease)[0]; h.Timeout != DefaultTimeout || h.FailurePolicy != FailurePolicyFail {
+				t.Errorf("release hook defaults not set %+v", h)
+			}
+		})
+	}
+}
+
+func TestRunExec(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "out")
+	data := &Data{Device: "1-2", Soc: map[string]interface{}{"ip": "10.0.0.2"}}
+	h := &Hook{Name: "write", Exec: []string{"/bin/sh", "-c", "echo -n {{.Device}} {{.Soc.ip}} > " + out}}
+	if err := h.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Run(context.Background(), data); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if got, _ := ioutil.ReadFile(out); string(got) != "1-2 10.0.0.2" {
+		t.Errorf("unexpected rendered command output %q", got)
+	}
+
+	missing := &Hook{Name: "missing", Exec: []string{"echo", "{{.Soc.serial}}"}}
+	missing.Validate()
+	if err := missing.Run(context.Background(), data); err == nil {
+		t.Errorf("missing template key should fail")
+	}
+
+	slow := &Hook{Name: "slow", Exec: []string{"sleep", "5"}, Timeout: 50 * time.Millisecond}
+	slow.Validate()
+	start := time.Now()
+	if err := slow.Run(context.Background(), data); err == nil || time.Since(start) > 2*time.Second {
+		t.Errorf("hook should time out, err %v, took %s", err, time.Since(start))
+	}
+}
+
+func TestRunHTTPRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/socs/1-2/reset" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	h := &Hook{Name: "reset", HTTP: &HTTPAction{URL: server.URL + "/socs/{{.Device}}/reset"}, Retries: 1}
+	h.Validate()
+	if err := h.Run(context.Background(), &Data{Device: "1-2"}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("expect 2 calls, got %d", calls)
+	}
+}
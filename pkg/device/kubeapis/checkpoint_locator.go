@@ -0,0 +1,217 @@
+package kubeapis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+)
+
+const KubeletCheckpointName = "kubelet_internal_checkpoint"
+
+var KubeletCheckpointPath = path.Join(v1beta1.DevicePluginPath, KubeletCheckpointName)
+
+// podDevicesEntry is the entry of kubelet device manager checkpoint, DeviceIDs
+// is a list before k8s 1.20, and a map of numa node to list since 1.20
+type podDevicesEntry struct {
+	PodUID        string
+	ContainerName string
+	ResourceName  string
+	DeviceIDs     json.RawMessage
+}
+
+type kubeletCheckpoint struct {
+	Data struct {
+		PodDeviceEntries []podDevicesEntry
+	}
+}
+
+// containerDevices is the devices of one resource allocated to a container
+type containerDevices struct {
+	PodUID        string
+	ContainerName string
+	Device        *types.Device
+}
+
+// parseKubeletCheckpoint parse orin devices from kubelet device manager
+// checkpoint, devices of other resource will be ignored
+func parseKubeletCheckpoint(data []byte) ([]*containerDevices, error) {
+	cp := &kubeletCheckpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, err
+	}
+	res := []*containerDevices{}
+	for _, e := range cp.Data.PodDeviceEntries {
+		if !strings.HasPrefix(e.ResourceName, common.ExtendResouceTypeOrin) {
+			continue
+		}
+		ids, err := parseCheckpointDeviceIDs(e.DeviceIDs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid device ids of pod %s: %v", e.PodUID, err)
+		}
+		res = append(res, &containerDevices{
+			PodUID:        e.PodUID,
+			ContainerName: e.ContainerName,
+			Device:        types.NewDevice(ids, v1.ResourceName(e.ResourceName)),
+		})
+	}
+	return res, nil
+}
+
+func parseCheckpointDeviceIDs(raw json.RawMessage) ([]string, error) {
+	list := []string{}
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list, nil
+	}
+	perNUMA := map[string][]string{}
+	if err := json.Unmarshal(raw, &perNUMA); err != nil {
+		return nil, err
+	}
+	for _, ids := range perNUMA {
+		list = append(list, ids...)
+	}
+	return list, nil
+}
+
+// CheckpointDeviceLocator locate devices by kubelet device manager checkpoint,
+// which map device ids to pod uid and container, it is used when kubelet pod
+// resources socket is disabled or slow
+type CheckpointDeviceLocator struct {
+	path    string
+	sitter  Sitter
+	watcher *fsnotify.Watcher
+
+	lock    sync.RWMutex
+	entries []*containerDevices
+	err     error
+}
+
+func NewCheckpointDeviceLocator(checkpointPath string, sitter Sitter) (DeviceLocator, error) {
+	l := &CheckpointDeviceLocator{path: checkpointPath, sitter: sitter}
+	l.reload()
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	// kubelet replace checkpoint file by rename, so watch the directory
+	if err := watcher.Add(filepath.Dir(checkpointPath)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+	l.watcher = watcher
+	go l.watch()
+	return l, nil
+}
+
+func (l *CheckpointDeviceLocator) watch() {
+	for {
+		select {
+		case event, ok := <-l.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Name == l.path && event.Op&(fsnotify.Create|fsnotify.Write|fsnotify.Rename) != 0 {
+				klog.V(5).InfoS("kubelet checkpoint changed", "path", l.path, "op", event.Op.String())
+				l.reload()
+			}
+		case err, ok := <-l.watcher.Errors:
+			if !ok {
+				return
+			}
+			klog.ErrorS(err, "watch kubelet checkpoint error", "path", l.path)
+		}
+	}
+}
+
+func (l *CheckpointDeviceLocator) reload() {
+	entries, err := l.read()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if err != nil {
+		klog.ErrorS(err, "read kubelet checkpoint error", "path", l.path)
+		l.err = err
+		return
+	}
+	l.entries, l.err = entries, nil
+}
+
+func (l *CheckpointDeviceLocator) read() ([]*containerDevices, error) {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return nil, err
+	}
+	return parseKubeletCheckpoint(data)
+}
+
+func (l *CheckpointDeviceLocator) find(devices *types.Device) *containerDevices {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	for _, e := range l.entries {
+		if devices.Equals(e.Device) {
+			return e
+		}
+	}
+	return nil
+}
+
+func (l *CheckpointDeviceLocator) Locate(devices *types.Device) (*types.PodContainer, error) {
+	e := l.find(devices)
+	if e == nil {
+		// kubelet write checkpoint right before PreStartContainer, the event
+		// may not be handled yet
+		l.reload()
+		if e = l.find(devices); e == nil {
+			return nil, fmt.Errorf("not such pod with the same devices list in kubelet checkpoint")
+		}
+	}
+	pod, err := l.sitter.GetPodByUID(e.PodUID)
+	if err != nil {
+		return nil, fmt.Errorf("get pod by uid %s error: %v", e.PodUID, err)
+	}
+	klog.V(5).Infof("pod %s/%s located with device list %v in kubelet checkpoint", pod.Namespace, pod.Name, devices.List)
+	return &types.PodContainer{
+		Namespace: pod.Namespace,
+		Name:      pod.Name,
+		Container: e.ContainerName,
+	}, nil
+}
+
+func (l *CheckpointDeviceLocator) List() ([]*types.PodInfo, error) {
+	l.lock.RLock()
+	defer l.lock.RUnlock()
+	if l.err != nil {
+		return nil, l.err
+	}
+	pods := map[string]*types.PodInfo{}
+	list := []*types.PodInfo{}
+	for _, e := range l.entries {
+		pi, ok := pods[e.PodUID]
+		if !ok {
+			pod, err := l.sitter.GetPodByUID(e.PodUID)
+			if err != nil {
+				// pod has gone, kubelet will clean it up
+				continue
+			}
+			pi = types.NewPI(pod.Namespace, pod.Name)
+			pods[e.PodUID] = pi
+			list = append(list, pi)
+		}
+		pi.AddDevice(e.ContainerName, e.Device)
+	}
+	return list, nil
+}
+
+func (l *CheckpointDeviceLocator) Close() error {
+	return l.watcher.Close()
+}
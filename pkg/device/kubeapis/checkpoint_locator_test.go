@@ -0,0 +1,54 @@
+package kubeapis
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKubeletCheckpoint(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		expect  map[string][]string
+		wantErr bool
+	}{
+		{
+			name: "k8s 1.20+ numa map",
+			data: `{"Data":{"PodDeviceEntries":[
+				{"PodUID":"uid1","ContainerName":"c1","ResourceName":"superedge.io/device-orin-1","DeviceIDs":{"-1":["2-1","1-1"]},"AllocResp":""},
+				{"PodUID":"uid2","ContainerName":"c1","ResourceName":"nvidia.com/gpu","DeviceIDs":{"0":["gpu0"]},"AllocResp":""}
+			],"RegisteredDevices":{}},"Checksum":1}`,
+			expect: map[string][]string{"uid1/c1": {"1-1", "2-1"}},
+		},
+		{
+			name: "k8s 1.19- list",
+			data: `{"Data":{"PodDeviceEntries":[
+				{"PodUID":"uid1","ContainerName":"c2","ResourceName":"superedge.io/device-orin-2","DeviceIDs":["1-2"],"AllocResp":""}
+			],"RegisteredDevices":{}},"Checksum":1}`,
+			expect: map[string][]string{"uid1/c2": {"1-2"}},
+		},
+		{
+			name:    "invalid device ids",
+			data:    `{"Data":{"PodDeviceEntries":[{"PodUID":"uid1","ContainerName":"c1","ResourceName":"superedge.io/device-orin-1","DeviceIDs":"1-1"}]}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := parseKubeletCheckpoint([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseKubeletCheckpoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := map[string][]string{}
+			for _, e := range entries {
+				got[e.PodUID+"/"+e.ContainerName] = e.Device.List
+			}
+			if !reflect.DeepEqual(got, tt.expect) {
+				t.Errorf("parseKubeletCheckpoint() = %v, expect %v", got, tt.expect)
+			}
+		})
+	}
+}
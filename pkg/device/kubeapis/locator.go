@@ -1,18 +1,13 @@
 package kubeapis
 
 import (
-	"context"
 	"fmt"
-	"sync"
 	"time"
 
 	v1 "k8s.io/api/core/v1"
 
-	"google.golang.org/grpc"
 	"k8s.io/klog/v2"
 
-	"github.com/superedge/orin-device-system/pkg/device/podresources"
-	"github.com/superedge/orin-device-system/pkg/device/podresources/v1alpha1"
 	"github.com/superedge/orin-device-system/pkg/device/types"
 )
 
@@ -22,98 +17,143 @@ type DeviceLocator interface {
 	Close() error
 }
 
+// AllocatableLister is implemented by locators which know the devices kubelet
+// consider allocatable
+type AllocatableLister interface {
+	// Allocatable return orin device ids of every resource known by kubelet
+	Allocatable() (map[v1.ResourceName][]string, error)
+}
+
+// KubeletDeviceLocator locate devices of every resource through the shared
+// kubelet pod resources client
 type KubeletDeviceLocator struct {
-	err      error
-	resource string
-	client   v1alpha1.PodResourcesListerClient
-	conn     *grpc.ClientConn
-	lock     sync.Mutex
-}
-
-func NewKubeletDeviceLocator(resource string) DeviceLocator {
-	ep, _ := podresources.LocalEndpoint(podresources.PodResourceRoot, podresources.Socket)
-	client, conn, err := podresources.GetClient(ep, 10*time.Second, 1024*1024*16)
-	return &KubeletDeviceLocator{
-		resource: resource,
-		client:   client,
-		conn:     conn,
-		err:      err,
-	}
+	client *PodResourcesClient
+}
+
+// NewKubeletDeviceLocator create locator on the v1alpha1 pod resources api
+func NewKubeletDeviceLocator() DeviceLocator {
+	return &KubeletDeviceLocator{client: SharedPodResourcesClient(PodResourcesV1alpha1)}
+}
+
+// NewKubeletV1DeviceLocator create locator on the v1 pod resources api, the
+// api version is negotiated on first call, and v1alpha1 is used if kubelet
+// does not serve v1.
+//
+// Get of single pod is added to v1 since k8s 1.27, which is not in the vendored
+// api, so Locate still looks up the result of List.
+func NewKubeletV1DeviceLocator() DeviceLocator {
+	return &KubeletDeviceLocator{client: SharedPodResourcesClient("")}
+}
+
+// NewKubeletV1DirectDeviceLocator create locator like NewKubeletV1DeviceLocator
+// on its own client, which asks kubelet on every call
+func NewKubeletV1DirectDeviceLocator() DeviceLocator {
+	return &KubeletDeviceLocator{client: NewPodResourcesClient("")}
 }
 
 func (k *KubeletDeviceLocator) Locate(devices *types.Device) (*types.PodContainer, error) {
 	klog.V(5).Infof("Locate device %s", devices.List)
-	k.lock.Lock()
-	defer k.lock.Unlock()
-	if k.err != nil {
-		ep, _ := podresources.LocalEndpoint(podresources.PodResourceRoot, podresources.Socket)
-		k.client, k.conn, k.err = podresources.GetClient(ep, 10*time.Second, 1024*1024*16)
-		if k.err != nil {
-			return nil, k.err
-		}
-	}
-
-	response, err := k.client.List(context.Background(), &v1alpha1.ListPodResourcesRequest{})
-	klog.V(5).Infof("List pod resources response %v", response)
+	// devices are allocated right before PreStart and may belong to another
+	// pod in a cached snapshot, so only a snapshot taken after the call is
+	// trusted, concurrent calls still share one List
+	s, err := k.client.Snapshot(time.Now())
 	if err != nil {
-		k.err = err
 		return nil, err
 	}
-	// pod -> container -> resource
-	for _, pod := range response.PodResources {
-		for _, container := range pod.Containers {
-			deviceIds := []string{}
-			for _, resource := range container.Devices {
-				if resource.ResourceName == k.resource {
-					klog.V(5).Infof("found equal resource %s", resource.ResourceName)
-					// for k8s 1.20-, resource.DeviceIds contain all device IDs
-					if devices.Equals(types.NewDevice(resource.DeviceIds, v1.ResourceName(resource.ResourceName))) {
-						klog.V(5).Infof("pod %s/%s located with device list %v", pod.Namespace, pod.Name, resource.DeviceIds)
-						return &types.PodContainer{
-							Namespace: pod.Namespace,
-							Name:      pod.Name,
-							Container: container.Name,
-						}, nil
-					} else { // for k8s 1.21+, resource.DeviceIds contain only one device ID
-						deviceIds = append(deviceIds, resource.DeviceIds...)
-					}
-				}
-			}
-			// for k8s 1.21+
-			if devices.Equals(types.NewDevice(deviceIds, v1.ResourceName(k.resource))) {
-				klog.V(5).Infof("pod %s/%s located with device list %v", pod.Namespace, pod.Name, deviceIds)
-				return &types.PodContainer{
-					Namespace: pod.Namespace,
-					Name:      pod.Name,
-					Container: container.Name,
-				}, nil
-			}
-		}
+	if pc, ok := s.index[snapshotKey(devices.ResourceName, devices.Hash)]; ok {
+		klog.V(5).Infof("pod %s/%s located with device list %v", pc.Namespace, pc.Name, devices.List)
+		return pc, nil
 	}
 	return nil, fmt.Errorf("not such pod with the same devices list")
 }
 
+// List return every pod with orin devices, resource of other device plugin
+// will be ignored, the result is shared and must not be modified
 func (k *KubeletDeviceLocator) List() ([]*types.PodInfo, error) {
-	ans, err := k.client.List(context.Background(), &v1alpha1.ListPodResourcesRequest{})
+	s, err := k.client.Snapshot(time.Time{})
 	if err != nil {
 		return nil, err
 	}
-	// pod -> container -> resource
-	list := []*types.PodInfo{}
-	for _, pod := range ans.PodResources {
-		pi := types.NewPI(pod.Namespace, pod.Name)
-		for _, container := range pod.Containers {
-			for _, resource := range container.Devices {
-				if resource.ResourceName == k.resource {
-					pi.ContainerDeviceMap[container.Name] = types.NewDevice(resource.DeviceIds, v1.ResourceName(k.resource))
-				}
-			}
+	return s.pods, nil
+}
+
+func (k *KubeletDeviceLocator) Allocatable() (map[v1.ResourceName][]string, error) {
+	return k.client.Allocatable()
+}
+
+func (k *KubeletDeviceLocator) Close() error {
+	return k.client.Close()
+}
+
+const (
+	LocatorPodResources         = "podresources"
+	LocatorPodResourcesV1alpha1 = "podresources-v1alpha1"
+	LocatorPodResourcesDirect   = "podresources-direct"
+	LocatorCheckpoint           = "checkpoint"
+)
+
+// NewDeviceLocator create locator by name, sitter is used to get pod by uid
+func NewDeviceLocator(name string, sitter Sitter) (DeviceLocator, error) {
+	switch name {
+	case LocatorPodResources:
+		return NewKubeletV1DeviceLocator(), nil
+	case LocatorPodResourcesV1alpha1:
+		return NewKubeletDeviceLocator(), nil
+	case LocatorPodResourcesDirect:
+		return NewKubeletV1DirectDeviceLocator(), nil
+	case LocatorCheckpoint:
+		return NewCheckpointDeviceLocator(KubeletCheckpointPath, sitter)
+	default:
+		return nil, fmt.Errorf("unknown device locator %s", name)
+	}
+}
+
+// FallbackDeviceLocator try Primary first, and Fallback if Primary failed
+type FallbackDeviceLocator struct {
+	Primary  DeviceLocator
+	Fallback DeviceLocator
+}
+
+func (f *FallbackDeviceLocator) Locate(devices *types.Device) (*types.PodContainer, error) {
+	pc, err := f.Primary.Locate(devices)
+	if err == nil {
+		return pc, nil
+	}
+	klog.V(4).InfoS("primary locator failed, try fallback", "devices", devices.List, "err", err)
+	pc, ferr := f.Fallback.Locate(devices)
+	if ferr != nil {
+		return nil, fmt.Errorf("%v, fallback: %v", err, ferr)
+	}
+	return pc, nil
+}
+
+func (f *FallbackDeviceLocator) List() ([]*types.PodInfo, error) {
+	list, err := f.Primary.List()
+	if err == nil {
+		return list, nil
+	}
+	klog.V(4).InfoS("primary locator list failed, try fallback", "err", err)
+	list, ferr := f.Fallback.List()
+	if ferr != nil {
+		return nil, fmt.Errorf("%v, fallback: %v", err, ferr)
+	}
+	return list, nil
+}
+
+// Allocatable is served by the first locator which implements AllocatableLister
+func (f *FallbackDeviceLocator) Allocatable() (map[v1.ResourceName][]string, error) {
+	for _, l := range []DeviceLocator{f.Primary, f.Fallback} {
+		if al, ok := l.(AllocatableLister); ok {
+			return al.Allocatable()
 		}
-		list = append(list, pi)
 	}
-	return list, err
+	return nil, ErrAllocatableUnsupported
 }
 
-func (k *KubeletDeviceLocator) Close() error {
-	return k.conn.Close()
+func (f *FallbackDeviceLocator) Close() error {
+	perr := f.Primary.Close()
+	if err := f.Fallback.Close(); err != nil {
+		return err
+	}
+	return perr
 }
@@ -0,0 +1,344 @@
+package kubeapis
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	podresourcesv1 "k8s.io/kubelet/pkg/apis/podresources/v1"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/podresources"
+	"github.com/superedge/orin-device-system/pkg/device/podresources/v1alpha1"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+)
+
+const (
+	PodResourcesV1       = "v1"
+	PodResourcesV1alpha1 = "v1alpha1"
+
+	// DefaultSnapshotTTL is how long a List of kubelet pod resources is reused
+	DefaultSnapshotTTL = time.Second
+
+	podResourcesTimeout        = 10 * time.Second
+	podResourcesMaxMsgSize     = 1024 * 1024 * 16
+	podResourcesBackoffInitial = 500 * time.Millisecond
+	podResourcesBackoffMax     = 30 * time.Second
+)
+
+// ErrAllocatableUnsupported means kubelet can not report allocatable devices,
+// it is older than k8s 1.21 or KubeletPodResourcesGetAllocatable is disabled
+var ErrAllocatableUnsupported = fmt.Errorf("kubelet pod resources GetAllocatableResources unsupported")
+
+// podResourcesSnapshot is orin devices of every pod on node at a time
+type podResourcesSnapshot struct {
+	time time.Time
+	pods []*types.PodInfo
+	// index is resource name and device hash to container
+	index map[string]*types.PodContainer
+}
+
+func snapshotKey(resourceName v1.ResourceName, hash string) string {
+	return fmt.Sprintf("%s/%s", resourceName, hash)
+}
+
+// containerDeviceIDs is device ids of a container grouped by orin resource
+type containerDeviceIDs struct {
+	namespace, name, container string
+	devices                    map[v1.ResourceName][]string
+}
+
+func newSnapshot(containers []*containerDeviceIDs) *podResourcesSnapshot {
+	s := &podResourcesSnapshot{time: time.Now(), index: map[string]*types.PodContainer{}}
+	pods := map[string]*types.PodInfo{}
+	for _, c := range containers {
+		if len(c.devices) == 0 {
+			continue
+		}
+		key := c.namespace + "/" + c.name
+		pi, ok := pods[key]
+		if !ok {
+			pi = types.NewPI(c.namespace, c.name)
+			pods[key] = pi
+			s.pods = append(s.pods, pi)
+		}
+		for resourceName, ids := range c.devices {
+			device := types.NewDevice(ids, resourceName)
+			pi.AddDevice(c.container, device)
+			s.index[snapshotKey(resourceName, device.Hash)] = &types.PodContainer{
+				Namespace: c.namespace,
+				Name:      c.name,
+				Container: c.container,
+			}
+		}
+	}
+	return s
+}
+
+// PodResourcesClient is a kubelet pod resources client shared by every
+// locator, it reconnects with backoff, caches List for a short time and merges
+// concurrent List calls into one
+type PodResourcesClient struct {
+	// SnapshotTTL is how long a snapshot is reused, zero means every
+	// snapshot is listed fresh
+	SnapshotTTL time.Duration
+
+	lock        sync.Mutex
+	version     string
+	allocatable bool
+	v1          podresourcesv1.PodResourcesListerClient
+	v1alpha1    v1alpha1.PodResourcesListerClient
+	conn        *grpc.ClientConn
+	err         error
+	failures    int
+	retryAt     time.Time
+	snapshot    *podResourcesSnapshot
+	refs        int
+
+	group singleflight.Group
+}
+
+var (
+	sharedClientsLock sync.Mutex
+	sharedClients     = map[string]*PodResourcesClient{}
+)
+
+// SharedPodResourcesClient return the client of version shared in process,
+// empty version means negotiate with kubelet, Close it when no longer used
+func SharedPodResourcesClient(version string) *PodResourcesClient {
+	sharedClientsLock.Lock()
+	defer sharedClientsLock.Unlock()
+	c, ok := sharedClients[version]
+	if !ok {
+		c = &PodResourcesClient{SnapshotTTL: DefaultSnapshotTTL, version: version}
+		sharedClients[version] = c
+	}
+	c.lock.Lock()
+	c.refs++
+	c.lock.Unlock()
+	return c
+}
+
+// NewPodResourcesClient return a client not shared with other locators, it
+// has its own connection and never reuses a snapshot
+func NewPodResourcesClient(version string) *PodResourcesClient {
+	return &PodResourcesClient{version: version, refs: 1}
+}
+
+// connect dial kubelet if not connected, and fail fast in backoff, caller
+// must hold lock
+func (c *PodResourcesClient) connect() error {
+	if c.err == nil && (c.v1 != nil || c.v1alpha1 != nil) {
+		return nil
+	}
+	if time.Now().Before(c.retryAt) {
+		return fmt.Errorf("kubelet pod resources in backoff until %s: %v", c.retryAt.Format(time.RFC3339), c.err)
+	}
+	if c.conn != nil {
+		c.conn.Close()
+		c.conn = nil
+	}
+	ep, _ := podresources.LocalEndpoint(podresources.PodResourceRoot, podresources.Socket)
+	var conn *grpc.ClientConn
+	var err error
+	if c.version == PodResourcesV1alpha1 {
+		c.v1alpha1, conn, err = podresources.GetClient(ep, podResourcesTimeout, podResourcesMaxMsgSize)
+	} else {
+		c.v1, conn, err = podresources.GetV1Client(ep, podResourcesTimeout, podResourcesMaxMsgSize)
+	}
+	if err != nil {
+		c.fail(err)
+		return err
+	}
+	c.conn, c.err = conn, nil
+	return nil
+}
+
+// fail record error and extend backoff, caller must hold lock
+func (c *PodResourcesClient) fail(err error) {
+	c.err = err
+	delay := podResourcesBackoffInitial << uint(c.failures)
+	if delay > podResourcesBackoffMax || delay <= 0 {
+		delay = podResourcesBackoffMax
+	} else {
+		c.failures++
+	}
+	c.retryAt = time.Now().Add(delay)
+	klog.ErrorS(err, "kubelet pod resources call failed", "retryAfter", delay)
+}
+
+func (c *PodResourcesClient) succeed() {
+	c.err, c.failures, c.retryAt = nil, 0, time.Time{}
+}
+
+// negotiate decide api version by calling v1 List, caller must hold lock
+func (c *PodResourcesClient) negotiate() error {
+	if c.version != "" {
+		return nil
+	}
+	if err := c.connect(); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), podResourcesTimeout)
+	defer cancel()
+	_, err := c.v1.List(ctx, &podresourcesv1.ListPodResourcesRequest{})
+	switch {
+	case status.Code(err) == codes.Unimplemented:
+		c.version = PodResourcesV1alpha1
+		if c.v1alpha1 == nil && c.conn != nil {
+			c.v1alpha1 = v1alpha1.NewPodResourcesListerClient(c.conn)
+		}
+	case err != nil:
+		c.fail(err)
+		return err
+	default:
+		c.version = PodResourcesV1
+		_, err = c.v1.GetAllocatableResources(ctx, &podresourcesv1.AllocatableResourcesRequest{})
+		c.allocatable = status.Code(err) != codes.Unimplemented
+	}
+	klog.InfoS("kubelet pod resources api negotiated", "version", c.version, "allocatable", c.allocatable)
+	return nil
+}
+
+// Version return negotiated api version, empty if not negotiated yet
+func (c *PodResourcesClient) Version() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.version
+}
+
+// Snapshot return a snapshot taken at or after since, concurrent callers share
+// the same List call
+func (c *PodResourcesClient) Snapshot(since time.Time) (*podResourcesSnapshot, error) {
+	c.lock.Lock()
+	s := c.snapshot
+	c.lock.Unlock()
+	if s != nil && !s.time.Before(since) && time.Since(s.time) < c.SnapshotTTL {
+		return s, nil
+	}
+	// a shared call may start before since, then list again
+	for i := 0; i < 2; i++ {
+		v, err, _ := c.group.Do("list", func() (interface{}, error) {
+			return c.list()
+		})
+		if err != nil {
+			return nil, err
+		}
+		if s = v.(*podResourcesSnapshot); !s.time.Before(since) {
+			break
+		}
+	}
+	return s, nil
+}
+
+func (c *PodResourcesClient) list() (*podResourcesSnapshot, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := c.negotiate(); err != nil {
+		return nil, err
+	}
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), podResourcesTimeout)
+	defer cancel()
+	start := time.Now()
+	var containers []*containerDeviceIDs
+	if c.version == PodResourcesV1alpha1 {
+		response, err := c.v1alpha1.List(ctx, &v1alpha1.ListPodResourcesRequest{})
+		if err != nil {
+			c.fail(err)
+			return nil, err
+		}
+		for _, pod := range response.PodResources {
+			for _, container := range pod.Containers {
+				devices := map[v1.ResourceName][]string{}
+				for _, d := range container.Devices {
+					addOrinDeviceIDs(devices, d.ResourceName, d.DeviceIds)
+				}
+				containers = append(containers, &containerDeviceIDs{pod.Namespace, pod.Name, container.Name, devices})
+			}
+		}
+	} else {
+		response, err := c.v1.List(ctx, &podresourcesv1.ListPodResourcesRequest{})
+		if err != nil {
+			c.fail(err)
+			return nil, err
+		}
+		for _, pod := range response.PodResources {
+			for _, container := range pod.Containers {
+				containers = append(containers, &containerDeviceIDs{pod.Namespace, pod.Name, container.Name, orinDeviceIDs(container.Devices)})
+			}
+		}
+	}
+	c.succeed()
+	s := newSnapshot(containers)
+	// the snapshot is at least as new as the request
+	s.time = start
+	c.snapshot = s
+	return s, nil
+}
+
+// Allocatable return orin device ids of every resource known by kubelet
+func (c *PodResourcesClient) Allocatable() (map[v1.ResourceName][]string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := c.negotiate(); err != nil {
+		return nil, err
+	}
+	if c.version != PodResourcesV1 || !c.allocatable {
+		return nil, ErrAllocatableUnsupported
+	}
+	if err := c.connect(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), podResourcesTimeout)
+	defer cancel()
+	response, err := c.v1.GetAllocatableResources(ctx, &podresourcesv1.AllocatableResourcesRequest{})
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+	c.succeed()
+	return orinDeviceIDs(response.Devices), nil
+}
+
+// Close release the client, connection is closed after every user closed it
+func (c *PodResourcesClient) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.refs > 0 {
+		c.refs--
+	}
+	if c.refs > 0 || c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn, c.v1, c.v1alpha1, c.snapshot = nil, nil, nil, nil
+	return err
+}
+
+// orinDeviceIDs group device ids of orin resources by resource name
+func orinDeviceIDs(devices []*podresourcesv1.ContainerDevices) map[v1.ResourceName][]string {
+	res := map[v1.ResourceName][]string{}
+	for _, d := range devices {
+		addOrinDeviceIDs(res, d.ResourceName, d.DeviceIds)
+	}
+	return res
+}
+
+// addOrinDeviceIDs add ids if resource is orin-N or orin count, k8s 1.21+
+// report one entry per device, and older report one entry per resource
+func addOrinDeviceIDs(res map[v1.ResourceName][]string, resourceName string, ids []string) {
+	if strings.HasPrefix(resourceName, common.ExtendResouceTypeOrin) {
+		res[v1.ResourceName(resourceName)] = append(res[v1.ResourceName(resourceName)], ids...)
+	}
+}
@@ -0,0 +1,213 @@
+package kubeapis
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	v1 "k8s.io/api/core/v1"
+	podresourcesv1 "k8s.io/kubelet/pkg/apis/podresources/v1"
+
+	"github.com/superedge/orin-device-system/pkg/device/podresources/v1alpha1"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+)
+
+type countingV1Client struct {
+	lock        sync.Mutex
+	calls       int
+	list        *podresourcesv1.ListPodResourcesResponse
+	listErr     error
+	allocatable *podresourcesv1.AllocatableResourcesResponse
+	allocErr    error
+}
+
+func (f *countingV1Client) List(ctx context.Context, in *podresourcesv1.ListPodResourcesRequest, opts ...grpc.CallOption) (*podresourcesv1.ListPodResourcesResponse, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.calls++
+	return f.list, f.listErr
+}
+
+func (f *countingV1Client) GetAllocatableResources(ctx context.Context, in *podresourcesv1.AllocatableResourcesRequest, opts ...grpc.CallOption) (*podresourcesv1.AllocatableResourcesResponse, error) {
+	return f.allocatable, f.allocErr
+}
+
+func (f *countingV1Client) Calls() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.calls
+}
+
+type fakeV1alpha1Client struct {
+	list *v1alpha1.ListPodResourcesResponse
+}
+
+func (f *fakeV1alpha1Client) List(ctx context.Context, in *v1alpha1.ListPodResourcesRequest, opts ...grpc.CallOption) (*v1alpha1.ListPodResourcesResponse, error) {
+	return f.list, nil
+}
+
+var testV1List = &podresourcesv1.ListPodResourcesResponse{PodResources: []*podresourcesv1.PodResources{{
+	Name:      "p1",
+	Namespace: "default",
+	Containers: []*podresourcesv1.ContainerResources{{
+		Name: "c1",
+		Devices: []*podresourcesv1.ContainerDevices{
+			{ResourceName: "superedge.io/device-orin-1", DeviceIds: []string{"2-1"}},
+			{ResourceName: "superedge.io/device-orin-1", DeviceIds: []string{"1-1"}},
+			{ResourceName: "nvidia.com/gpu", DeviceIds: []string{"gpu0"}},
+		},
+	}},
+}}}
+
+func TestPodResourcesClientNegotiate(t *testing.T) {
+	allocatable := &podresourcesv1.AllocatableResourcesResponse{Devices: []*podresourcesv1.ContainerDevices{
+		{ResourceName: "superedge.io/device-orin-1", DeviceIds: []string{"1-1", "2-1"}},
+		{ResourceName: "nvidia.com/gpu", DeviceIds: []string{"gpu0"}},
+	}}
+	legacy := &fakeV1alpha1Client{list: &v1alpha1.ListPodResourcesResponse{PodResources: []*v1alpha1.PodResources{{
+		Name:      "legacy",
+		Namespace: "default",
+		Containers: []*v1alpha1.ContainerResources{{
+			Name:    "c1",
+			Devices: []*v1alpha1.ContainerDevices{{ResourceName: "superedge.io/device-orin-1", DeviceIds: []string{"1-1", "2-1"}}},
+		}},
+	}}}}
+
+	tests := []struct {
+		name            string
+		client          *countingV1Client
+		expectVersion   string
+		expectPods      []string
+		expectAllocable map[v1.ResourceName][]string
+		expectAllocErr  error
+	}{
+		{
+			name:            "v1 with allocatable",
+			client:          &countingV1Client{list: testV1List, allocatable: allocatable},
+			expectVersion:   PodResourcesV1,
+			expectPods:      []string{"p1"},
+			expectAllocable: map[v1.ResourceName][]string{"superedge.io/device-orin-1": {"1-1", "2-1"}},
+		},
+		{
+			name:           "v1 without allocatable",
+			client:         &countingV1Client{list: testV1List, allocErr: status.Error(codes.Unimplemented, "")},
+			expectVersion:  PodResourcesV1,
+			expectPods:     []string{"p1"},
+			expectAllocErr: ErrAllocatableUnsupported,
+		},
+		{
+			name:           "fallback to v1alpha1",
+			client:         &countingV1Client{listErr: status.Error(codes.Unimplemented, "")},
+			expectVersion:  PodResourcesV1alpha1,
+			expectPods:     []string{"legacy"},
+			expectAllocErr: ErrAllocatableUnsupported,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &PodResourcesClient{SnapshotTTL: DefaultSnapshotTTL, v1: tt.client, v1alpha1: legacy}
+			k := &KubeletDeviceLocator{client: c}
+			pods, err := k.List()
+			if err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if v := c.Version(); v != tt.expectVersion {
+				t.Errorf("Version() = %s, expect %s", v, tt.expectVersion)
+			}
+			names := []string{}
+			for _, pi := range pods {
+				names = append(names, pi.Name)
+			}
+			if !reflect.DeepEqual(names, tt.expectPods) {
+				t.Errorf("List() = %v, expect %v", names, tt.expectPods)
+			}
+			pc, err := k.Locate(types.NewDevice([]string{"1-1", "2-1"}, "superedge.io/device-orin-1"))
+			if err != nil || pc.Name != tt.expectPods[0] || pc.Container != "c1" {
+				t.Errorf("Locate() = %+v, %v", pc, err)
+			}
+			got, err := k.Allocatable()
+			if err != tt.expectAllocErr {
+				t.Fatalf("Allocatable() error = %v, expect %v", err, tt.expectAllocErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.expectAllocable) {
+				t.Errorf("Allocatable() = %v, expect %v", got, tt.expectAllocable)
+			}
+		})
+	}
+}
+
+func TestPodResourcesClientCache(t *testing.T) {
+	fake := &countingV1Client{list: testV1List}
+	c := &PodResourcesClient{SnapshotTTL: time.Hour, version: PodResourcesV1, v1: fake}
+	k := &KubeletDeviceLocator{client: c}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := k.List(); err != nil {
+				t.Errorf("List() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if calls := fake.Calls(); calls != 1 {
+		t.Errorf("expect one List for concurrent List, got %d", calls)
+	}
+
+	// devices of a deleted pod are allocated to a new pod after the snapshot,
+	// Locate must not return the previous owner
+	fake.lock.Lock()
+	fake.list = &podresourcesv1.ListPodResourcesResponse{PodResources: []*podresourcesv1.PodResources{{
+		Name:       "p2",
+		Namespace:  "default",
+		Containers: testV1List.PodResources[0].Containers,
+	}}}
+	fake.lock.Unlock()
+	pc, err := k.Locate(types.NewDevice([]string{"1-1", "2-1"}, "superedge.io/device-orin-1"))
+	if err != nil || pc.Name != "p2" {
+		t.Errorf("Locate() = %+v, %v, expect p2", pc, err)
+	}
+	if calls := fake.Calls(); calls != 2 {
+		t.Errorf("expect Locate to refresh snapshot, got %d List", calls)
+	}
+	if _, err := k.Locate(types.NewDevice([]string{"1-1"}, "superedge.io/device-orin-1")); err == nil {
+		t.Errorf("Locate() of partial devices should fail")
+	}
+}
+
+func TestPodResourcesClientDirect(t *testing.T) {
+	fake := &countingV1Client{list: testV1List}
+	c := NewPodResourcesClient(PodResourcesV1)
+	c.v1 = fake
+	k := &KubeletDeviceLocator{client: c}
+	for i := 0; i < 2; i++ {
+		if pods, err := k.List(); err != nil || len(pods) != 1 {
+			t.Fatalf("List() = %v, %v", pods, err)
+		}
+	}
+	if calls := fake.Calls(); calls != 2 {
+		t.Errorf("expect every List to ask kubelet, got %d List", calls)
+	}
+}
+
+func TestPodResourcesClientBackoff(t *testing.T) {
+	fake := &countingV1Client{listErr: status.Error(codes.Unavailable, "")}
+	c := &PodResourcesClient{SnapshotTTL: DefaultSnapshotTTL, version: PodResourcesV1, v1: fake}
+	if _, err := c.Snapshot(time.Time{}); err == nil {
+		t.Fatalf("Snapshot() should fail")
+	}
+	// fail fast without calling kubelet in backoff
+	if _, err := c.Snapshot(time.Time{}); err == nil {
+		t.Fatalf("Snapshot() should fail in backoff")
+	}
+	if calls := fake.Calls(); calls != 1 {
+		t.Errorf("expect no List in backoff, got %d", calls)
+	}
+}
@@ -5,19 +5,28 @@ import (
 	"time"
 
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	listerv1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 )
 
+const podUIDIndex = "uid"
+
 type Sitter interface {
 	Start()
 	GetPod(namespace, name string) (*v1.Pod, error)
-	GetPodFromApiServer(namespace, name string) (*v1.Pod, error)
+	GetPodByUID(uid string) (*v1.Pod, error)
+	// ListPods list pods on this node from informer
+	ListPods() ([]*v1.Pod, error)
+	GetPodFromApiServer(ctx context.Context, namespace, name string) (*v1.Pod, error)
 	GetNodeFromApiServer(name string) (*v1.Node, error)
+	// AddPodEventHandler add handler of pods on this node
+	AddPodEventHandler(handler cache.ResourceEventHandler)
 	HasSynced() bool
 }
 
@@ -37,14 +46,33 @@ func (p *PodSitter) GetPod(namespace, name string) (*v1.Pod, error) {
 	return p.podLister.Pods(namespace).Get(name)
 }
 
-func (p *PodSitter) GetPodFromApiServer(namespace, name string) (*v1.Pod, error) {
-	return p.client.CoreV1().Pods(namespace).Get(context.Background(), name, metav1.GetOptions{})
+func (p *PodSitter) GetPodByUID(uid string) (*v1.Pod, error) {
+	objs, err := p.podInformer.GetIndexer().ByIndex(podUIDIndex, uid)
+	if err != nil {
+		return nil, err
+	}
+	if len(objs) == 0 {
+		return nil, errors.NewNotFound(v1.Resource("pods"), uid)
+	}
+	return objs[0].(*v1.Pod), nil
+}
+
+func (p *PodSitter) ListPods() ([]*v1.Pod, error) {
+	return p.podLister.List(labels.Everything())
+}
+
+func (p *PodSitter) GetPodFromApiServer(ctx context.Context, namespace, name string) (*v1.Pod, error) {
+	return p.client.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
 func (p *PodSitter) GetNodeFromApiServer(name string) (*v1.Node, error) {
 	return p.client.CoreV1().Nodes().Get(context.Background(), name, metav1.GetOptions{})
 }
 
+func (p *PodSitter) AddPodEventHandler(handler cache.ResourceEventHandler) {
+	p.podInformer.AddEventHandler(handler)
+}
+
 func (p *PodSitter) HasSynced() bool {
 	if p.podInformer == nil {
 		return false
@@ -59,6 +87,13 @@ func NewSitter(client *kubernetes.Clientset, nodeName string) Sitter {
 		informersFactory: informers.NewSharedInformerFactoryWithOptions(client, time.Second, informers.WithTweakListOptions(nodeNameFilter(nodeName))),
 	}
 	ps.podInformer = ps.informersFactory.Core().V1().Pods().Informer()
+	ps.podInformer.AddIndexers(cache.Indexers{podUIDIndex: func(obj interface{}) ([]string, error) {
+		pod, ok := obj.(*v1.Pod)
+		if !ok {
+			return nil, nil
+		}
+		return []string{string(pod.UID)}, nil
+	}})
 	ps.podLister = ps.informersFactory.Core().V1().Pods().Lister()
 	return ps
 }
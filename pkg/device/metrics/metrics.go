@@ -0,0 +1,255 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/klog/v2"
+)
+
+const (
+	namespace = "orin_device_plugin"
+
+	ResultSuccess = "success"
+	ResultError   = "error"
+
+	metricsPath = "/metrics"
+	healthzPath = "/healthz"
+	readyzPath  = "/readyz"
+)
+
+// telemetryLabels is the board device_num, soc name and the pod owning the
+// soc, pod is empty if soc is not allocated
+var telemetryLabels = []string{"device_num", "soc", "pod"}
+
+var (
+	AllocateTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "allocate_total",
+		Help:      "Number of Allocate calls by resource and result.",
+	}, []string{"resource", "result"})
+
+	AllocateDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "allocate_duration_seconds",
+		Help:      "Latency of Allocate calls.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"resource"})
+
+	PreStartTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "prestart_total",
+		Help:      "Number of PreStartContainer calls by resource, result and error reason.",
+	}, []string{"resource", "result", "reason"})
+
+	PreStartDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "prestart_duration_seconds",
+		Help:      "Latency of PreStartContainer calls.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"resource"})
+
+	LocateDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "locate_duration_seconds",
+		Help:      "Latency of DeviceLocator.Locate calls by result.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"result"})
+
+	LocateMissTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "locate_miss_total",
+		Help:      "Number of DeviceLocator.Locate calls which found no pod.",
+	}, []string{"resource"})
+
+	PodLookupTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "pod_lookup_total",
+		Help:      "Number of PreStartContainer pod lookups by source and result, source is informer, informer_retry or apiserver.",
+	}, []string{"source", "result"})
+
+	RegistrationTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kubelet_registration_total",
+		Help:      "Number of registrations to kubelet by resource and result.",
+	}, []string{"resource", "result"})
+
+	KubeletRestartTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kubelet_restart_total",
+		Help:      "Number of kubelet restarts detected.",
+	})
+
+	AllocatableMismatch = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "allocatable_mismatch_devices",
+		Help:      "Number of devices differ between kubelet allocatable and ListAndWatch advertised.",
+	}, []string{"resource"})
+
+	AuditFindings = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "audit_findings",
+		Help:      "Number of devices found inconsistent by the last audit, by kind.",
+	}, []string{"kind"})
+
+	HookTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "hook_total",
+		Help:      "Number of lifecycle hook runs by phase, hook and result.",
+	}, []string{"phase", "hook", "result"})
+
+	BoardPowered = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "board_powered",
+		Help:      "Whether board is powered on, 0 means powered off or waking.",
+	}, []string{"board"})
+
+	AgentUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "agent_up",
+		Help:      "Whether agent on orin soc answered the last poll, 1 means answered.",
+	}, []string{"board", "orin"})
+
+	SocCPUUtilization = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "soc_cpu_utilization_percent",
+		Help:      "CPU utilization of orin soc.",
+	}, telemetryLabels)
+
+	SocGPUUtilization = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "soc_gpu_utilization_percent",
+		Help:      "GPU utilization of orin soc.",
+	}, telemetryLabels)
+
+	SocDLAUtilization = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "soc_dla_utilization_percent",
+		Help:      "Utilization of every DLA engine of orin soc.",
+	}, append(telemetryLabels, "dla"))
+
+	SocMemoryUsed = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "soc_memory_used_bytes",
+		Help:      "Used memory of orin soc.",
+	}, telemetryLabels)
+
+	SocMemoryTotal = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "soc_memory_total_bytes",
+		Help:      "Total memory of orin soc.",
+	}, telemetryLabels)
+
+	SocTemperature = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "soc_temperature_celsius",
+		Help:      "Temperature of every thermal zone of orin soc.",
+	}, append(telemetryLabels, "zone"))
+
+	SocPower = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "soc_power_milliwatts",
+		Help:      "Power of every rail of orin soc.",
+	}, append(telemetryLabels, "rail"))
+
+	DeviceHealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "device_healthy",
+		Help:      "Whether orin soc is healthy, 1 means healthy.",
+	}, []string{"board", "orin"})
+
+	DeviceAllocated = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "device_allocated",
+		Help:      "Whether orin soc is allocated to a pod, 1 means allocated.",
+	}, []string{"board", "orin"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		AllocateTotal,
+		AllocateDuration,
+		PreStartTotal,
+		PreStartDuration,
+		LocateDuration,
+		LocateMissTotal,
+		PodLookupTotal,
+		RegistrationTotal,
+		KubeletRestartTotal,
+		AllocatableMismatch,
+		AuditFindings,
+		HookTotal,
+		BoardPowered,
+		AgentUp,
+		SocCPUUtilization,
+		SocGPUUtilization,
+		SocDLAUtilization,
+		SocMemoryUsed,
+		SocMemoryTotal,
+		SocTemperature,
+		SocPower,
+		DeviceHealthy,
+		DeviceAllocated,
+	)
+}
+
+// DeviceLabels return board and orin label values of device gauges
+func DeviceLabels(boardID, orinID int) prometheus.Labels {
+	return prometheus.Labels{"board": strconv.Itoa(boardID), "orin": strconv.Itoa(orinID)}
+}
+
+// TelemetryLabels return label values of soc telemetry gauges
+func TelemetryLabels(deviceNum, soc, pod string) prometheus.Labels {
+	return prometheus.Labels{"device_num": deviceNum, "soc": soc, "pod": pod}
+}
+
+// ResetTelemetry remove all soc telemetry, so socs gone or moved to another
+// pod are not reported with stale labels
+func ResetTelemetry() {
+	for _, g := range []*prometheus.GaugeVec{SocCPUUtilization, SocGPUUtilization, SocDLAUtilization,
+		SocMemoryUsed, SocMemoryTotal, SocTemperature, SocPower} {
+		g.Reset()
+	}
+}
+
+func Result(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultSuccess
+}
+
+// Serve serve /metrics, /healthz and /readyz on port, ready return nil when
+// plugin is ready
+func Serve(port int, ready func() error) error {
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, promhttp.Handler())
+	mux.HandleFunc(healthzPath, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+	mux.HandleFunc(readyzPath, func(w http.ResponseWriter, r *http.Request) {
+		if err := ready(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+	klog.Infof("metrics server starting on the port :%d", port)
+	return http.ListenAndServe(fmt.Sprintf(":%d", port), mux)
+}
+
+// ServeDebug serve debug handlers on address, they dump pods of the node, so
+// address should be on localhost
+func ServeDebug(address string, handlers map[string]http.HandlerFunc) error {
+	mux := http.NewServeMux()
+	for p, h := range handlers {
+		mux.HandleFunc(p, h)
+	}
+	klog.Infof("debug server starting on %s", address)
+	return http.ListenAndServe(address, mux)
+}
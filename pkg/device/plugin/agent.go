@@ -0,0 +1,182 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/agent/api"
+	"github.com/superedge/orin-device-system/pkg/device/metrics"
+	"github.com/superedge/orin-device-system/pkg/device/provider"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+const (
+	ReasonAgentUnhealthy = "AgentUnhealthy"
+	ReasonAgentRecovered = "AgentRecovered"
+
+	DefaultAgentPeriod = 30 * time.Second
+	agentCallTimeout   = 5 * time.Second
+)
+
+// socAgent is the connection to agent on a soc and its last identity
+type socAgent struct {
+	addr     string
+	conn     *grpc.ClientConn
+	client   api.AgentClient
+	identity *api.Identity
+	healthy  bool
+}
+
+// agent return agent client of device, connection is created lazily and
+// recreated if soc ip changed
+func (odp *OrinDevicePlugin) agent(id, addr string) (*socAgent, error) {
+	odp.agentLock.Lock()
+	defer odp.agentLock.Unlock()
+	if a, ok := odp.agents[id]; ok {
+		if a.addr == addr {
+			return a, nil
+		}
+		a.conn.Close()
+		delete(odp.agents, id)
+	}
+	creds := grpc.WithInsecure()
+	if odp.AgentTLS != nil {
+		creds = grpc.WithTransportCredentials(credentials.NewTLS(odp.AgentTLS))
+	}
+	conn, err := grpc.Dial(addr, creds)
+	if err != nil {
+		return nil, err
+	}
+	a := &socAgent{addr: addr, conn: conn, client: api.NewAgentClient(conn), healthy: true}
+	odp.agents[id] = a
+	return a, nil
+}
+
+// pollAgents ask agent of every powered on soc for identity and health
+func (odp *OrinDevicePlugin) pollAgents() {
+	wg := sync.WaitGroup{}
+	for _, boardID := range odp.DeviceProvider.GetBoards() {
+		for _, orinID := range odp.DeviceProvider.GetBoardOrins(boardID) {
+			id := types.OrinDeviceID(boardID, orinID)
+			ip, _ := odp.DeviceProvider.GetOrinAttrs(boardID, orinID)[provider.AttrKeyOrinIp].(string)
+			if ip == "" || odp.boardPoweredOff(id) {
+				continue
+			}
+			wg.Add(1)
+			go func(boardID, orinID int, addr string) {
+				defer wg.Done()
+				odp.pollAgent(boardID, orinID, addr)
+			}(boardID, orinID, net.JoinHostPort(ip, strconv.Itoa(odp.AgentPort)))
+		}
+	}
+	wg.Wait()
+}
+
+func (odp *OrinDevicePlugin) pollAgent(boardID, orinID int, addr string) {
+	id := types.OrinDeviceID(boardID, orinID)
+	a, err := odp.agent(id, addr)
+	if err != nil {
+		klog.ErrorS(err, "dial soc agent error", "device", id, "address", addr)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), agentCallTimeout)
+	defer cancel()
+	identity, err := a.client.GetIdentity(ctx, &api.Empty{})
+	var status *api.Status
+	if err == nil {
+		status, err = a.client.GetStatus(ctx, &api.Empty{})
+	}
+	up := 1.0
+	healthy, message := true, ""
+	switch {
+	case err != nil:
+		up = 0
+		healthy, message = false, fmt.Sprintf("agent %s unreachable: %v", addr, err)
+	case !status.Healthy:
+		healthy, message = false, status.Message
+	}
+	metrics.AgentUp.With(metrics.DeviceLabels(boardID, orinID)).Set(up)
+
+	odp.agentLock.Lock()
+	if identity != nil {
+		if int(identity.SocIndex) != orinID {
+			klog.Warningf("agent %s of device %s reports soc index %d", addr, id, identity.SocIndex)
+		}
+		a.identity = identity
+	}
+	changed := a.healthy != healthy
+	a.healthy = healthy
+	odp.agentLock.Unlock()
+
+	if !healthy {
+		odp.Health.SetHealth(id, v1beta1.Unhealthy)
+	} else if changed {
+		odp.setHealthy(id)
+	}
+	if !changed {
+		return
+	}
+	if healthy {
+		klog.InfoS("soc agent recovered", "device", id)
+		odp.event(odp.nodeRef(), v1.EventTypeNormal, ReasonAgentRecovered, fmt.Sprintf("device %s agent is healthy", id))
+	} else {
+		klog.InfoS("soc agent unhealthy", "device", id, "message", message)
+		odp.event(odp.nodeRef(), v1.EventTypeWarning, ReasonAgentUnhealthy, fmt.Sprintf("device %s unhealthy: %s", id, message))
+	}
+}
+
+// setHealthy mark device healthy unless it is quarantined or powered off
+func (odp *OrinDevicePlugin) setHealthy(id string) {
+	odp.quarantineLock.Lock()
+	defer odp.quarantineLock.Unlock()
+	if !odp.quarantined.Has(id) && !odp.boardPoweredOff(id) {
+		odp.Health.SetHealth(id, v1beta1.Healthy)
+	}
+}
+
+// agentHealthy return false if agent of device reported it unhealthy
+func (odp *OrinDevicePlugin) agentHealthy(id string) bool {
+	odp.agentLock.Lock()
+	defer odp.agentLock.Unlock()
+	a, ok := odp.agents[id]
+	return !ok || a.healthy
+}
+
+// orinAttrs return provider attrs of soc with identity reported by its agent
+func (odp *OrinDevicePlugin) orinAttrs(boardID, orinID int) map[string]interface{} {
+	attrs := odp.DeviceProvider.GetOrinAttrs(boardID, orinID)
+	odp.agentLock.Lock()
+	defer odp.agentLock.Unlock()
+	a, ok := odp.agents[types.OrinDeviceID(boardID, orinID)]
+	if !ok || a.identity == nil || len(attrs) == 0 {
+		return attrs
+	}
+	attrs[provider.AttrKeyOrinBoardSerial] = a.identity.BoardSerial
+	attrs[provider.AttrKeyOrinHostname] = a.identity.Hostname
+	if a.identity.L4TVersion != "" {
+		attrs[provider.AttrKeyOrinL4TVersion] = a.identity.L4TVersion
+	}
+	if a.identity.JetPackVersion != "" {
+		attrs[provider.AttrKeyOrinJetPackVersion] = a.identity.JetPackVersion
+	}
+	return attrs
+}
+
+func (odp *OrinDevicePlugin) closeAgents() {
+	odp.agentLock.Lock()
+	defer odp.agentLock.Unlock()
+	for id, a := range odp.agents {
+		a.conn.Close()
+		delete(odp.agents, id)
+	}
+}
@@ -0,0 +1,64 @@
+package plugin
+
+import (
+	"net"
+	"testing"
+
+	"github.com/superedge/orin-device-system/pkg/agent/api"
+	"github.com/superedge/orin-device-system/pkg/agent/server"
+	"github.com/superedge/orin-device-system/pkg/device/provider"
+
+	"google.golang.org/grpc"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+func TestPollAgents(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := grpc.NewServer()
+	api.RegisterAgentServer(s, server.New(&server.Config{BoardSerial: "serial-1", SocIndex: 1}))
+	go s.Serve(listener)
+	defer s.Stop()
+
+	// nothing listens on 127.0.0.2, agent of soc 2 is unreachable
+	p := &provider.FileDeviceProvider{FileDevice: &provider.OrinFileDevice{BoardDevices: []*provider.Device{
+		{ID: 1, OrinSocs: []*provider.OrinSoc{{ID: 1, IP: "127.0.0.1"}, {ID: 2, IP: "127.0.0.2"}, {ID: 3}}},
+	}}}
+	odp := &OrinDevicePlugin{
+		OrinDeviceConfig: &OrinDeviceConfig{
+			DeviceProvider: p,
+			Sitter:         &fakeSitter{node: &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}},
+			Recorder:       record.NewFakeRecorder(10),
+			NodeName:       "node1",
+			AgentPort:      listener.Addr().(*net.TCPAddr).Port,
+		},
+		Health:      NewHealthView("1-1", "1-2", "1-3"),
+		quarantined: sets.NewString(),
+		agents:      make(map[string]*socAgent),
+	}
+	defer odp.closeAgents()
+	odp.pollAgents()
+
+	if odp.Health.Health("1-1") != v1beta1.Healthy || odp.Health.Health("1-2") != v1beta1.Unhealthy || odp.Health.Health("1-3") != v1beta1.Healthy {
+		t.Fatalf("unexpected health %v", odp.Health.Unhealthy())
+	}
+	if attrs := odp.orinAttrs(1, 1); attrs[provider.AttrKeyOrinBoardSerial] != "serial-1" || attrs[provider.AttrKeyOrinIp] != "127.0.0.1" {
+		t.Fatalf("expect identity in attrs, actual %v", attrs)
+	}
+	if _, ok := odp.orinAttrs(1, 2)[provider.AttrKeyOrinBoardSerial]; ok {
+		t.Fatalf("expect no identity of unreachable agent")
+	}
+
+	// unreachable agent keeps soc unhealthy after quarantine cleared
+	odp.quarantined.Insert("1-2")
+	odp.syncQuarantine()
+	if odp.Health.Health("1-2") != v1beta1.Unhealthy {
+		t.Fatalf("expect 1-2 unhealthy")
+	}
+}
@@ -0,0 +1,65 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/superedge/orin-device-system/pkg/device/kubeapis"
+	"github.com/superedge/orin-device-system/pkg/device/metrics"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+const ReasonAllocatableMismatch = "AllocatableMismatch"
+
+// checkAllocatable compare devices kubelet consider allocatable with devices
+// advertised by ListAndWatch, a mismatch means kubelet lost some devices or
+// still keeps devices removed from provider
+func (odp *OrinDevicePlugin) checkAllocatable() {
+	lister, ok := odp.DeviceLocator.(kubeapis.AllocatableLister)
+	if !ok {
+		return
+	}
+	allocatable, err := lister.Allocatable()
+	if err == kubeapis.ErrAllocatableUnsupported {
+		klog.V(5).InfoS("skip allocatable check", "reason", err)
+		return
+	}
+	if err != nil {
+		klog.ErrorS(err, "get kubelet allocatable devices error")
+		return
+	}
+	states := odp.States()
+	for name, r := range odp.resources {
+		// kubelet does not know resource before registration
+		if states[string(name)] != ServerStateRegistered {
+			continue
+		}
+		missing, unknown := diffDeviceIDs(r.DeviceIDs, allocatable[name])
+		metrics.AllocatableMismatch.WithLabelValues(string(name)).Set(float64(len(missing) + len(unknown)))
+		diff := ""
+		if len(missing) != 0 || len(unknown) != 0 {
+			diff = fmt.Sprintf("devices %s advertised but not allocatable in kubelet, devices %s allocatable in kubelet but not advertised",
+				strings.Join(missing, ","), strings.Join(unknown, ","))
+		}
+		// only report when mismatch changed, check runs periodically
+		if diff == odp.allocatableDiff[name] {
+			continue
+		}
+		odp.allocatableDiff[name] = diff
+		if diff == "" {
+			klog.InfoS("kubelet allocatable devices match advertised", "resource", name)
+			continue
+		}
+		klog.InfoS("kubelet allocatable devices mismatch", "resource", name, "missing", missing, "unknown", unknown)
+		odp.event(odp.nodeRef(), v1.EventTypeWarning, ReasonAllocatableMismatch, fmt.Sprintf("resource %s: %s", name, diff))
+	}
+}
+
+// diffDeviceIDs return sorted ids only in advertised and only in allocatable
+func diffDeviceIDs(advertised, allocatable []string) ([]string, []string) {
+	a, b := sets.NewString(advertised...), sets.NewString(allocatable...)
+	return a.Difference(b).List(), b.Difference(a).List()
+}
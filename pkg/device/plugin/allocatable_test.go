@@ -0,0 +1,58 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/superedge/orin-device-system/pkg/device/types"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+type allocatableLocator struct {
+	allocatable map[v1.ResourceName][]string
+}
+
+func (l *allocatableLocator) Locate(*types.Device) (*types.PodContainer, error) { return nil, nil }
+func (l *allocatableLocator) List() ([]*types.PodInfo, error)                   { return nil, nil }
+func (l *allocatableLocator) Close() error                                      { return nil }
+func (l *allocatableLocator) Allocatable() (map[v1.ResourceName][]string, error) {
+	return l.allocatable, nil
+}
+
+func TestCheckAllocatable(t *testing.T) {
+	const name = "superedge.io/device-orin-1"
+	locator := &allocatableLocator{allocatable: map[v1.ResourceName][]string{name: {"1-1", "3-1"}}}
+	recorder := record.NewFakeRecorder(10)
+	server := &DevicePluginServer{ResourceName: name}
+	server.setState(ServerStateRegistered, nil)
+	odp := &OrinDevicePlugin{
+		OrinDeviceConfig: &OrinDeviceConfig{DeviceLocator: locator, Recorder: recorder, NodeName: "node1"},
+		resources:        map[v1.ResourceName]*orinResource{name: {OrinID: 1, ResourceName: name, DeviceIDs: []string{"1-1", "2-1"}}},
+		endpoints:        []*DevicePluginServer{server},
+		allocatableDiff:  make(map[v1.ResourceName]string),
+	}
+
+	odp.checkAllocatable()
+	if len(recorder.Events) != 1 {
+		t.Fatalf("expect one mismatch event, got %d", len(recorder.Events))
+	}
+	<-recorder.Events
+	// the same mismatch is reported once
+	odp.checkAllocatable()
+	if len(recorder.Events) != 0 {
+		t.Fatalf("expect no event for unchanged mismatch, got %d", len(recorder.Events))
+	}
+	locator.allocatable[name] = []string{"2-1", "1-1"}
+	odp.checkAllocatable()
+	if len(recorder.Events) != 0 || odp.allocatableDiff[name] != "" {
+		t.Fatalf("expect mismatch resolved without event")
+	}
+}
+
+func TestDiffDeviceIDs(t *testing.T) {
+	missing, unknown := diffDeviceIDs([]string{"1-1", "2-1"}, []string{"3-1", "1-1"})
+	if len(missing) != 1 || missing[0] != "2-1" || len(unknown) != 1 || unknown[0] != "3-1" {
+		t.Errorf("diffDeviceIDs() = %v, %v", missing, unknown)
+	}
+}
@@ -0,0 +1,195 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/metrics"
+	"github.com/superedge/orin-device-system/pkg/device/provider"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+	"github.com/superedge/orin-device-system/pkg/scheduler/manager"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+const DefaultAuditPeriod = 5 * time.Minute
+
+// kinds of audit finding, they are also event reasons
+const (
+	AuditBoardMismatch    = "BoardMismatch"
+	AuditDoubleAllocation = "DoubleAllocation"
+	AuditOrphanDevice     = "OrphanDevice"
+	AuditUnknownDevice    = "UnknownDevice"
+)
+
+var auditKinds = []string{AuditBoardMismatch, AuditDoubleAllocation, AuditOrphanDevice, AuditUnknownDevice}
+
+// auditFinding is an inconsistency of one device between kubelet allocation,
+// scheduler binding and provider inventory
+type auditFinding struct {
+	Kind   string
+	Device string
+	// Pods is namespace/name of pods holding the device
+	Pods    []string
+	Message string
+}
+
+func (f *auditFinding) key() string {
+	return fmt.Sprintf("%s:%s:%s", f.Kind, f.Device, strings.Join(f.Pods, ","))
+}
+
+// AuditSummary is written to node annotation superedge.io/orin-audit
+type AuditSummary struct {
+	Time     metav1.Time    `json:"time"`
+	Findings map[string]int `json:"findings"`
+}
+
+// auditAllocations compare every device allocated by kubelet with the bind
+// annotation of its pod and the provider inventory
+func auditAllocations(pods []*types.PodInfo, getPod func(namespace, name string) (*v1.Pod, error), p provider.DeviceProvider) []*auditFinding {
+	findings := []*auditFinding{}
+	holders := map[string]sets.String{}
+	for _, pi := range pods {
+		key := string(pi.Key())
+		pod, err := getPod(pi.Namespace, pi.Name)
+		if err != nil && !errors.IsNotFound(err) {
+			klog.ErrorS(err, "get pod for audit error", "pod", key)
+			continue
+		}
+		for _, id := range pi.DeviceIDs() {
+			if holders[id] == nil {
+				holders[id] = sets.NewString()
+			}
+			holders[id].Insert(key)
+			boardID, orinID, perr := types.ParseOrinDeviceID(id)
+			if perr != nil {
+				continue
+			}
+			if !sets.NewInt(p.GetBoardOrins(boardID)...).Has(orinID) {
+				findings = append(findings, &auditFinding{Kind: AuditUnknownDevice, Device: id, Pods: []string{key},
+					Message: fmt.Sprintf("device %s held by pod %s is not in provider %s inventory", id, key, p.Name())})
+			}
+			if pod == nil {
+				findings = append(findings, &auditFinding{Kind: AuditOrphanDevice, Device: id, Pods: []string{key},
+					Message: fmt.Sprintf("device %s is held by pod %s which no longer exists", id, key)})
+				continue
+			}
+			if _, ok := manager.PodBoardOrins(pod)[boardID]; !ok {
+				bound := pod.Annotations[common.AnnotationPodBindToBoard]
+				if boards, ok := pod.Annotations[common.AnnotationPodBindBoards]; ok {
+					bound = boards
+				}
+				findings = append(findings, &auditFinding{Kind: AuditBoardMismatch, Device: id, Pods: []string{key},
+					Message: fmt.Sprintf("device %s of pod %s is on board %d, but pod is bound to board %q", id, key, boardID, bound)})
+			}
+		}
+	}
+	for id, h := range holders {
+		// containers of the same pod may share devices, e.g. init containers
+		if h.Len() > 1 {
+			findings = append(findings, &auditFinding{Kind: AuditDoubleAllocation, Device: id, Pods: h.List(),
+				Message: fmt.Sprintf("device %s is allocated to pods %s", id, strings.Join(h.List(), ","))})
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool { return findings[i].key() < findings[j].key() })
+	return findings
+}
+
+// audit run auditAllocations, and report new findings as events, every finding
+// as metrics and node annotation
+func (odp *OrinDevicePlugin) audit() {
+	if !odp.Sitter.HasSynced() {
+		return
+	}
+	pods, err := odp.DeviceLocator.List()
+	if err != nil {
+		klog.ErrorS(err, "list pod devices for audit error")
+		return
+	}
+	findings := auditAllocations(pods, odp.Sitter.GetPod, odp.DeviceProvider)
+
+	summary := &AuditSummary{Time: metav1.Now(), Findings: map[string]int{}}
+	for _, kind := range auditKinds {
+		summary.Findings[kind] = 0
+	}
+	reported := sets.NewString()
+	for _, f := range findings {
+		summary.Findings[f.Kind]++
+		reported.Insert(f.key())
+		if odp.auditReported.Has(f.key()) {
+			continue
+		}
+		klog.InfoS("audit finding", "kind", f.Kind, "device", f.Device, "pods", f.Pods, "message", f.Message)
+		if f.Kind == AuditBoardMismatch {
+			if pod, err := odp.Sitter.GetPod(podNamespaceName(f.Pods[0])); err == nil {
+				odp.event(pod, v1.EventTypeWarning, f.Kind, f.Message)
+				continue
+			}
+		}
+		odp.event(odp.nodeRef(), v1.EventTypeWarning, f.Kind, f.Message)
+	}
+	odp.auditReported = reported
+	for kind, n := range summary.Findings {
+		metrics.AuditFindings.WithLabelValues(kind).Set(float64(n))
+	}
+	if err := odp.patchAuditSummary(summary); err != nil {
+		klog.ErrorS(err, "patch node audit summary error")
+	}
+}
+
+func podNamespaceName(key string) (string, string) {
+	arr := strings.SplitN(key, "/", 2)
+	if len(arr) != 2 {
+		return "", key
+	}
+	return arr[0], arr[1]
+}
+
+// patchAuditSummary write summary to node annotation if findings changed
+func (odp *OrinDevicePlugin) patchAuditSummary(summary *AuditSummary) error {
+	if odp.ClientSet == nil {
+		return nil
+	}
+	if odp.auditSummary != nil && equalFindings(odp.auditSummary.Findings, summary.Findings) {
+		return nil
+	}
+	val, err := json.Marshal(summary)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{common.AnnotationNodeOrinAudit: string(val)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := odp.ClientSet.CoreV1().Nodes().Patch(context.TODO(), odp.NodeName, k8stypes.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return err
+	}
+	odp.auditSummary = summary
+	return nil
+}
+
+func equalFindings(a, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,81 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/provider"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAuditAllocations(t *testing.T) {
+	p := &provider.FileDeviceProvider{FileDevice: &provider.OrinFileDevice{BoardDevices: []*provider.Device{
+		{ID: 1, OrinSocs: []*provider.OrinSoc{{ID: 1}, {ID: 2}}},
+		{ID: 2, OrinSocs: []*provider.OrinSoc{{ID: 1}, {ID: 2}}},
+	}}}
+	boundPod := func(name, board string) *v1.Pod {
+		return &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Annotations: map[string]string{common.AnnotationPodBindToBoard: board}}}
+	}
+	podInfo := func(name string, ids ...string) *types.PodInfo {
+		pi := types.NewPI("default", name)
+		for _, id := range ids {
+			_, orinID, _ := types.ParseOrinDeviceID(id)
+			pi.AddDevice("c"+id, types.NewDevice([]string{id}, v1.ResourceName(common.ExtendResouceTypeOrinPrefix+string(rune('0'+orinID)))))
+		}
+		return pi
+	}
+
+	tests := []struct {
+		name   string
+		pods   []*types.PodInfo
+		exists map[string]*v1.Pod
+		expect []string
+	}{
+		{
+			name:   "consistent",
+			pods:   []*types.PodInfo{podInfo("p1", "1-1", "1-2"), podInfo("p2", "2-1")},
+			exists: map[string]*v1.Pod{"p1": boundPod("p1", "1"), "p2": boundPod("p2", "2")},
+			expect: []string{},
+		},
+		{
+			name:   "board mismatch",
+			pods:   []*types.PodInfo{podInfo("p1", "2-1")},
+			exists: map[string]*v1.Pod{"p1": boundPod("p1", "1")},
+			expect: []string{"BoardMismatch:2-1:default/p1"},
+		},
+		{
+			name:   "double allocation",
+			pods:   []*types.PodInfo{podInfo("p1", "1-1"), podInfo("p2", "1-1")},
+			exists: map[string]*v1.Pod{"p1": boundPod("p1", "1"), "p2": boundPod("p2", "1")},
+			expect: []string{"DoubleAllocation:1-1:default/p1,default/p2"},
+		},
+		{
+			name:   "orphan and unknown device",
+			pods:   []*types.PodInfo{podInfo("gone", "1-1"), podInfo("p1", "3-1")},
+			exists: map[string]*v1.Pod{"p1": boundPod("p1", "3")},
+			expect: []string{"OrphanDevice:1-1:default/gone", "UnknownDevice:3-1:default/p1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getPod := func(namespace, name string) (*v1.Pod, error) {
+				if pod, ok := tt.exists[name]; ok {
+					return pod, nil
+				}
+				return nil, errors.NewNotFound(v1.Resource("pods"), name)
+			}
+			keys := []string{}
+			for _, f := range auditAllocations(tt.pods, getPod, p) {
+				keys = append(keys, f.key())
+			}
+			if !reflect.DeepEqual(keys, tt.expect) {
+				t.Errorf("auditAllocations() = %v, expect %v", keys, tt.expect)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package plugin
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/checkpoint"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// allocationTTL is how long an allocation without PreStartContainer is kept
+	allocationTTL = time.Hour
+
+	DefaultCheckpointPeriod = 5 * time.Minute
+)
+
+// addAllocationCheckpoint record devices kubelet allocated
+func (odp *OrinDevicePlugin) addAllocationCheckpoint(device *types.Device) {
+	if odp.Checkpoint == nil {
+		return
+	}
+	if err := odp.Checkpoint.AddAllocation(device); err != nil {
+		klog.ErrorS(err, "checkpoint allocation error", "devices", device.List)
+	}
+}
+
+// addPodCheckpoint record devices injected into pod container
+func (odp *OrinDevicePlugin) addPodCheckpoint(pod *v1.Pod, pc *types.PodContainer, device *types.Device) {
+	if odp.Checkpoint == nil {
+		return
+	}
+	if err := odp.Checkpoint.AddPodDevice(string(pod.UID), pc.Namespace, pc.Name, pc.Container, device); err != nil {
+		klog.ErrorS(err, "checkpoint pod device error", "pod", pc, "devices", device.List)
+	}
+}
+
+// deletePodCheckpoint is pod informer delete handler
+func (odp *OrinDevicePlugin) deletePodCheckpoint(obj interface{}) {
+	var pod *v1.Pod
+	switch t := obj.(type) {
+	case *v1.Pod:
+		pod = t
+	case cache.DeletedFinalStateUnknown:
+		var ok bool
+		if pod, ok = t.Obj.(*v1.Pod); !ok {
+			return
+		}
+	default:
+		return
+	}
+	if err := odp.Checkpoint.DeletePod(string(pod.UID)); err != nil {
+		klog.ErrorS(err, "delete pod from checkpoint error", "pod", klog.KObj(pod))
+	}
+}
+
+// pruneCheckpoint remove pods which no longer exist and stale allocations,
+// delete event may be lost when plugin is down
+func (odp *OrinDevicePlugin) pruneCheckpoint() {
+	if !odp.Sitter.HasSynced() {
+		return
+	}
+	for _, pi := range odp.Checkpoint.Pods() {
+		pod, err := odp.Sitter.GetPod(pi.Namespace, pi.Name)
+		// pod recreated with the same name, e.g. of statefulset, is another pod
+		gone := errors.IsNotFound(err) || (err == nil && pi.UID != "" && string(pod.UID) != pi.UID)
+		if gone {
+			klog.V(4).InfoS("prune pod from checkpoint", "pod", string(pi.Key()), "uid", pi.UID)
+			if err := odp.Checkpoint.DeletePod(checkpoint.RecordKey(pi)); err != nil {
+				klog.ErrorS(err, "delete pod from checkpoint error", "pod", string(pi.Key()))
+			}
+		}
+	}
+	if err := odp.Checkpoint.PruneAllocations(time.Now().Add(-allocationTTL)); err != nil {
+		klog.ErrorS(err, "prune checkpoint allocations error")
+	}
+}
+
+// recoverInjectedConfigs populate orin attr again for pods in checkpoint,
+// whose injected config is lost
+func (odp *OrinDevicePlugin) recoverInjectedConfigs() {
+	for _, pi := range odp.Checkpoint.Pods() {
+		for _, devices := range pi.ContainerDeviceMap {
+			for _, d := range devices {
+				if len(d.List) == 0 {
+					continue
+				}
+				vpath := path.Join(HostVitualPath, string(d.ResourceName), d.List[0])
+				if _, err := os.Stat(path.Join(vpath, "config.json")); err == nil {
+					continue
+				}
+				var config interface{}
+				if d.ResourceName == common.ExtendResouceTypeOrin {
+					socs := odp.socsAttrs(d.List)
+					if len(socs) == 0 {
+						continue
+					}
+					config = socs
+				} else {
+					boardID, orinID, err := types.ParseOrinDeviceID(d.List[0])
+					if err != nil {
+						klog.ErrorS(err, "invalid device in checkpoint", "pod", string(pi.Key()))
+						continue
+					}
+					attrs := odp.orinAttrs(boardID, orinID)
+					if len(attrs) == 0 {
+						continue
+					}
+					config = attrs
+				}
+				if err := populateOrinAttr(vpath, config); err != nil {
+					klog.ErrorS(err, "recover injected config error", "pod", string(pi.Key()), "vitual path", vpath)
+					continue
+				}
+				klog.InfoS("recover injected config", "pod", string(pi.Key()), "vitual path", vpath)
+			}
+		}
+	}
+}
+
+type allocationsView struct {
+	Pods        []*types.PodInfo         `json:"pods"`
+	Allocations []*checkpoint.Allocation `json:"allocations"`
+}
+
+// ServeAllocations is the debug handler dump checkpoint
+func (odp *OrinDevicePlugin) ServeAllocations(w http.ResponseWriter, r *http.Request) {
+	view := allocationsView{Pods: []*types.PodInfo{}, Allocations: []*checkpoint.Allocation{}}
+	if odp.Checkpoint != nil {
+		view.Pods = odp.Checkpoint.Pods()
+		view.Allocations = odp.Checkpoint.Allocations()
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(view); err != nil {
+		klog.ErrorS(err, "encode allocations error")
+	}
+}
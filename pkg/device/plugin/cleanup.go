@@ -0,0 +1,147 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+type jsonPatchOp struct {
+	Op   string `json:"op"`
+	Path string `json:"path"`
+}
+
+// Cleanup removes everything the orin device plugin left on the node: the
+// superedge.io/device-* extended resources in node status, the plugin sockets
+// and, if removeConfig, the injected orin config tree and the checkpoint at
+// checkpointPath. The config tree is bind mounted into running pods, so it is
+// only removed when the node is retired, not when plugin exits for an update.
+func Cleanup(clientset *kubernetes.Clientset, nodeName string, removeConfig bool, checkpointPath string) error {
+	var errs []string
+	if err := removeNodeExtraResource(clientset, nodeName); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if err := removePluginSockets(v1beta1.DevicePluginPath); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if removeConfig {
+		if err := removeInjectedConfig(HostVitualPath); err != nil {
+			errs = append(errs, err.Error())
+		}
+		// a reused node must not start with records of pods long gone
+		if err := removeCheckpoint(checkpointPath); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("cleanup node %s error: %s", nodeName, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+func removeNodeExtraResource(clientset *kubernetes.Clientset, nodeName string) error {
+	node, err := clientset.CoreV1().Nodes().Get(context.TODO(), nodeName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	ops := buildRemoveResourcePatch(node)
+	if len(ops) == 0 {
+		klog.InfoS("no orin extra resource found on node", "node", nodeName)
+		return nil
+	}
+	data, err := json.Marshal(ops)
+	if err != nil {
+		return err
+	}
+	if _, err := clientset.CoreV1().Nodes().Patch(context.TODO(), nodeName, k8stypes.JSONPatchType, data, metav1.PatchOptions{}, "status"); err != nil {
+		klog.ErrorS(err, "remove node extra resource error", "node", nodeName)
+		return err
+	}
+	klog.InfoS("remove node extra resource", "node", nodeName, "patch", string(data))
+	return nil
+}
+
+// buildRemoveResourcePatch builds a json patch which remove every orin extra
+// resource from node capacity and allocatable
+func buildRemoveResourcePatch(node *v1.Node) []jsonPatchOp {
+	ops := []jsonPatchOp{}
+	for _, status := range []struct {
+		field string
+		list  v1.ResourceList
+	}{
+		{field: "capacity", list: node.Status.Capacity},
+		{field: "allocatable", list: node.Status.Allocatable},
+	} {
+		for rkey := range status.list {
+			if !strings.HasPrefix(string(rkey), common.ExtendResouceTypePrefix) {
+				continue
+			}
+			// json pointer escaping, see rfc6901
+			escaped := strings.ReplaceAll(strings.ReplaceAll(string(rkey), "~", "~0"), "/", "~1")
+			ops = append(ops, jsonPatchOp{Op: "remove", Path: fmt.Sprintf("/status/%s/%s", status.field, escaped)})
+		}
+	}
+	return ops
+}
+
+func removePluginSockets(pluginPath string) error {
+	prefix := strings.ReplaceAll(common.ExtendResouceTypePrefix, "/", "-")
+	sockets, err := filepath.Glob(path.Join(pluginPath, prefix+"*.sock"))
+	if err != nil {
+		return err
+	}
+	for _, s := range sockets {
+		if err := os.Remove(s); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		klog.InfoS("remove plugin socket", "socket", s)
+	}
+	return nil
+}
+
+// removeInjectedConfig remove the content of vitual path, but keep the
+// directory itself which may be a host mount point
+func removeInjectedConfig(vpath string) error {
+	entries, err := os.ReadDir(vpath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, e := range entries {
+		if err := os.RemoveAll(path.Join(vpath, e.Name())); err != nil {
+			return err
+		}
+	}
+	klog.InfoS("remove injected orin config", "path", vpath)
+	return nil
+}
+
+// removeCheckpoint remove the checkpoint file and the copy kept when it was
+// found corrupted
+func removeCheckpoint(checkpointPath string) error {
+	if checkpointPath == "" {
+		return nil
+	}
+	for _, p := range []string{checkpointPath, checkpointPath + ".corrupted"} {
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	klog.InfoS("remove allocation checkpoint", "path", checkpointPath)
+	return nil
+}
@@ -0,0 +1,82 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestBuildRemoveResourcePatch(t *testing.T) {
+	q, _ := resource.ParseQuantity("1111")
+
+	testcases := []struct {
+		name     string
+		status   v1.NodeStatus
+		expected []string
+	}{
+		{
+			name:     "1.no orin resource",
+			status:   v1.NodeStatus{Capacity: v1.ResourceList{v1.ResourceCPU: q}},
+			expected: []string{},
+		},
+		{
+			name: "2.board and orin resource",
+			status: v1.NodeStatus{
+				Capacity: v1.ResourceList{
+					v1.ResourceCPU: q,
+					v1.ResourceName(common.ExtendResouceTypeBoard):             q,
+					v1.ResourceName(common.ExtendResouceTypeBoardPrefix + "0"): q,
+				},
+				Allocatable: v1.ResourceList{
+					v1.ResourceName(common.ExtendResouceTypeOrinPrefix + "1"): q,
+				},
+			},
+			expected: []string{
+				"/status/allocatable/superedge.io~1device-orin-1",
+				"/status/capacity/superedge.io~1device-board",
+				"/status/capacity/superedge.io~1device-board-0",
+			},
+		},
+	}
+	for _, tc := range testcases {
+		ops := buildRemoveResourcePatch(&v1.Node{Status: tc.status})
+		actual := []string{}
+		for _, op := range ops {
+			if op.Op != "remove" {
+				t.Errorf("test case %s, unexpected op %s", tc.name, op.Op)
+			}
+			actual = append(actual, op.Path)
+		}
+		sort.Strings(actual)
+		if !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("test case %s, is not same, expect %v, actual %v", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func TestRemoveCheckpoint(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "checkpoint")
+	for _, f := range []string{p, p + ".corrupted"} {
+		if err := os.WriteFile(f, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := removeCheckpoint(p); err != nil {
+		t.Fatalf("removeCheckpoint() error = %v", err)
+	}
+	for _, f := range []string{p, p + ".corrupted"} {
+		if _, err := os.Stat(f); !os.IsNotExist(err) {
+			t.Errorf("expect %s removed, got %v", f, err)
+		}
+	}
+	// removing again is not an error
+	if err := removeCheckpoint(p); err != nil {
+		t.Errorf("removeCheckpoint() of removed checkpoint error = %v", err)
+	}
+}
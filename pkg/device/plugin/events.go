@@ -0,0 +1,154 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/scheduler/manager"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+const (
+	ReasonOrinConfigured = "OrinConfigured"
+
+	// injectStateTTL is how long a partial injected pod is tracked
+	injectStateTTL = time.Hour
+)
+
+// report record event on pod, or on node if pod is unknown, and update the
+// OrinConfigured condition of pod according to the PreStartContainer result
+func (odp *OrinDevicePlugin) report(r *orinResource, pod *v1.Pod, devicesIDs []string, reason string, err error) {
+	devices := strings.Join(devicesIDs, ",")
+	if pod == nil {
+		if err != nil {
+			odp.event(odp.nodeRef(), v1.EventTypeWarning, reason, fmt.Sprintf("PreStartContainer of %s devices %s failed: %v", r.ResourceName, devices, err))
+		}
+		return
+	}
+
+	switch {
+	case err != nil:
+		message := fmt.Sprintf("PreStartContainer of %s devices %s failed: %v", r.ResourceName, devices, err)
+		odp.event(pod, v1.EventTypeWarning, reason, message)
+		odp.setConfiguredCondition(pod, v1.ConditionFalse, reason, message)
+	case reason == ReasonEmptyAttrs:
+		message := fmt.Sprintf("provider %s has no attribute of %s devices %s", odp.DeviceProvider.Name(), r.ResourceName, devices)
+		odp.event(pod, v1.EventTypeWarning, reason, message)
+		odp.setConfiguredCondition(pod, v1.ConditionFalse, reason, message)
+	case reason == "":
+		// preStart has checked the devices
+		socDevices, _ := odp.injectedDevices(r, pod, devicesIDs)
+		if odp.injected.Inject(pod, socDevices...) {
+			message := fmt.Sprintf("%s configured", manager.DescribeBoardOrins(manager.PodBoardOrins(pod)))
+			odp.event(pod, v1.EventTypeNormal, ReasonOrinConfigured, message)
+			odp.setConfiguredCondition(pod, v1.ConditionTrue, ReasonOrinConfigured, message)
+		}
+	}
+}
+
+func (odp *OrinDevicePlugin) nodeRef() *v1.ObjectReference {
+	return &v1.ObjectReference{
+		Kind: "Node",
+		Name: odp.NodeName,
+		// kubelet use node name as uid of node event
+		UID: k8stypes.UID(odp.NodeName),
+	}
+}
+
+func (odp *OrinDevicePlugin) event(object runtime.Object, eventtype, reason, message string) {
+	if odp.Recorder == nil {
+		return
+	}
+	odp.Recorder.Event(object, eventtype, reason, message)
+}
+
+// setConfiguredCondition patch OrinConfigured condition to pod status, it runs
+// asynchronously to avoid blocking container start
+func (odp *OrinDevicePlugin) setConfiguredCondition(pod *v1.Pod, status v1.ConditionStatus, reason, message string) {
+	if odp.ClientSet == nil {
+		return
+	}
+	condition := v1.PodCondition{
+		Type:               common.PodConditionOrinConfigured,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.Now(),
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == condition.Type && c.Status == condition.Status && c.Reason == condition.Reason {
+			return
+		}
+	}
+	go func() {
+		data, err := buildConditionPatch(condition)
+		if err != nil {
+			klog.ErrorS(err, "build pod condition patch error", "pod", klog.KObj(pod))
+			return
+		}
+		if _, err := odp.ClientSet.CoreV1().Pods(pod.Namespace).Patch(context.TODO(), pod.Name, k8stypes.StrategicMergePatchType, data, metav1.PatchOptions{}, "status"); err != nil {
+			klog.ErrorS(err, "patch pod condition error", "pod", klog.KObj(pod), "condition", condition.Type)
+		}
+	}()
+}
+
+// buildConditionPatch build a strategic merge patch, conditions merge by type
+func buildConditionPatch(condition v1.PodCondition) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"conditions": []v1.PodCondition{condition},
+		},
+	})
+}
+
+type injectState struct {
+	injected sets.String
+	updated  time.Time
+}
+
+// injectTracker track which device of a pod has been injected, PreStartContainer
+// is called once per resource, pod is configured after all of them injected
+type injectTracker struct {
+	lock sync.Mutex
+	pods map[k8stypes.UID]*injectState
+}
+
+func newInjectTracker() *injectTracker {
+	return &injectTracker{pods: make(map[k8stypes.UID]*injectState)}
+}
+
+// Inject mark devices of pod injected, and return true if every device of
+// pod on every bound board has been injected
+func (t *injectTracker) Inject(pod *v1.Pod, devicesIDs ...string) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := time.Now()
+	for uid, state := range t.pods {
+		if now.Sub(state.updated) > injectStateTTL {
+			delete(t.pods, uid)
+		}
+	}
+	state, ok := t.pods[pod.UID]
+	if !ok {
+		state = &injectState{injected: sets.NewString()}
+		t.pods[pod.UID] = state
+	}
+	state.injected.Insert(devicesIDs...)
+	state.updated = now
+	if state.injected.HasAll(podDevices(manager.PodBoardOrins(pod))...) {
+		delete(t.pods, pod.UID)
+		return true
+	}
+	return false
+}
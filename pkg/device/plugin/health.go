@@ -0,0 +1,94 @@
+package plugin
+
+import (
+	"sort"
+	"sync"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// HealthView is the health of every orin device shared by all resources,
+// ListAndWatch subscribe it and send devices again when health changed
+type HealthView struct {
+	lock        sync.RWMutex
+	health      map[string]string
+	subscribers map[int]chan struct{}
+	nextID      int
+}
+
+func NewHealthView(deviceIDs ...string) *HealthView {
+	hv := &HealthView{
+		health:      make(map[string]string, len(deviceIDs)),
+		subscribers: make(map[int]chan struct{}),
+	}
+	for _, id := range deviceIDs {
+		hv.health[id] = v1beta1.Healthy
+	}
+	return hv
+}
+
+// SetHealth update device health, and notify subscribers if it changed
+func (hv *HealthView) SetHealth(deviceID, health string) bool {
+	hv.lock.Lock()
+	defer hv.lock.Unlock()
+	if old, ok := hv.health[deviceID]; !ok || old == health {
+		return false
+	}
+	hv.health[deviceID] = health
+	for _, ch := range hv.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+	return true
+}
+
+func (hv *HealthView) Health(deviceID string) string {
+	hv.lock.RLock()
+	defer hv.lock.RUnlock()
+	return hv.health[deviceID]
+}
+
+// Devices return device with health in the order of deviceIDs
+func (hv *HealthView) Devices(deviceIDs []string) []*v1beta1.Device {
+	hv.lock.RLock()
+	defer hv.lock.RUnlock()
+	devices := make([]*v1beta1.Device, 0, len(deviceIDs))
+	for _, id := range deviceIDs {
+		if health, ok := hv.health[id]; ok {
+			devices = append(devices, &v1beta1.Device{ID: id, Health: health})
+		}
+	}
+	return devices
+}
+
+// Unhealthy return all unhealthy device ids
+func (hv *HealthView) Unhealthy() []string {
+	hv.lock.RLock()
+	defer hv.lock.RUnlock()
+	ids := []string{}
+	for id, health := range hv.health {
+		if health != v1beta1.Healthy {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Subscribe return a channel notified when any device health changed, call
+// cancel func to unsubscribe
+func (hv *HealthView) Subscribe() (<-chan struct{}, func()) {
+	hv.lock.Lock()
+	defer hv.lock.Unlock()
+	id := hv.nextID
+	hv.nextID++
+	ch := make(chan struct{}, 1)
+	hv.subscribers[id] = ch
+	return ch, func() {
+		hv.lock.Lock()
+		defer hv.lock.Unlock()
+		delete(hv.subscribers, id)
+	}
+}
@@ -0,0 +1,37 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+func TestHealthView(t *testing.T) {
+	hv := NewHealthView("0-1", "1-1", "0-2")
+	changed, cancel := hv.Subscribe()
+	defer cancel()
+
+	if hv.SetHealth("0-1", v1beta1.Healthy) {
+		t.Fatalf("set same health should not change")
+	}
+	if hv.SetHealth("9-9", v1beta1.Unhealthy) {
+		t.Fatalf("set unknown device health should not change")
+	}
+	if !hv.SetHealth("1-1", v1beta1.Unhealthy) {
+		t.Fatalf("set health should change")
+	}
+	select {
+	case <-changed:
+	default:
+		t.Fatalf("subscriber is not notified")
+	}
+
+	expected := []*v1beta1.Device{{ID: "0-1", Health: v1beta1.Healthy}, {ID: "1-1", Health: v1beta1.Unhealthy}}
+	if actual := hv.Devices([]string{"0-1", "1-1"}); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("devices is not same, expect %v, actual %v", expected, actual)
+	}
+	if actual := hv.Unhealthy(); !reflect.DeepEqual(actual, []string{"1-1"}) {
+		t.Errorf("unhealthy devices is not same, expect %v, actual %v", []string{"1-1"}, actual)
+	}
+}
@@ -0,0 +1,300 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/hook"
+	"github.com/superedge/orin-device-system/pkg/device/metrics"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+	"k8s.io/klog/v2"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+const (
+	ReasonHookFailed  = "HookFailed"
+	ReasonQuarantined = "Quarantined"
+
+	DefaultQuarantinePeriod = 30 * time.Second
+)
+
+// hookData build template data of soc device for pod
+func (odp *OrinDevicePlugin) hookData(pod *v1.Pod, id string) (*hook.Data, error) {
+	boardID, socID, err := types.ParseOrinDeviceID(id)
+	if err != nil {
+		return nil, err
+	}
+	return &hook.Data{
+		Namespace: pod.Namespace,
+		Pod:       pod.Name,
+		Device:    id,
+		BoardID:   boardID,
+		SocID:     socID,
+		Board:     odp.DeviceProvider.GetBoardAttrs(boardID),
+		Soc:       odp.orinAttrs(boardID, socID),
+	}, nil
+}
+
+// runHooks run hooks of phase on every device in order, hooks of a device
+// stop at the first failure which is not ignored, errors of all devices are
+// returned
+func (odp *OrinDevicePlugin) runHooks(phase hook.Phase, pod *v1.Pod, deviceIDs []string) error {
+	hooks := odp.Hooks.Hooks(phase)
+	if len(hooks) == 0 {
+		return nil
+	}
+	errs := []error{}
+	for _, id := range deviceIDs {
+		data, err := odp.hookData(pod, id)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		for _, h := range hooks {
+			err := h.Run(context.Background(), data)
+			metrics.HookTotal.WithLabelValues(string(phase), h.Name, metrics.Result(err)).Inc()
+			if err == nil {
+				continue
+			}
+			message := fmt.Sprintf("%s hook %s of device %s failed: %v", phase, h.Name, id, err)
+			klog.ErrorS(err, "run hook error", "phase", phase, "hook", h.Name, "device", id, "pod", klog.KObj(pod))
+			odp.event(pod, v1.EventTypeWarning, ReasonHookFailed, message)
+			if h.FailurePolicy == hook.FailurePolicyIgnore {
+				continue
+			}
+			if h.FailurePolicy == hook.FailurePolicyQuarantine {
+				odp.quarantine(id, message)
+			}
+			errs = append(errs, fmt.Errorf("%s", message))
+			break
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// releaseTracker remember devices of pods which release hooks should run on
+type releaseTracker struct {
+	lock sync.Mutex
+	pods map[k8stypes.UID]sets.String
+}
+
+func newReleaseTracker() *releaseTracker {
+	return &releaseTracker{pods: make(map[k8stypes.UID]sets.String)}
+}
+
+func (t *releaseTracker) Add(uid k8stypes.UID, deviceIDs ...string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.pods[uid] == nil {
+		t.pods[uid] = sets.NewString()
+	}
+	t.pods[uid].Insert(deviceIDs...)
+}
+
+// Take return and forget devices of pod
+func (t *releaseTracker) Take(uid k8stypes.UID) []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	ids, ok := t.pods[uid]
+	if !ok {
+		return nil
+	}
+	delete(t.pods, uid)
+	return ids.List()
+}
+
+// recoverReleases track devices of pods started before plugin restart, so
+// their release hooks still run when they finish
+func (odp *OrinDevicePlugin) recoverReleases() {
+	if len(odp.Hooks.Hooks(hook.PhaseRelease)) == 0 {
+		return
+	}
+	infos, err := odp.DeviceLocator.List()
+	if err != nil {
+		klog.ErrorS(err, "list pod devices for release hooks error")
+		return
+	}
+	pods, err := odp.Sitter.ListPods()
+	if err != nil {
+		klog.ErrorS(err, "list pods for release hooks error")
+		return
+	}
+	podMap := make(map[string]*v1.Pod, len(pods))
+	for _, pod := range pods {
+		podMap[pod.Namespace+"/"+pod.Name] = pod
+	}
+	for _, pi := range infos {
+		pod, ok := podMap[pi.Namespace+"/"+pi.Name]
+		if !ok {
+			continue
+		}
+		if _, ok := pod.Annotations[common.AnnotationPodBindToBoard]; !ok {
+			continue
+		}
+		for _, devices := range pi.ContainerDeviceMap {
+			for _, d := range devices {
+				r, ok := odp.resources[d.ResourceName]
+				if !ok || len(d.List) == 0 {
+					continue
+				}
+				ids, err := odp.injectedDevices(r, pod, d.List)
+				if err != nil {
+					klog.ErrorS(err, "recover devices for release hooks error", "pod", klog.KObj(pod))
+					continue
+				}
+				odp.releases.Add(pod.UID, ids...)
+				klog.V(4).InfoS("recover devices for release hooks", "pod", klog.KObj(pod), "devices", ids)
+			}
+		}
+	}
+}
+
+func podTerminated(pod *v1.Pod) bool {
+	return pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed
+}
+
+// releaseHandler run release hooks when pod finished or deleted, pods
+// finished before the handler is added are released when it replays them
+func (odp *OrinDevicePlugin) releaseHandler() cache.ResourceEventHandler {
+	release := func(pod *v1.Pod) {
+		ids := odp.releases.Take(pod.UID)
+		if len(ids) == 0 {
+			return
+		}
+		go func() {
+			if err := odp.runHooks(hook.PhaseRelease, pod, ids); err != nil {
+				klog.ErrorS(err, "release hooks failed", "pod", klog.KObj(pod))
+			}
+		}()
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if pod, ok := obj.(*v1.Pod); ok && podTerminated(pod) {
+				release(pod)
+			}
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			if pod, ok := obj.(*v1.Pod); ok && podTerminated(pod) {
+				release(pod)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			switch t := obj.(type) {
+			case *v1.Pod:
+				release(t)
+			case cache.DeletedFinalStateUnknown:
+				if pod, ok := t.Obj.(*v1.Pod); ok {
+					release(pod)
+				}
+			}
+		},
+	}
+}
+
+// quarantine mark device unhealthy and record it in node annotation, it stays
+// unhealthy until operator removes it from the annotation. The device is
+// pending until it is seen in the annotation, syncQuarantine retries the patch
+// and never clears a pending device
+func (odp *OrinDevicePlugin) quarantine(id, message string) {
+	odp.quarantineLock.Lock()
+	odp.quarantined.Insert(id)
+	if odp.ClientSet != nil {
+		odp.quarantinePending.Insert(id)
+	}
+	odp.Health.SetHealth(id, v1beta1.Unhealthy)
+	odp.quarantineLock.Unlock()
+	odp.event(odp.nodeRef(), v1.EventTypeWarning, ReasonQuarantined, fmt.Sprintf("device %s quarantined: %s", id, message))
+	if err := odp.patchQuarantine(); err != nil {
+		klog.ErrorS(err, "patch node quarantine annotation error, retry later", "device", id)
+	}
+}
+
+// patchQuarantine add pending devices to node annotation, the patch carries
+// resourceVersion of the node, so devices changed by others are never
+// overwritten and the patch is retried on conflict
+func (odp *OrinDevicePlugin) patchQuarantine() error {
+	if odp.ClientSet == nil {
+		return nil
+	}
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		node, err := odp.Sitter.GetNodeFromApiServer(odp.NodeName)
+		if err != nil {
+			return err
+		}
+		ids := parseQuarantine(node.Annotations[common.AnnotationNodeOrinQuarantine])
+		odp.quarantineLock.Lock()
+		pending := odp.quarantinePending.List()
+		odp.quarantineLock.Unlock()
+		if !ids.HasAll(pending...) {
+			ids.Insert(pending...)
+			patch, _ := json.Marshal(map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"resourceVersion": node.ResourceVersion,
+					"annotations":     map[string]string{common.AnnotationNodeOrinQuarantine: strings.Join(ids.List(), ",")},
+				},
+			})
+			if _, err := odp.ClientSet.CoreV1().Nodes().Patch(context.TODO(), odp.NodeName, k8stypes.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+				return err
+			}
+		}
+		odp.quarantineLock.Lock()
+		odp.quarantinePending.Delete(pending...)
+		odp.quarantineLock.Unlock()
+		return nil
+	})
+}
+
+// syncQuarantine apply node quarantine annotation to device health, devices
+// removed from annotation by operator become healthy again
+func (odp *OrinDevicePlugin) syncQuarantine() {
+	node, err := odp.Sitter.GetNodeFromApiServer(odp.NodeName)
+	if err != nil {
+		klog.ErrorS(err, "get node for quarantine error")
+		return
+	}
+	ids := parseQuarantine(node.Annotations[common.AnnotationNodeOrinQuarantine])
+	odp.quarantineLock.Lock()
+	odp.quarantinePending.Delete(ids.List()...)
+	pending := odp.quarantinePending.Len() != 0
+	for _, id := range ids.List() {
+		odp.quarantined.Insert(id)
+		odp.Health.SetHealth(id, v1beta1.Unhealthy)
+	}
+	// pending devices are not in annotation yet, they are not cleared
+	for _, id := range odp.quarantined.Difference(ids).Difference(odp.quarantinePending).List() {
+		klog.InfoS("device quarantine cleared", "device", id)
+		odp.quarantined.Delete(id)
+		if !odp.boardPoweredOff(id) && odp.agentHealthy(id) {
+			odp.Health.SetHealth(id, v1beta1.Healthy)
+		}
+	}
+	odp.quarantineLock.Unlock()
+	if pending {
+		if err := odp.patchQuarantine(); err != nil {
+			klog.ErrorS(err, "patch node quarantine annotation error, retry later")
+		}
+	}
+}
+
+func parseQuarantine(val string) sets.String {
+	ids := sets.NewString()
+	for _, id := range strings.Split(val, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids.Insert(id)
+		}
+	}
+	return ids
+}
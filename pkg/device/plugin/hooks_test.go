@@ -0,0 +1,131 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/hook"
+	"github.com/superedge/orin-device-system/pkg/device/provider"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+func TestRunHooksQuarantine(t *testing.T) {
+	p := &provider.FileDeviceProvider{FileDevice: &provider.OrinFileDevice{BoardDevices: []*provider.Device{
+		{ID: 1, OrinSocs: []*provider.OrinSoc{{ID: 1, IP: "10.0.0.1"}, {ID: 2, IP: "10.0.0.2"}}},
+	}}}
+	hooks := &hook.Config{Allocate: []*hook.Hook{
+		{Name: "ignored", Exec: []string{"false"}, FailurePolicy: hook.FailurePolicyIgnore, Timeout: hook.DefaultTimeout},
+		{Name: "reset", Exec: []string{"/bin/sh", "-c", "test {{.Soc.ip}} = 10.0.0.1"}, FailurePolicy: hook.FailurePolicyQuarantine, Timeout: hook.DefaultTimeout},
+	}}
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	sitter := &fakeSitter{node: node}
+	odp := &OrinDevicePlugin{
+		OrinDeviceConfig: &OrinDeviceConfig{DeviceProvider: p, Hooks: hooks, Sitter: sitter, Recorder: record.NewFakeRecorder(10), NodeName: "node1"},
+		Health:           NewHealthView("1-1", "1-2"),
+		quarantined:      sets.NewString(),
+	}
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "p1"}}
+
+	if err := odp.runHooks(hook.PhaseAllocate, pod, []string{"1-1"}); err != nil {
+		t.Fatalf("runHooks() of 1-1 error = %v", err)
+	}
+	if err := odp.runHooks(hook.PhaseAllocate, pod, []string{"1-2"}); err == nil {
+		t.Fatalf("runHooks() of 1-2 should fail")
+	}
+	if odp.Health.Health("1-2") != v1beta1.Unhealthy || odp.Health.Health("1-1") != v1beta1.Healthy {
+		t.Fatalf("1-2 should be quarantined")
+	}
+	// release phase has no hooks
+	if err := odp.runHooks(hook.PhaseRelease, pod, []string{"1-2"}); err != nil {
+		t.Fatalf("runHooks() of release error = %v", err)
+	}
+
+	// operator quarantines 1-1 and clears 1-2 by node annotation
+	node.Annotations = map[string]string{common.AnnotationNodeOrinQuarantine: "1-1"}
+	odp.syncQuarantine()
+	if odp.Health.Health("1-2") != v1beta1.Healthy || odp.Health.Health("1-1") != v1beta1.Unhealthy {
+		t.Fatalf("quarantine annotation not applied")
+	}
+}
+
+func TestSyncQuarantinePending(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	odp := &OrinDevicePlugin{
+		OrinDeviceConfig:  &OrinDeviceConfig{Sitter: &fakeSitter{node: node}, NodeName: "node1"},
+		Health:            NewHealthView("1-1", "1-2"),
+		quarantined:       sets.NewString("1-1"),
+		quarantinePending: sets.NewString("1-1"),
+	}
+	odp.Health.SetHealth("1-1", v1beta1.Unhealthy)
+
+	// patch of 1-1 is not confirmed yet
+	odp.syncQuarantine()
+	if odp.Health.Health("1-1") != v1beta1.Unhealthy || !odp.quarantinePending.Has("1-1") {
+		t.Fatalf("pending device should stay quarantined")
+	}
+	node.Annotations = map[string]string{common.AnnotationNodeOrinQuarantine: "1-1"}
+	odp.syncQuarantine()
+	if odp.quarantinePending.Len() != 0 {
+		t.Fatalf("confirmed device should not be pending, got %v", odp.quarantinePending.List())
+	}
+	// operator clears the confirmed device
+	node.Annotations = nil
+	odp.syncQuarantine()
+	if odp.Health.Health("1-1") != v1beta1.Healthy {
+		t.Fatalf("quarantine of 1-1 should be cleared")
+	}
+}
+
+func TestReleaseTracker(t *testing.T) {
+	tracker := newReleaseTracker()
+	tracker.Add("uid1", "1-1")
+	tracker.Add("uid1", "2-1")
+	if ids := tracker.Take("uid1"); len(ids) != 2 || ids[0] != "1-1" {
+		t.Errorf("Take() = %v", ids)
+	}
+	if ids := tracker.Take("uid1"); ids != nil {
+		t.Errorf("released pod should be forgotten, got %v", ids)
+	}
+}
+
+func TestRecoverReleases(t *testing.T) {
+	hooks := &hook.Config{Release: []*hook.Hook{{Name: "reset", Exec: []string{"true"}, Timeout: hook.DefaultTimeout}}}
+	running := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "running", UID: "uid1", Annotations: map[string]string{
+		common.AnnotationPodBindToBoard: "2",
+	}}}
+	unbound := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "unbound", UID: "uid2"}}
+	infos := []*types.PodInfo{
+		{Namespace: "default", Name: "running", ContainerDeviceMap: map[string][]*types.Device{
+			"test": {types.NewDevice([]string{"1-1"}, "superedge.io/device-orin-1")},
+		}},
+		{Namespace: "default", Name: "unbound", ContainerDeviceMap: map[string][]*types.Device{
+			"test": {types.NewDevice([]string{"1-1"}, "superedge.io/device-orin-1")},
+		}},
+		{Namespace: "default", Name: "deleted", ContainerDeviceMap: map[string][]*types.Device{
+			"test": {types.NewDevice([]string{"2-1"}, "superedge.io/device-orin-1")},
+		}},
+	}
+	odp := &OrinDevicePlugin{
+		OrinDeviceConfig: &OrinDeviceConfig{
+			Hooks:         hooks,
+			Sitter:        &fakeSitter{pods: []*v1.Pod{running, unbound}},
+			DeviceLocator: &listLocator{pods: infos},
+		},
+		resources: map[v1.ResourceName]*orinResource{"superedge.io/device-orin-1": {ResourceName: "superedge.io/device-orin-1", OrinID: 1}},
+		releases:  newReleaseTracker(),
+	}
+	odp.recoverReleases()
+	// the soc of orin-N is on the bound board
+	if ids := odp.releases.Take("uid1"); len(ids) != 1 || ids[0] != "2-1" {
+		t.Errorf("expect 2-1 of running pod tracked, got %v", ids)
+	}
+	if ids := odp.releases.Take("uid2"); ids != nil {
+		t.Errorf("expect pod without bound board not tracked, got %v", ids)
+	}
+}
@@ -0,0 +1,53 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/provider"
+	"github.com/superedge/orin-device-system/pkg/scheduler/manager/topo"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+// interconnect return links between socs of every board from provider, nil
+// if provider does not describe them
+func (odp *OrinDevicePlugin) interconnect() topo.Interconnect {
+	ip, ok := odp.DeviceProvider.(provider.InterconnectProvider)
+	if !ok {
+		return nil
+	}
+	res := topo.Interconnect{}
+	for _, boardID := range odp.DeviceProvider.GetBoards() {
+		for _, l := range ip.GetBoardLinks(boardID) {
+			res[boardID] = append(res[boardID], topo.Link{A: l.Socs[0], B: l.Socs[1], BandwidthMbps: l.BandwidthMbps, LatencyMicros: l.LatencyMicros})
+		}
+	}
+	return res
+}
+
+// publishInterconnect patch links of every board to node for the scheduler
+// extender if they changed
+func (odp *OrinDevicePlugin) publishInterconnect() {
+	links := odp.interconnect()
+	if links == nil || odp.ClientSet == nil {
+		return
+	}
+	data, _ := json.Marshal(links)
+	if string(data) == odp.interconnectPublished {
+		return
+	}
+	patch, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{common.AnnotationNodeOrinInterconnect: string(data)},
+		},
+	})
+	if _, err := odp.ClientSet.CoreV1().Nodes().Patch(context.TODO(), odp.NodeName, k8stypes.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		klog.ErrorS(err, "patch node interconnect annotation error")
+		return
+	}
+	odp.interconnectPublished = string(data)
+}
@@ -0,0 +1,42 @@
+package plugin
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/superedge/orin-device-system/pkg/device/provider"
+)
+
+func TestInterconnect(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "device.yaml")
+	content := `
+device:
+- id: 0
+  socs:
+  - id: 1
+  - id: 2
+- id: 1
+  socs:
+  - id: 1
+  - id: 2
+  links:
+  - socs: [1, 2]
+    bandwidth_mbps: 10000
+    latency_us: 5
+`
+	if err := os.WriteFile(config, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := provider.NewFileDeviceProvider(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	odp := &OrinDevicePlugin{OrinDeviceConfig: &OrinDeviceConfig{DeviceProvider: p}}
+	data, _ := json.Marshal(odp.interconnect())
+	expected := `{"1":[{"a":1,"b":2,"bw":10000,"lat":5}]}`
+	if string(data) != expected {
+		t.Fatalf("expect interconnect %s, actual %s", expected, data)
+	}
+}
@@ -0,0 +1,42 @@
+package plugin
+
+import (
+	"github.com/superedge/orin-device-system/pkg/device/metrics"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// updateDeviceMetrics update health and allocation gauge of every orin soc
+func (odp *OrinDevicePlugin) updateDeviceMetrics() {
+	allocated := sets.NewString()
+	pods, listErr := odp.DeviceLocator.List()
+	if listErr != nil {
+		klog.ErrorS(listErr, "list pod devices for metrics error")
+	}
+	for _, pi := range pods {
+		allocated.Insert(pi.DeviceIDs()...)
+	}
+	for _, r := range odp.resources {
+		for _, id := range r.DeviceIDs {
+			boardID, orinID, err := types.ParseOrinDeviceID(id)
+			if err != nil {
+				continue
+			}
+			labels := metrics.DeviceLabels(boardID, orinID)
+			metrics.DeviceHealthy.With(labels).Set(boolToFloat(odp.Health.Health(id) == v1beta1.Healthy))
+			// keep the last value if kubelet is unreachable
+			if listErr == nil {
+				metrics.DeviceAllocated.With(labels).Set(boolToFloat(allocated.Has(id)))
+			}
+		}
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
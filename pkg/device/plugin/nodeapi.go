@@ -0,0 +1,249 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+
+	"golang.org/x/sys/unix"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// DefaultAPISocket is the node local api socket, mount its directory into
+	// pods to query their allocation
+	DefaultAPISocket = "/var/run/orin-device/api.sock"
+
+	apiAllocationPath = "/v1/allocation"
+	apiWatchPath      = "/v1/watch"
+)
+
+// podUIDPattern match pod uid in cgroup path of both cgroupfs driver
+// (pod<uid>) and systemd driver (pod<uid with _>.slice)
+var podUIDPattern = regexp.MustCompile(`pod([0-9a-f]{8}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{4}[-_][0-9a-f]{12})`)
+
+// BoardAllocation is the board of pod and its attributes
+type BoardAllocation struct {
+	ID    int                    `json:"id"`
+	Attrs map[string]interface{} `json:"attrs"`
+}
+
+// SocAllocation is an orin soc allocated to a container of pod
+type SocAllocation struct {
+	ID        int                    `json:"id"`
+	Device    string                 `json:"device"`
+	Resource  string                 `json:"resource"`
+	Container string                 `json:"container"`
+	Health    string                 `json:"health"`
+	Attrs     map[string]interface{} `json:"attrs"`
+}
+
+// Allocation is the response of node local api
+type Allocation struct {
+	Namespace string           `json:"namespace"`
+	Name      string           `json:"name"`
+	UID       string           `json:"uid"`
+	Board     *BoardAllocation `json:"board,omitempty"`
+	Socs      []*SocAllocation `json:"socs"`
+}
+
+type peerPIDKey struct{}
+
+// ServeNodeAPI serve allocation api on unix socket, caller pod is identified
+// by the pid of socket peer, so plugin must run in host pid namespace
+func (odp *OrinDevicePlugin) ServeNodeAPI(socket string, stop <-chan struct{}) error {
+	if err := os.MkdirAll(filepath.Dir(socket), 0755); err != nil {
+		return err
+	}
+	_ = os.Remove(socket)
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return err
+	}
+	// every pod mounting the socket is allowed to connect
+	if err := os.Chmod(socket, 0666); err != nil {
+		listener.Close()
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(apiAllocationPath, odp.serveAllocation)
+	mux.HandleFunc(apiWatchPath, odp.serveWatch)
+	server := &http.Server{
+		Handler: mux,
+		ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+			pid, err := peerPID(c)
+			if err != nil {
+				klog.ErrorS(err, "get node api peer pid error")
+				return ctx
+			}
+			return context.WithValue(ctx, peerPIDKey{}, pid)
+		},
+	}
+	go func() {
+		<-stop
+		server.Close()
+		_ = os.Remove(socket)
+	}()
+	klog.InfoS("node api serving", "socket", socket)
+	if err := server.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func peerPID(c net.Conn) (int, error) {
+	uc, ok := c.(*net.UnixConn)
+	if !ok {
+		return 0, fmt.Errorf("not unix connection")
+	}
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	var cred *unix.Ucred
+	var credErr error
+	if err := raw.Control(func(fd uintptr) {
+		cred, credErr = unix.GetsockoptUcred(int(fd), unix.SOL_SOCKET, unix.SO_PEERCRED)
+	}); err != nil {
+		return 0, err
+	}
+	if credErr != nil {
+		return 0, credErr
+	}
+	return int(cred.Pid), nil
+}
+
+// podUIDFromCgroup return pod uid in content of /proc/<pid>/cgroup
+func podUIDFromCgroup(cgroup string) (string, bool) {
+	m := podUIDPattern.FindStringSubmatch(cgroup)
+	if m == nil {
+		return "", false
+	}
+	return strings.ReplaceAll(m[1], "_", "-"), true
+}
+
+// callerPod identify pod of the api caller
+func (odp *OrinDevicePlugin) callerPod(r *http.Request) (*v1.Pod, error) {
+	pid, ok := r.Context().Value(peerPIDKey{}).(int)
+	if !ok {
+		return nil, fmt.Errorf("unknown caller")
+	}
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+	if err != nil {
+		return nil, fmt.Errorf("read cgroup of caller %d error: %v", pid, err)
+	}
+	uid, ok := podUIDFromCgroup(string(data))
+	if !ok {
+		return nil, fmt.Errorf("caller %d is not in a pod", pid)
+	}
+	return odp.Sitter.GetPodByUID(uid)
+}
+
+// allocation build allocation of pod from locator, provider and health view
+func (odp *OrinDevicePlugin) allocation(pod *v1.Pod) (*Allocation, error) {
+	pods, err := odp.DeviceLocator.List()
+	if err != nil {
+		return nil, err
+	}
+	res := &Allocation{Namespace: pod.Namespace, Name: pod.Name, UID: string(pod.UID), Socs: []*SocAllocation{}}
+	if board, err := strconv.Atoi(pod.Annotations[common.AnnotationPodBindToBoard]); err == nil {
+		res.Board = &BoardAllocation{ID: board, Attrs: odp.DeviceProvider.GetBoardAttrs(board)}
+	}
+	for _, pi := range pods {
+		if pi.Namespace != pod.Namespace || pi.Name != pod.Name {
+			continue
+		}
+		for container, devices := range pi.ContainerDeviceMap {
+			for _, d := range devices {
+				for _, id := range d.List {
+					boardID, orinID, err := types.ParseOrinDeviceID(id)
+					if err != nil {
+						continue
+					}
+					res.Socs = append(res.Socs, &SocAllocation{
+						ID:        orinID,
+						Device:    id,
+						Resource:  string(d.ResourceName),
+						Container: container,
+						Health:    odp.Health.Health(id),
+						Attrs:     odp.orinAttrs(boardID, orinID),
+					})
+				}
+			}
+		}
+	}
+	return res, nil
+}
+
+func (odp *OrinDevicePlugin) callerAllocation(w http.ResponseWriter, r *http.Request) (*v1.Pod, *Allocation, bool) {
+	pod, err := odp.callerPod(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return nil, nil, false
+	}
+	a, err := odp.allocation(pod)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return nil, nil, false
+	}
+	return pod, a, true
+}
+
+func (odp *OrinDevicePlugin) serveAllocation(w http.ResponseWriter, r *http.Request) {
+	_, a, ok := odp.callerAllocation(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(a)
+}
+
+// serveWatch stream allocation as json lines, a new line is written when
+// health of any soc of pod changed
+func (odp *OrinDevicePlugin) serveWatch(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	changed, cancel := odp.Health.Subscribe()
+	defer cancel()
+	pod, a, ok := odp.callerAllocation(w, r)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	var last *Allocation
+	for {
+		if last == nil || !reflect.DeepEqual(last, a) {
+			if err := encoder.Encode(a); err != nil {
+				return
+			}
+			flusher.Flush()
+			last = a
+		}
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+		var err error
+		if a, err = odp.allocation(pod); err != nil {
+			klog.ErrorS(err, "build allocation for watch error", "pod", klog.KObj(pod))
+			a = last
+		}
+	}
+}
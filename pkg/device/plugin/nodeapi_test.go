@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/provider"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+func TestPodUIDFromCgroup(t *testing.T) {
+	tests := []struct {
+		name   string
+		cgroup string
+		expect string
+	}{
+		{
+			name:   "cgroupfs",
+			cgroup: "11:cpu,cpuacct:/kubepods/besteffort/pod0f1e2d3c-4b5a-6978-8a9b-0c1d2e3f4a5b/abcdef\n",
+			expect: "0f1e2d3c-4b5a-6978-8a9b-0c1d2e3f4a5b",
+		},
+		{
+			name:   "systemd",
+			cgroup: "0::/kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod0f1e2d3c_4b5a_6978_8a9b_0c1d2e3f4a5b.slice/cri-containerd-abc.scope\n",
+			expect: "0f1e2d3c-4b5a-6978-8a9b-0c1d2e3f4a5b",
+		},
+		{
+			name:   "not in pod",
+			cgroup: "0::/system.slice/kubelet.service\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uid, ok := podUIDFromCgroup(tt.cgroup)
+			if ok != (tt.expect != "") || uid != tt.expect {
+				t.Errorf("podUIDFromCgroup() = %s, %v, expect %s", uid, ok, tt.expect)
+			}
+		})
+	}
+}
+
+func TestAllocation(t *testing.T) {
+	p := &provider.FileDeviceProvider{FileDevice: &provider.OrinFileDevice{BoardDevices: []*provider.Device{
+		{ID: 1, DeviceNum: "board-1", OrinSocs: []*provider.OrinSoc{{ID: 1, Name: "soc-1"}, {ID: 2, Name: "soc-2"}}},
+	}}}
+	pi := types.NewPI("default", "p1")
+	pi.AddDevice("c1", types.NewDevice([]string{"1-2"}, common.ExtendResouceTypeOrinPrefix+"2"))
+	other := types.NewPI("default", "p2")
+	other.AddDevice("c1", types.NewDevice([]string{"1-1"}, common.ExtendResouceTypeOrinPrefix+"1"))
+	odp := &OrinDevicePlugin{
+		OrinDeviceConfig: &OrinDeviceConfig{DeviceProvider: p, DeviceLocator: &listLocator{pods: []*types.PodInfo{pi, other}}},
+		Health:           NewHealthView("1-1", "1-2"),
+	}
+	odp.Health.SetHealth("1-2", v1beta1.Unhealthy)
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "p1", UID: "uid1",
+		Annotations: map[string]string{common.AnnotationPodBindToBoard: "1"}}}
+
+	a, err := odp.allocation(pod)
+	if err != nil {
+		t.Fatalf("allocation() error = %v", err)
+	}
+	if a.Board == nil || a.Board.ID != 1 || a.Board.Attrs[provider.AttrKeyBoardDeviceNum] != "board-1" {
+		t.Errorf("unexpected board %+v", a.Board)
+	}
+	if len(a.Socs) != 1 {
+		t.Fatalf("expect one soc, got %d", len(a.Socs))
+	}
+	soc := a.Socs[0]
+	if soc.ID != 2 || soc.Device != "1-2" || soc.Container != "c1" || soc.Health != v1beta1.Unhealthy || soc.Attrs[provider.AttrKeyOrinName] != "soc-2" {
+		t.Errorf("unexpected soc %+v", soc)
+	}
+}
+
+func TestServeNodeAPIUnknownCaller(t *testing.T) {
+	odp := &OrinDevicePlugin{OrinDeviceConfig: &OrinDeviceConfig{Sitter: &fakeSitter{}}, Health: NewHealthView()}
+	socket := filepath.Join(t.TempDir(), "api.sock")
+	stop := make(chan struct{})
+	defer close(stop)
+	go odp.ServeNodeAPI(socket, stop)
+
+	client := &http.Client{Transport: &http.Transport{DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+		return net.Dial("unix", socket)
+	}}}
+	var resp *http.Response
+	var err error
+	for i := 0; i < 50; i++ {
+		if resp, err = client.Get("http://unix" + apiAllocationPath); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("get allocation error = %v", err)
+	}
+	defer resp.Body.Close()
+	// test process is not in a pod known by sitter
+	if resp.StatusCode != http.StatusForbidden {
+		var body interface{}
+		json.NewDecoder(resp.Body).Decode(&body)
+		t.Errorf("expect forbidden, got %d %v", resp.StatusCode, body)
+	}
+}
+
+type listLocator struct {
+	pods []*types.PodInfo
+}
+
+func (l *listLocator) Locate(*types.Device) (*types.PodContainer, error) { return nil, nil }
+func (l *listLocator) List() ([]*types.PodInfo, error)                   { return l.pods, nil }
+func (l *listLocator) Close() error                                      { return nil }
@@ -2,15 +2,22 @@ package plugin
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/checkpoint"
+	"github.com/superedge/orin-device-system/pkg/device/hook"
 	"github.com/superedge/orin-device-system/pkg/device/kubeapis"
+	"github.com/superedge/orin-device-system/pkg/device/metrics"
 	"github.com/superedge/orin-device-system/pkg/device/provider"
 	"github.com/superedge/orin-device-system/pkg/device/types"
 	"github.com/superedge/orin-device-system/pkg/scheduler/manager"
@@ -18,49 +25,161 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
 
+	"google.golang.org/grpc"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	applyv1 "k8s.io/client-go/applyconfigurations/core/v1"
 )
 
 const (
 	HostVitualPath = "/data/edge/device"
+
+	DefaultMetricsPeriod = 30 * time.Second
+)
+
+// reasons of PreStartContainer failing or skipping orin attr injection
+const (
+	ReasonEmptyDevices      = "EmptyDevices"
+	ReasonLocateFailed      = "LocateFailed"
+	ReasonPodNotFound       = "PodNotFound"
+	ReasonAnnotationMissing = "AnnotationMissing"
+	ReasonInvalidAnnotation = "InvalidAnnotation"
+	ReasonEmptyOrinRequest  = "EmptyOrinRequest"
+	ReasonEmptyAttrs        = "EmptyAttrs"
+	ReasonPopulateFailed    = "PopulateFailed"
+	ReasonPreferenceIgnored = "PreferenceIgnored"
 )
 
 type OrinDeviceConfig struct {
-	DeviceLocator  map[v1.ResourceName]kubeapis.DeviceLocator
+	DeviceLocator  kubeapis.DeviceLocator
 	Sitter         kubeapis.Sitter
 	DeviceProvider provider.DeviceProvider
 	NodeName       string
 	ClientSet      *kubernetes.Clientset
+	Recorder       record.EventRecorder
+	// Checkpoint record pod devices, nil means disable
+	Checkpoint *checkpoint.Store
+	// PodLookupConfig bound the wait of pod bind annotation in PreStartContainer
+	PodLookupConfig
+	// AuditPeriod is the period of allocation audit, zero means disable
+	AuditPeriod time.Duration
+	// Hooks run on soc allocation and release, nil means disable
+	Hooks *hook.Config
+	// PowerIdleTimeout is how long a board stays idle before powered off, zero
+	// means disable, it needs a provider with power control
+	PowerIdleTimeout time.Duration
+	// AgentPort is the port of agent on every soc ip, zero means disable
+	AgentPort   int
+	AgentPeriod time.Duration
+	// AgentTLS dial agents with tls, nil means plaintext
+	AgentTLS *tls.Config
+	// TelemetryConfig collect soc telemetry, zero period means disable
+	TelemetryConfig
 }
 
-type OrinDevicePlugin map[string]*DevicePluginServer
+// orinResource is one superedge.io/device-orin-N resource, its devices are
+// orin N on every board
+type orinResource struct {
+	OrinID       int
+	ResourceName v1.ResourceName
+	DeviceIDs    []string
+	// Any is the superedge.io/device-orin resource, its devices are every orin
+	// on every board and scheduler extender chooses the orins
+	Any bool
+}
 
-func NewOrinDevicePlugin(c *OrinDeviceConfig) (OrinDevicePlugin, error) {
+// OrinDevicePlugin serve all orin resources with one grpc server, one kubelet
+// watcher, one device locator and one health view, and dispatch every call to
+// the resource by the endpoint it comes from
+type OrinDevicePlugin struct {
+	*OrinDeviceConfig
+	Health *HealthView
+
+	injected  *injectTracker
+	resources map[v1.ResourceName]*orinResource
+	server    *grpc.Server
+	endpoints []*DevicePluginServer
+
+	// allocatableDiff is the last reported allocatable mismatch of resource
+	allocatableDiff map[v1.ResourceName]string
+	// auditReported is keys of findings reported by the last audit
+	auditReported sets.String
+	auditSummary  *AuditSummary
+
+	releases       *releaseTracker
+	quarantineLock sync.Mutex
+	quarantined    sets.String
+	// quarantinePending is quarantined devices not confirmed in node
+	// annotation yet
+	quarantinePending sets.String
+
+	power      provider.PowerController
+	powerLock  sync.Mutex
+	powerState map[int]string
+	idleSince  map[int]time.Time
+	// publishLock serialize node patches of power states, it guards
+	// powerPublished
+	publishLock    sync.Mutex
+	powerPublished string
+
+	agentLock sync.Mutex
+	agents    map[string]*socAgent
+
+	telemetryPublished    string
+	interconnectPublished string
+}
+
+func NewOrinDevicePlugin(c *OrinDeviceConfig) (*OrinDevicePlugin, error) {
 	classes := c.DeviceProvider.GetOrinClasses()
 
 	klog.V(5).InfoS("get devices from provider", "device ids", classes)
-	odp := make(map[string]*DevicePluginServer, len(classes))
+	odp := &OrinDevicePlugin{
+		OrinDeviceConfig:  c,
+		resources:         make(map[v1.ResourceName]*orinResource, len(classes)),
+		injected:          newInjectTracker(),
+		allocatableDiff:   make(map[v1.ResourceName]string),
+		auditReported:     sets.NewString(),
+		releases:          newReleaseTracker(),
+		quarantined:       sets.NewString(),
+		quarantinePending: sets.NewString(),
+		powerState:        make(map[int]string),
+		idleSince:         make(map[int]time.Time),
+		agents:            make(map[string]*socAgent),
+		server:            grpc.NewServer(),
+	}
+	odp.PodLookupConfig.setDefaults()
+	if odp.DeviceLocator == nil {
+		odp.DeviceLocator = kubeapis.NewKubeletV1DeviceLocator()
+	}
 	// provider get orin soc ids
-
+	allDeviceIDs := []string{}
 	for orinID, boardIDSets := range classes {
 		resourceName := fmt.Sprintf("%s%d", common.ExtendResouceTypeOrinPrefix, orinID)
-		c.DeviceLocator[v1.ResourceName(resourceName)] = kubeapis.NewKubeletDeviceLocator(resourceName)
-		gsrv, err := NewOrinDeviceGrpcServer(c, resourceName, orinID, boardIDSets)
-		if err != nil {
-			return nil, err
+		r := &orinResource{
+			OrinID:       orinID,
+			ResourceName: v1.ResourceName(resourceName),
 		}
-		odp[resourceName] = &DevicePluginServer{
-			Endpoint:           fmt.Sprintf("%s.sock", strings.ReplaceAll(resourceName, "/", "-")),
-			ResourceName:       resourceName,
-			DevicePluginServer: gsrv,
+		for _, bid := range boardIDSets.List() {
+			r.DeviceIDs = append(r.DeviceIDs, types.OrinDeviceID(bid, orinID))
 		}
+		allDeviceIDs = append(allDeviceIDs, r.DeviceIDs...)
+		odp.addResource(r)
 	}
-	klog.V(5).InfoS("create orin device plugin", "odp", odp)
+	if len(allDeviceIDs) != 0 {
+		r := &orinResource{ResourceName: common.ExtendResouceTypeOrin, DeviceIDs: append([]string{}, allDeviceIDs...), Any: true}
+		sort.Strings(r.DeviceIDs)
+		odp.addResource(r)
+	}
+	odp.Health = NewHealthView(allDeviceIDs...)
+	v1beta1.RegisterDevicePluginServer(odp.server, odp)
+	klog.V(5).InfoS("create orin device plugin", "resources", odp.resources)
 	// patch node extra resource
 	if err := patchNodeExtraResource(c.ClientSet, c.DeviceProvider, c.NodeName); err != nil {
 		return nil, err
@@ -68,112 +187,251 @@ func NewOrinDevicePlugin(c *OrinDeviceConfig) (OrinDevicePlugin, error) {
 	return odp, nil
 }
 
-func (odp OrinDevicePlugin) Run(stop <-chan struct{}) {
-	for _, p := range odp {
-		klog.InfoS("start plugin", "name", p.ResourceName)
-		go p.Run(stop)
-	}
+func (odp *OrinDevicePlugin) addResource(r *orinResource) {
+	odp.resources[r.ResourceName] = r
+	odp.endpoints = append(odp.endpoints, &DevicePluginServer{
+		Endpoint:     fmt.Sprintf("%s.sock", strings.ReplaceAll(string(r.ResourceName), "/", "-")),
+		ResourceName: string(r.ResourceName),
+		Server:       odp.server,
+	})
 }
 
-type OrinDeviceGrpcServer struct {
-	OrinID       int
-	ResourceName v1.ResourceName
-	devices      []*v1beta1.Device
-	*OrinDeviceConfig
+func (odp *OrinDevicePlugin) Run(stop <-chan struct{}) {
+	NewSupervisor(odp.endpoints...).Run(stop)
+	go wait.Until(odp.updateDeviceMetrics, DefaultMetricsPeriod, stop)
+	if _, ok := odp.DeviceLocator.(kubeapis.AllocatableLister); ok {
+		go wait.Until(odp.checkAllocatable, DefaultMetricsPeriod, stop)
+	}
+	if odp.Hooks != nil {
+		odp.recoverReleases()
+		odp.Sitter.AddPodEventHandler(odp.releaseHandler())
+	}
+	// quarantine of the operator is kept even without hooks
+	go wait.Until(odp.syncQuarantine, DefaultQuarantinePeriod, stop)
+	if odp.PowerIdleTimeout > 0 {
+		if odp.power = provider.GetPowerController(odp.DeviceProvider); odp.power != nil {
+			odp.recoverPower()
+			go wait.Until(odp.syncPower, DefaultPowerSyncPeriod, stop)
+		} else {
+			klog.Warningf("provider %s can not switch board power, idle power off disabled", odp.DeviceProvider.Name())
+		}
+	}
+	if odp.AgentPort > 0 {
+		if odp.AgentPeriod <= 0 {
+			odp.AgentPeriod = DefaultAgentPeriod
+		}
+		go wait.Until(odp.pollAgents, odp.AgentPeriod, stop)
+	}
+	if odp.TelemetryPeriod > 0 {
+		if source, err := odp.telemetrySource(); err != nil {
+			klog.ErrorS(err, "soc telemetry disabled")
+		} else {
+			go wait.Until(func() { odp.collectTelemetry(source) }, odp.TelemetryPeriod, stop)
+		}
+	}
+	if _, ok := odp.DeviceProvider.(provider.InterconnectProvider); ok {
+		go wait.Until(odp.publishInterconnect, DefaultMetricsPeriod, stop)
+	}
+	if odp.AuditPeriod > 0 {
+		go wait.Until(odp.audit, odp.AuditPeriod, stop)
+	}
+	if odp.Checkpoint != nil {
+		odp.recoverInjectedConfigs()
+		odp.Sitter.AddPodEventHandler(cache.ResourceEventHandlerFuncs{DeleteFunc: odp.deletePodCheckpoint})
+		go wait.Until(odp.pruneCheckpoint, DefaultCheckpointPeriod, stop)
+	}
+	go func() {
+		<-stop
+		odp.server.Stop()
+		odp.closeAgents()
+		if err := odp.DeviceLocator.Close(); err != nil {
+			klog.ErrorS(err, "close device locator error")
+		}
+	}()
 }
 
-func NewOrinDeviceGrpcServer(c *OrinDeviceConfig, resourceName string, orinID int, boardIDSets sets.Int) (*OrinDeviceGrpcServer, error) {
-
-	devices := make([]*v1beta1.Device, boardIDSets.Len())
-
-	for i, bid := range boardIDSets.UnsortedList() {
-		devices[i] = &v1beta1.Device{
-			ID:     fmt.Sprintf("%d-%d", bid, orinID),
-			Health: v1beta1.Healthy,
+// Ready return nil when pod informer synced and every orin resource
+// registered to kubelet
+func (odp *OrinDevicePlugin) Ready() error {
+	if !odp.Sitter.HasSynced() {
+		return fmt.Errorf("pod informer has not synced")
+	}
+	for name, state := range odp.States() {
+		if state != ServerStateRegistered {
+			return fmt.Errorf("resource %s is %s", name, state)
 		}
 	}
+	return nil
+}
 
-	return &OrinDeviceGrpcServer{
-		OrinID:           orinID,
-		ResourceName:     v1.ResourceName(resourceName),
-		devices:          devices,
-		OrinDeviceConfig: c,
-	}, nil
+// States return every orin resource plugin server state
+func (odp *OrinDevicePlugin) States() map[string]ServerState {
+	res := make(map[string]ServerState, len(odp.endpoints))
+	for _, p := range odp.endpoints {
+		res[p.ResourceName], _ = p.State()
+	}
+	return res
+}
 
+func (odp *OrinDevicePlugin) resource(ctx context.Context) (*orinResource, error) {
+	resourceName, err := resourceFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := odp.resources[resourceName]
+	if !ok {
+		return nil, fmt.Errorf("unknown resource %s", resourceName)
+	}
+	return r, nil
 }
 
-func (s *OrinDeviceGrpcServer) GetDevicePluginOptions(ctx context.Context, empty *v1beta1.Empty) (*v1beta1.DevicePluginOptions, error) {
+func (odp *OrinDevicePlugin) GetDevicePluginOptions(ctx context.Context, empty *v1beta1.Empty) (*v1beta1.DevicePluginOptions, error) {
 	return &v1beta1.DevicePluginOptions{
-		PreStartRequired: true,
+		PreStartRequired:                true,
+		GetPreferredAllocationAvailable: true,
 	}, nil
 }
 
-func (s *OrinDeviceGrpcServer) ListAndWatch(empty *v1beta1.Empty, server v1beta1.DevicePlugin_ListAndWatchServer) error {
-	if err := server.Send(&v1beta1.ListAndWatchResponse{Devices: s.devices}); err != nil {
+func (odp *OrinDevicePlugin) ListAndWatch(empty *v1beta1.Empty, server v1beta1.DevicePlugin_ListAndWatchServer) error {
+	r, err := odp.resource(server.Context())
+	if err != nil {
 		return err
 	}
-	<-server.Context().Done()
-	return nil
+	changed, cancel := odp.Health.Subscribe()
+	defer cancel()
+	for {
+		if err := server.Send(&v1beta1.ListAndWatchResponse{Devices: odp.Health.Devices(r.DeviceIDs)}); err != nil {
+			return err
+		}
+		select {
+		case <-changed:
+			klog.V(4).InfoS("device health changed, send devices again", "resource", r.ResourceName)
+		case <-server.Context().Done():
+			return nil
+		}
+	}
 }
 
-func (s *OrinDeviceGrpcServer) PreStartContainer(ctx context.Context, request *v1beta1.PreStartContainerRequest) (*v1beta1.PreStartContainerResponse, error) {
+func (odp *OrinDevicePlugin) PreStartContainer(ctx context.Context, request *v1beta1.PreStartContainerRequest) (*v1beta1.PreStartContainerResponse, error) {
+	startTime := time.Now()
+	r, err := odp.resource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pod, reason, err := odp.preStart(r, request.DevicesIDs)
+	odp.report(r, pod, request.DevicesIDs, reason, err)
+	metrics.PreStartTotal.WithLabelValues(string(r.ResourceName), metrics.Result(err), reason).Inc()
+	metrics.PreStartDuration.WithLabelValues(string(r.ResourceName)).Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		return nil, err
+	}
+	return &v1beta1.PreStartContainerResponse{}, nil
+}
 
-	devicesIDs := request.DevicesIDs
+// preStart inject orin attr into the pod which the devices allocated to, it
+// return the pod if located, and the reason why it fail or skip injecting
+func (odp *OrinDevicePlugin) preStart(r *orinResource, devicesIDs []string) (*v1.Pod, string, error) {
 	if len(devicesIDs) == 0 {
-		return &v1beta1.PreStartContainerResponse{}, fmt.Errorf("devices is empty")
+		return nil, ReasonEmptyDevices, fmt.Errorf("devices is empty")
 	}
 	// get pod by device ids
-	orindevice := types.NewDevice(devicesIDs, s.ResourceName)
-	curr, err := s.DeviceLocator[s.ResourceName].Locate(orindevice)
+	orindevice := types.NewDevice(devicesIDs, r.ResourceName)
+	curr, err := odp.locate(orindevice)
 	if err != nil {
 		klog.ErrorS(err, "no pod with such device list", "devices list", strings.Join(devicesIDs, ":"))
-		return nil, err
+		return nil, ReasonLocateFailed, err
 	}
-	pod, err := s.Sitter.GetPod(curr.Namespace, curr.Name)
+	pod, err := odp.getBoundPod(curr)
 	if err != nil {
 		klog.ErrorS(err, "failed to get pod", "pod", curr)
-		return nil, err
+		return nil, ReasonPodNotFound, err
 	}
 	// get board id from pod annotation which written by scheduler extender
 	boardID, ok := pod.Annotations[common.AnnotationPodBindToBoard]
 	if !ok {
 		klog.Errorf("annotation %s does not on pod %s", common.AnnotationPodBindToBoard, curr)
-		return nil, fmt.Errorf("annotation %s does not on pod %s", common.AnnotationPodBindToBoard, curr)
+		return pod, ReasonAnnotationMissing, fmt.Errorf("annotation %s does not on pod %s", common.AnnotationPodBindToBoard, curr)
 	}
 	boardIDInt, err := strconv.ParseInt(boardID, 10, 0)
 	if err != nil {
 		klog.ErrorS(err, "parse board ID error", "boardID", boardID)
-		return nil, err
+		return pod, ReasonInvalidAnnotation, err
 	}
-	orins := manager.BuildRequestOrinSet(pod)
-	if orins.Len() == 0 {
+	if len(podDevices(manager.PodBoardOrins(pod))) == 0 {
 		klog.V(4).InfoS("find empty orin request pod", "pod", curr)
-		return &v1beta1.PreStartContainerResponse{}, nil
+		return pod, ReasonEmptyOrinRequest, nil
 	}
-	// get orin attr from provider
-	attrs := s.DeviceProvider.GetOrinAttrs(int(boardIDInt), s.OrinID)
-	if attrs == nil {
-		klog.V(4).InfoS("find empty orin attr", "pod", curr, "board", boardID, "orin", s.OrinID)
-		return &v1beta1.PreStartContainerResponse{}, nil
+	// get orin attr from provider, socs of any orin resource is the orins
+	// chosen by scheduler extender, which may be on several boards
+	var config interface{}
+	socDevices := devicesIDs
+	if r.Any {
+		socDevices, err = odp.injectedDevices(r, pod, devicesIDs)
+		if err != nil {
+			klog.ErrorS(err, "kubelet did not take the preferred allocation", "pod", curr)
+			return pod, ReasonPreferenceIgnored, err
+		}
+		socs := odp.socsAttrs(socDevices)
+		if len(socs) == 0 {
+			klog.V(4).InfoS("find empty orin attr", "pod", curr, "board", boardID, "devices", socDevices)
+			return pod, ReasonEmptyAttrs, nil
+		}
+		config = socs
+	} else {
+		attrs := odp.orinAttrs(int(boardIDInt), r.OrinID)
+		if len(attrs) == 0 {
+			klog.V(4).InfoS("find empty orin attr", "pod", curr, "board", boardID, "orin", r.OrinID)
+			return pod, ReasonEmptyAttrs, nil
+		}
+		config = attrs
 	}
 
-	vpath := fmt.Sprintf("%s/%s/%s", HostVitualPath, s.ResourceName, devicesIDs[0])
-	if err := populateOrinAttr(vpath, attrs); err != nil {
-		klog.ErrorS(err, "populate Orin attr error", "vitual path", vpath, "attr", attrs)
-		return nil, fmt.Errorf("populate Orin attr error")
+	vpath := fmt.Sprintf("%s/%s/%s", HostVitualPath, r.ResourceName, devicesIDs[0])
+	if err := populateOrinAttr(vpath, config); err != nil {
+		klog.ErrorS(err, "populate Orin attr error", "vitual path", vpath, "attr", config)
+		return pod, ReasonPopulateFailed, fmt.Errorf("populate Orin attr error")
+	}
+	odp.addPodCheckpoint(pod, curr, orindevice)
+	if err := odp.runHooks(hook.PhaseAllocate, pod, socDevices); err != nil {
+		return pod, ReasonHookFailed, err
+	}
+	if len(odp.Hooks.Hooks(hook.PhaseRelease)) != 0 {
+		odp.releases.Add(pod.UID, socDevices...)
 	}
 
-	return &v1beta1.PreStartContainerResponse{}, nil
+	return pod, "", nil
 }
 
-func (s *OrinDeviceGrpcServer) GetPreferredAllocation(ctx context.Context, request *v1beta1.PreferredAllocationRequest) (*v1beta1.PreferredAllocationResponse, error) {
-	return &v1beta1.PreferredAllocationResponse{}, nil
+// locate is DeviceLocator.Locate with metrics
+func (odp *OrinDevicePlugin) locate(device *types.Device) (*types.PodContainer, error) {
+	startTime := time.Now()
+	pc, err := odp.DeviceLocator.Locate(device)
+	metrics.LocateDuration.WithLabelValues(metrics.Result(err)).Observe(time.Since(startTime).Seconds())
+	if err != nil {
+		metrics.LocateMissTotal.WithLabelValues(string(device.ResourceName)).Inc()
+	}
+	return pc, err
 }
 
-func (s *OrinDeviceGrpcServer) Allocate(ctx context.Context, request *v1beta1.AllocateRequest) (*v1beta1.AllocateResponse, error) {
+func (odp *OrinDevicePlugin) Allocate(ctx context.Context, request *v1beta1.AllocateRequest) (*v1beta1.AllocateResponse, error) {
+	startTime := time.Now()
+	r, err := odp.resource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := odp.allocate(r, request)
+	metrics.AllocateTotal.WithLabelValues(string(r.ResourceName), metrics.Result(err)).Inc()
+	metrics.AllocateDuration.WithLabelValues(string(r.ResourceName)).Observe(time.Since(startTime).Seconds())
+	return resp, err
+}
+
+func (odp *OrinDevicePlugin) allocate(r *orinResource, request *v1beta1.AllocateRequest) (*v1beta1.AllocateResponse, error) {
 	devicesIDs := []string{}
 	for _, container := range request.ContainerRequests {
 		devicesIDs = append(devicesIDs, container.DevicesIDs...)
+		if len(container.DevicesIDs) != 0 {
+			odp.addAllocationCheckpoint(types.NewDevice(container.DevicesIDs, r.ResourceName))
+		}
 	}
 	if len(devicesIDs) == 0 {
 		return &v1beta1.AllocateResponse{}, fmt.Errorf("devices is empty")
@@ -181,8 +439,8 @@ func (s *OrinDeviceGrpcServer) Allocate(ctx context.Context, request *v1beta1.Al
 	// make a vitual path, and device nums always 1
 	mounts := []*v1beta1.Mount{
 		{
-			ContainerPath: fmt.Sprintf("/etc/%s", s.ResourceName),
-			HostPath:      fmt.Sprintf("%s/%s/%s", HostVitualPath, s.ResourceName, devicesIDs[0]),
+			ContainerPath: fmt.Sprintf("/etc/%s", r.ResourceName),
+			HostPath:      fmt.Sprintf("%s/%s/%s", HostVitualPath, r.ResourceName, devicesIDs[0]),
 			ReadOnly:      true,
 		},
 	}
@@ -218,7 +476,9 @@ func patchNodeExtraResource(clientset *kubernetes.Clientset, provider provider.D
 	return nil
 }
 
-func populateOrinAttr(vpath string, attr map[string]interface{}) error {
+// populateOrinAttr write attrs of one soc, or a list of soc attrs of any orin
+// resource, to config.json
+func populateOrinAttr(vpath string, attr interface{}) error {
 	configPath := path.Join(vpath, "config.json")
 	if err := os.MkdirAll(vpath, os.ModePerm); err != nil {
 		return err
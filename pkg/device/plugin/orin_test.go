@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"context"
+	"net"
+	"path"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+func TestListAndWatchDispatch(t *testing.T) {
+	dir := t.TempDir()
+	odp := &OrinDevicePlugin{
+		Health: NewHealthView("0-1", "1-1", "0-2"),
+		resources: map[v1.ResourceName]*orinResource{
+			"orin-1": {OrinID: 1, ResourceName: "orin-1", DeviceIDs: []string{"0-1", "1-1"}},
+			"orin-2": {OrinID: 2, ResourceName: "orin-2", DeviceIDs: []string{"0-2"}},
+		},
+		server: grpc.NewServer(),
+	}
+	v1beta1.RegisterDevicePluginServer(odp.server, odp)
+	defer odp.server.Stop()
+
+	for name := range odp.resources {
+		l, err := net.Listen("unix", path.Join(dir, string(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		go odp.server.Serve(&resourceListener{Listener: l, addr: resourceAddr(name)})
+	}
+
+	for name, r := range odp.resources {
+		conn, err := grpc.Dial(path.Join(dir, string(name)), grpc.WithInsecure(), grpc.WithBlock(), grpc.WithTimeout(5*time.Second),
+			grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
+				return net.DialTimeout("unix", addr, timeout)
+			}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream, err := v1beta1.NewDevicePluginClient(conn).ListAndWatch(context.Background(), &v1beta1.Empty{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Devices) != len(r.DeviceIDs) {
+			t.Errorf("resource %s expect %d devices, actual %v", name, len(r.DeviceIDs), resp.Devices)
+		}
+		if name == "orin-2" {
+			odp.Health.SetHealth("0-2", v1beta1.Unhealthy)
+			resp, err = stream.Recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.Devices[0].Health != v1beta1.Unhealthy {
+				t.Errorf("resource %s expect unhealthy device, actual %v", name, resp.Devices)
+			}
+		}
+		conn.Close()
+	}
+}
@@ -2,60 +2,95 @@ package plugin
 
 import (
 	"context"
-	"log"
+	"fmt"
 	"net"
 	"os"
 	"path"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/superedge/orin-device-system/pkg/device/metrics"
 
 	"k8s.io/klog/v2"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
 )
 
+type ServerState string
+
+const (
+	ServerStateStarting   ServerState = "Starting"
+	ServerStateRegistered ServerState = "Registered"
+	ServerStateBackoff    ServerState = "Backoff"
+	ServerStateStopped    ServerState = "Stopped"
+)
+
+// DevicePluginServer is the endpoint of one resource, all endpoints share the
+// same grpc server, which dispatch request by the listener it comes from
 type DevicePluginServer struct {
-	Endpoint           string
-	ResourceName       string
-	DevicePluginServer v1beta1.DevicePluginServer
+	Endpoint     string
+	ResourceName string
+	Server       *grpc.Server
+
+	lock     sync.RWMutex
+	state    ServerState
+	lastErr  error
+	listener net.Listener
+	// exit receive grpc serve error, listener is unusable after that
+	exit chan error
 }
 
-func (p *DevicePluginServer) Run(stop <-chan struct{}) {
-	errChan := make(chan error, 1)
-	stoChan := make(chan struct{})
-	watcher, err := NewFSWatcher(v1beta1.DevicePluginPath)
-	if err != nil {
-		klog.Fatalf("create fswatch failed: %s", err.Error())
+// State return current state of plugin server and the last error which
+// make it go into backoff
+func (p *DevicePluginServer) State() (ServerState, error) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	if p.state == "" {
+		return ServerStateStopped, p.lastErr
+	}
+	return p.state, p.lastErr
+}
+
+func (p *DevicePluginServer) setState(state ServerState, err error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.state != state {
+		klog.InfoS("plugin server state changed", "resource", p.ResourceName, "from", p.state, "to", state, "err", err)
+	}
+	p.state = state
+	p.lastErr = err
+}
+
+func (p *DevicePluginServer) name() string {
+	return p.ResourceName
+}
+
+func (p *DevicePluginServer) socket() string {
+	return path.Join(v1beta1.DevicePluginPath, p.Endpoint)
+}
+
+// Start serve plugin grpc server, wait it ready and register it to kubelet
+func (p *DevicePluginServer) Start() error {
+	p.setState(ServerStateStarting, nil)
+	if err := p.Serve(); err != nil {
+		return err
 	}
-restart:
-	close(stoChan)
-	time.Sleep(time.Second)
-	stoChan = make(chan struct{})
-	p.Serve(stoChan)
 	if err := p.Wait(); err != nil {
-		klog.Error(err.Error())
-		goto restart
-	}
-	if err := p.Register(); err != nil {
-		errChan <- err
-	}
-	for {
-		select {
-		case err := <-errChan:
-			klog.Errorf("register error: %s", err.Error())
-			goto restart
-		case event := <-watcher.Events:
-			if event.Name == v1beta1.KubeletSocket && event.Op&fsnotify.Create == fsnotify.Create {
-				log.Printf("inotify: %s created, restarting.", v1beta1.KubeletSocket)
-				goto restart
-			}
-		case <-stop:
-			close(stoChan)
-			return
-		}
+		p.Stop()
+		return err
 	}
+	err := p.Register()
+	metrics.RegistrationTotal.WithLabelValues(p.ResourceName, metrics.Result(err)).Inc()
+	if err != nil {
+		p.Stop()
+		return err
+	}
+	p.setState(ServerStateRegistered, nil)
+	return nil
 }
 
 func (p *DevicePluginServer) Register() error {
@@ -73,38 +108,56 @@ func (p *DevicePluginServer) Register() error {
 		Endpoint:     p.Endpoint,
 		ResourceName: p.ResourceName,
 		Options: &v1beta1.DevicePluginOptions{
-			PreStartRequired: true,
+			PreStartRequired:                true,
+			GetPreferredAllocationAvailable: true,
 		},
 	})
 	return err
 }
 
-func (p *DevicePluginServer) Serve(stop <-chan struct{}) {
-	_ = os.Remove(path.Join(v1beta1.DevicePluginPath, p.Endpoint))
-	listener, err := net.Listen("unix", path.Join(v1beta1.DevicePluginPath, p.Endpoint))
+func (p *DevicePluginServer) Serve() error {
+	_ = os.Remove(p.socket())
+	listener, err := net.Listen("unix", p.socket())
 	if err != nil {
-		panic(err)
+		return err
 	}
+	listener = &resourceListener{Listener: listener, addr: resourceAddr(p.ResourceName)}
 
-	server := grpc.NewServer()
-	v1beta1.RegisterDevicePluginServer(server, p.DevicePluginServer)
+	exit := make(chan error, 1)
 	go func() {
-		if err := server.Serve(listener); err != nil {
-			panic(err)
-		}
+		err := p.Server.Serve(listener)
 		klog.Infof("plugin %s exit", p.ResourceName)
+		exit <- err
 	}()
 
-	go func() {
-		<-stop
-		server.GracefulStop()
+	p.lock.Lock()
+	p.listener = listener
+	p.exit = exit
+	p.lock.Unlock()
+	return nil
+}
+
+// Stop close listener of this resource, it is safe to call Stop on a stopped server
+func (p *DevicePluginServer) Stop() {
+	p.lock.Lock()
+	listener := p.listener
+	p.listener = nil
+	p.lock.Unlock()
+	if listener != nil {
 		listener.Close()
-	}()
+	}
+	_ = os.Remove(p.socket())
+}
+
+// Exited return a channel which receive grpc serve error
+func (p *DevicePluginServer) Exited() <-chan error {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.exit
 }
 
 func (p *DevicePluginServer) Wait() error {
-	time.Sleep(time.Second)
-	conn, err := grpc.Dial(path.Join(v1beta1.DevicePluginPath, p.Endpoint), grpc.WithInsecure(), grpc.WithBlock(),
+	conn, err := grpc.Dial(p.socket(), grpc.WithInsecure(), grpc.WithBlock(),
 		grpc.WithTimeout(time.Second*5),
 		grpc.WithDialer(func(addr string, timeout time.Duration) (net.Conn, error) {
 			return net.DialTimeout("unix", addr, timeout)
@@ -115,6 +168,15 @@ func (p *DevicePluginServer) Wait() error {
 	return conn.Close()
 }
 
+// Healthy check plugin socket still exist, kubelet will clean all sockets in
+// device plugin path when it restart
+func (p *DevicePluginServer) Healthy() error {
+	if _, err := os.Stat(p.socket()); err != nil {
+		return fmt.Errorf("plugin socket %s lost: %v", p.socket(), err)
+	}
+	return nil
+}
+
 func NewFSWatcher(files ...string) (*fsnotify.Watcher, error) {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -131,3 +193,76 @@ func NewFSWatcher(files ...string) (*fsnotify.Watcher, error) {
 
 	return watcher, nil
 }
+
+// resourceAddr is the remote address of connections accepted by resource
+// listener, grpc handler get it from peer to know which resource is called
+type resourceAddr string
+
+func (a resourceAddr) Network() string { return "unix" }
+func (a resourceAddr) String() string  { return string(a) }
+
+// resourceListener track accepted connections, so that closing it will close
+// every connection of the resource but leave the shared grpc server serving
+type resourceListener struct {
+	net.Listener
+	addr resourceAddr
+
+	lock  sync.Mutex
+	conns map[*resourceConn]struct{}
+}
+
+func (l *resourceListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	rc := &resourceConn{Conn: conn, listener: l}
+	l.lock.Lock()
+	if l.conns == nil {
+		l.conns = make(map[*resourceConn]struct{})
+	}
+	l.conns[rc] = struct{}{}
+	l.lock.Unlock()
+	return rc, nil
+}
+
+func (l *resourceListener) Close() error {
+	err := l.Listener.Close()
+	l.lock.Lock()
+	conns := l.conns
+	l.conns = nil
+	l.lock.Unlock()
+	for c := range conns {
+		c.Conn.Close()
+	}
+	return err
+}
+
+type resourceConn struct {
+	net.Conn
+	listener *resourceListener
+}
+
+func (c *resourceConn) RemoteAddr() net.Addr {
+	return c.listener.addr
+}
+
+func (c *resourceConn) Close() error {
+	c.listener.lock.Lock()
+	delete(c.listener.conns, c)
+	c.listener.lock.Unlock()
+	return c.Conn.Close()
+}
+
+// resourceFromContext return resource name of grpc request
+func resourceFromContext(ctx context.Context) (v1.ResourceName, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return "", fmt.Errorf("no peer found in request context")
+	}
+	addr, ok := p.Addr.(resourceAddr)
+	if !ok {
+		return "", fmt.Errorf("unknown peer address %s", p.Addr)
+	}
+	return v1.ResourceName(addr), nil
+}
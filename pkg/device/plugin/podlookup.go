@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"context"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/metrics"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+const (
+	DefaultPodWaitTimeout   = 2 * time.Second
+	DefaultPodWaitInterval  = 100 * time.Millisecond
+	DefaultAPIServerTimeout = 5 * time.Second
+
+	lookupSourceInformer      = "informer"
+	lookupSourceInformerRetry = "informer_retry"
+	lookupSourceAPIServer     = "apiserver"
+)
+
+// PodLookupConfig bound how long PreStartContainer waits for the bind annotation
+// written by scheduler extender, zero value means default
+type PodLookupConfig struct {
+	// PodWaitTimeout is how long informer is polled, negative means no retry
+	PodWaitTimeout time.Duration
+	// PodWaitInterval is the interval of informer polling
+	PodWaitInterval time.Duration
+	// APIServerTimeout is timeout of the final api server read, negative
+	// means never fall back to api server
+	APIServerTimeout time.Duration
+}
+
+func (c *PodLookupConfig) setDefaults() {
+	if c.PodWaitTimeout == 0 {
+		c.PodWaitTimeout = DefaultPodWaitTimeout
+	}
+	if c.PodWaitInterval <= 0 {
+		c.PodWaitInterval = DefaultPodWaitInterval
+	}
+	if c.APIServerTimeout == 0 {
+		c.APIServerTimeout = DefaultAPIServerTimeout
+	}
+}
+
+func hasBindAnnotation(pod *v1.Pod) bool {
+	_, ok := pod.Annotations[common.AnnotationPodBindToBoard]
+	return ok
+}
+
+// getBoundPod get pod with bind annotation, informer may not see the annotation
+// written by extender Bind moments ago, so it re-reads informer for a while and
+// then falls back to api server. The latest pod seen is returned if none of them
+// has the annotation
+func (odp *OrinDevicePlugin) getBoundPod(curr *types.PodContainer) (*v1.Pod, error) {
+	pod, err := odp.Sitter.GetPod(curr.Namespace, curr.Name)
+	if err == nil && hasBindAnnotation(pod) {
+		metrics.PodLookupTotal.WithLabelValues(lookupSourceInformer, metrics.ResultSuccess).Inc()
+		return pod, nil
+	}
+	metrics.PodLookupTotal.WithLabelValues(lookupSourceInformer, metrics.ResultError).Inc()
+
+	if odp.PodWaitTimeout > 0 {
+		start := time.Now()
+		_ = wait.PollImmediate(odp.PodWaitInterval, odp.PodWaitTimeout, func() (bool, error) {
+			if p, e := odp.Sitter.GetPod(curr.Namespace, curr.Name); e == nil {
+				pod, err = p, nil
+				return hasBindAnnotation(p), nil
+			}
+			return false, nil
+		})
+		if err == nil && hasBindAnnotation(pod) {
+			klog.V(4).InfoS("pod bind annotation found by informer retry", "pod", curr, "wait", time.Since(start))
+			metrics.PodLookupTotal.WithLabelValues(lookupSourceInformerRetry, metrics.ResultSuccess).Inc()
+			return pod, nil
+		}
+		metrics.PodLookupTotal.WithLabelValues(lookupSourceInformerRetry, metrics.ResultError).Inc()
+	}
+
+	if odp.APIServerTimeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), odp.APIServerTimeout)
+		defer cancel()
+		p, e := odp.Sitter.GetPodFromApiServer(ctx, curr.Namespace, curr.Name)
+		metrics.PodLookupTotal.WithLabelValues(lookupSourceAPIServer, metrics.Result(e)).Inc()
+		if e != nil {
+			klog.ErrorS(e, "get pod from api server error", "pod", curr)
+		} else {
+			klog.InfoS("pod read from api server, informer is behind", "pod", curr, "annotated", hasBindAnnotation(p))
+			pod, err = p, nil
+		}
+	}
+	return pod, err
+}
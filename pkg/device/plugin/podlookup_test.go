@@ -0,0 +1,138 @@
+package plugin
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// fakeSitter serve pods from a map, informer pod gets annotated after
+// annotateAfter calls of GetPod
+type fakeSitter struct {
+	lock          sync.Mutex
+	informerPod   *v1.Pod
+	apiServerPod  *v1.Pod
+	annotateAfter int
+	gets          int
+	apiServerGets int
+	node          *v1.Node
+	pods          []*v1.Pod
+}
+
+func (f *fakeSitter) Start() {}
+
+func (f *fakeSitter) GetPod(namespace, name string) (*v1.Pod, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.gets++
+	if f.informerPod == nil {
+		return nil, errors.NewNotFound(v1.Resource("pods"), name)
+	}
+	pod := f.informerPod.DeepCopy()
+	if f.annotateAfter > 0 && f.gets >= f.annotateAfter {
+		pod.Annotations = map[string]string{common.AnnotationPodBindToBoard: "1"}
+	}
+	return pod, nil
+}
+
+func (f *fakeSitter) GetPodByUID(uid string) (*v1.Pod, error) {
+	return nil, errors.NewNotFound(v1.Resource("pods"), uid)
+}
+
+func (f *fakeSitter) ListPods() ([]*v1.Pod, error) {
+	return f.pods, nil
+}
+
+func (f *fakeSitter) GetPodFromApiServer(ctx context.Context, namespace, name string) (*v1.Pod, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.apiServerGets++
+	if f.apiServerPod == nil {
+		return nil, errors.NewNotFound(v1.Resource("pods"), name)
+	}
+	return f.apiServerPod, nil
+}
+
+func (f *fakeSitter) GetNodeFromApiServer(name string) (*v1.Node, error) {
+	if f.node == nil {
+		return nil, errors.NewNotFound(v1.Resource("nodes"), name)
+	}
+	return f.node, nil
+}
+
+func (f *fakeSitter) AddPodEventHandler(handler cache.ResourceEventHandler) {}
+func (f *fakeSitter) HasSynced() bool                                       { return true }
+
+func TestGetBoundPod(t *testing.T) {
+	plain := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "p1"}}
+	annotated := plain.DeepCopy()
+	annotated.Annotations = map[string]string{common.AnnotationPodBindToBoard: "1"}
+	config := PodLookupConfig{PodWaitTimeout: 50 * time.Millisecond, PodWaitInterval: time.Millisecond, APIServerTimeout: time.Second}
+
+	tests := []struct {
+		name            string
+		sitter          *fakeSitter
+		config          PodLookupConfig
+		expectAnnotated bool
+		expectErr       bool
+		expectAPIServer int
+	}{
+		{
+			name:            "informer has annotation",
+			sitter:          &fakeSitter{informerPod: annotated},
+			config:          config,
+			expectAnnotated: true,
+		},
+		{
+			name:            "informer catches up in retry",
+			sitter:          &fakeSitter{informerPod: plain, annotateAfter: 3},
+			config:          config,
+			expectAnnotated: true,
+		},
+		{
+			name:            "fall back to api server",
+			sitter:          &fakeSitter{informerPod: plain, apiServerPod: annotated},
+			config:          config,
+			expectAnnotated: true,
+			expectAPIServer: 1,
+		},
+		{
+			name:            "api server fallback disabled",
+			sitter:          &fakeSitter{informerPod: plain, apiServerPod: annotated},
+			config:          PodLookupConfig{PodWaitTimeout: -1, APIServerTimeout: -1},
+			expectAnnotated: false,
+		},
+		{
+			name:            "pod not found anywhere",
+			sitter:          &fakeSitter{},
+			config:          config,
+			expectErr:       true,
+			expectAPIServer: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			odp := &OrinDevicePlugin{OrinDeviceConfig: &OrinDeviceConfig{Sitter: tt.sitter, PodLookupConfig: tt.config}}
+			odp.PodLookupConfig.setDefaults()
+			pod, err := odp.getBoundPod(&types.PodContainer{Namespace: "default", Name: "p1"})
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("getBoundPod() error = %v, expectErr %v", err, tt.expectErr)
+			}
+			if err == nil && hasBindAnnotation(pod) != tt.expectAnnotated {
+				t.Errorf("getBoundPod() annotated = %v, expect %v", hasBindAnnotation(pod), tt.expectAnnotated)
+			}
+			if tt.sitter.apiServerGets != tt.expectAPIServer {
+				t.Errorf("api server called %d times, expect %d", tt.sitter.apiServerGets, tt.expectAPIServer)
+			}
+		})
+	}
+}
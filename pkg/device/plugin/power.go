@@ -0,0 +1,278 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/metrics"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+	"github.com/superedge/orin-device-system/pkg/scheduler/manager"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+const (
+	ReasonBoardPoweredOff  = "BoardPoweredOff"
+	ReasonBoardPoweredOn   = "BoardPoweredOn"
+	ReasonBoardPowerFailed = "BoardPowerFailed"
+
+	DefaultPowerSyncPeriod = 5 * time.Second
+	// powerCallTimeout bound a power switch, power on waits for socs booting
+	powerCallTimeout = 10 * time.Minute
+)
+
+// recoverPower load board power states from node annotation, socs of boards
+// not powered on stay unhealthy until woken
+func (odp *OrinDevicePlugin) recoverPower() {
+	states := map[int]string{}
+	if node, err := odp.Sitter.GetNodeFromApiServer(odp.NodeName); err != nil {
+		klog.ErrorS(err, "get node for board power error, assume every board powered on")
+	} else {
+		states = manager.ParseBoardPower(node)
+	}
+	now := time.Now()
+	odp.powerLock.Lock()
+	off := []int{}
+	for _, boardID := range odp.DeviceProvider.GetBoards() {
+		odp.idleSince[boardID] = now
+		odp.powerState[boardID] = common.BoardPowerOn
+		// a wake interrupted by restart is requested again by its annotation
+		if state, ok := states[boardID]; ok && state != common.BoardPowerOn {
+			odp.powerState[boardID] = common.BoardPowerOff
+			off = append(off, boardID)
+		}
+	}
+	odp.powerLock.Unlock()
+	for _, boardID := range off {
+		odp.setBoardHealth(boardID, v1beta1.Unhealthy)
+	}
+	klog.InfoS("board power recovered", "poweredOff", off)
+}
+
+// busyBoards return boards bound by pods not terminated on node, a pod bound
+// but not started yet keeps its board busy too
+func (odp *OrinDevicePlugin) busyBoards() (sets.Int, error) {
+	pods, err := odp.Sitter.ListPods()
+	if err != nil {
+		return nil, err
+	}
+	res := sets.NewInt()
+	for _, pod := range pods {
+		if podTerminated(pod) {
+			continue
+		}
+		for boardID := range manager.PodBoardOrins(pod) {
+			res.Insert(boardID)
+		}
+	}
+	return res, nil
+}
+
+// wakeRequests return boards which scheduler extender asks to power on
+func wakeRequests(node *v1.Node) sets.Int {
+	res := sets.NewInt()
+	for k := range node.Annotations {
+		if !strings.HasPrefix(k, common.AnnotationNodeOrinWakeBoardPrefix) {
+			continue
+		}
+		boardID, err := strconv.Atoi(strings.TrimPrefix(k, common.AnnotationNodeOrinWakeBoardPrefix))
+		if err != nil {
+			klog.ErrorS(err, "invalid wake annotation", "annotation", k)
+			continue
+		}
+		res.Insert(boardID)
+	}
+	return res
+}
+
+// syncPower wake boards requested by scheduler extender, and power off boards
+// idle longer than PowerIdleTimeout
+func (odp *OrinDevicePlugin) syncPower() {
+	node, err := odp.Sitter.GetNodeFromApiServer(odp.NodeName)
+	if err != nil {
+		klog.ErrorS(err, "get node for board power error")
+		return
+	}
+	busy, err := odp.busyBoards()
+	if err != nil {
+		klog.ErrorS(err, "list pods for board power error")
+		return
+	}
+	wakes := wakeRequests(node)
+	now := time.Now()
+	toWake, toOff, woken := []int{}, []int{}, []int{}
+	odp.powerLock.Lock()
+	for _, boardID := range odp.DeviceProvider.GetBoards() {
+		if busy.Has(boardID) || wakes.Has(boardID) {
+			odp.idleSince[boardID] = now
+		}
+		state := odp.powerState[boardID]
+		switch {
+		case wakes.Has(boardID) && state == common.BoardPowerOff:
+			odp.powerState[boardID] = common.BoardPowerWaking
+			toWake = append(toWake, boardID)
+		case wakes.Has(boardID) && state == common.BoardPowerOn:
+			woken = append(woken, boardID)
+		case state == common.BoardPowerOn && now.Sub(odp.idleSince[boardID]) >= odp.PowerIdleTimeout:
+			odp.powerState[boardID] = common.BoardPowerPoweringOff
+			toOff = append(toOff, boardID)
+		}
+	}
+	odp.powerLock.Unlock()
+
+	if len(toOff) != 0 {
+		// scheduler extender must see the boards powering off before they are
+		// switched off, a pod bound before that is found by powerOff
+		if err := odp.publishPower(); err != nil {
+			for _, boardID := range toOff {
+				odp.keepPowerOn(boardID)
+			}
+			toOff = nil
+		}
+	}
+	// a power switch may take minutes, a board powering off or waking is not
+	// picked again by sync until its switch finished
+	for _, boardID := range toOff {
+		go odp.powerOff(boardID)
+	}
+	for _, boardID := range toWake {
+		go odp.wake(boardID)
+	}
+	odp.publishPower(woken...)
+}
+
+// powerOff mark socs of board unhealthy before switching power off, so kubelet
+// stops allocating them, and abort if a pod is bound to the board while its
+// powering off state is not seen by scheduler extender yet
+func (odp *OrinDevicePlugin) powerOff(boardID int) {
+	odp.setBoardHealth(boardID, v1beta1.Unhealthy)
+	busy, err := odp.busyBoards()
+	if err != nil || busy.Has(boardID) {
+		klog.InfoS("board is busy or unknown, power off aborted", "board", boardID, "err", err)
+		odp.keepPowerOn(boardID)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), powerCallTimeout)
+	defer cancel()
+	if err := odp.power.PowerOff(ctx, boardID); err != nil {
+		klog.ErrorS(err, "power off board error", "board", boardID)
+		odp.event(odp.nodeRef(), v1.EventTypeWarning, ReasonBoardPowerFailed, fmt.Sprintf("power off board %d failed: %v", boardID, err))
+		odp.keepPowerOn(boardID)
+		return
+	}
+	odp.powerLock.Lock()
+	odp.powerState[boardID] = common.BoardPowerOff
+	odp.powerLock.Unlock()
+	klog.InfoS("board powered off", "board", boardID, "idleTimeout", odp.PowerIdleTimeout)
+	odp.event(odp.nodeRef(), v1.EventTypeNormal, ReasonBoardPoweredOff, fmt.Sprintf("board %d idle for %s, powered off", boardID, odp.PowerIdleTimeout))
+}
+
+// keepPowerOn revert a board powering off to powered on and restart its idle
+// timer, its state is published in the next sync
+func (odp *OrinDevicePlugin) keepPowerOn(boardID int) {
+	odp.powerLock.Lock()
+	odp.powerState[boardID] = common.BoardPowerOn
+	odp.idleSince[boardID] = time.Now()
+	odp.powerLock.Unlock()
+	odp.setBoardHealth(boardID, v1beta1.Healthy)
+}
+
+// wake power on board and mark its socs healthy, the wake request is kept on
+// failure so the next sync tries again
+func (odp *OrinDevicePlugin) wake(boardID int) {
+	odp.publishPower()
+	ctx, cancel := context.WithTimeout(context.Background(), powerCallTimeout)
+	defer cancel()
+	err := odp.power.PowerOn(ctx, boardID)
+	odp.powerLock.Lock()
+	if err != nil {
+		odp.powerState[boardID] = common.BoardPowerOff
+	} else {
+		odp.powerState[boardID] = common.BoardPowerOn
+		odp.idleSince[boardID] = time.Now()
+	}
+	odp.powerLock.Unlock()
+	if err != nil {
+		klog.ErrorS(err, "power on board error", "board", boardID)
+		odp.event(odp.nodeRef(), v1.EventTypeWarning, ReasonBoardPowerFailed, fmt.Sprintf("power on board %d failed: %v", boardID, err))
+		odp.publishPower()
+		return
+	}
+	odp.setBoardHealth(boardID, v1beta1.Healthy)
+	klog.InfoS("board powered on", "board", boardID)
+	odp.event(odp.nodeRef(), v1.EventTypeNormal, ReasonBoardPoweredOn, fmt.Sprintf("board %d powered on by wake request", boardID))
+	odp.publishPower(boardID)
+}
+
+// setBoardHealth set health of every soc on board, quarantined socs and socs
+// reported unhealthy by agent stay unhealthy
+func (odp *OrinDevicePlugin) setBoardHealth(boardID int, health string) {
+	odp.quarantineLock.Lock()
+	defer odp.quarantineLock.Unlock()
+	for _, orinID := range odp.DeviceProvider.GetBoardOrins(boardID) {
+		id := types.OrinDeviceID(boardID, orinID)
+		if health == v1beta1.Healthy && (odp.quarantined.Has(id) || !odp.agentHealthy(id)) {
+			continue
+		}
+		odp.Health.SetHealth(id, health)
+	}
+}
+
+// boardPoweredOff return true if the board of device is not powered on
+func (odp *OrinDevicePlugin) boardPoweredOff(deviceID string) bool {
+	boardID, _, err := types.ParseOrinDeviceID(deviceID)
+	if err != nil {
+		return false
+	}
+	odp.powerLock.Lock()
+	defer odp.powerLock.Unlock()
+	state, ok := odp.powerState[boardID]
+	return ok && state != common.BoardPowerOn
+}
+
+// publishPower write board power states to node annotation if changed, and
+// remove wake requests of woken boards, publishes are serialized so that an
+// older snapshot never overwrites a newer one
+func (odp *OrinDevicePlugin) publishPower(woken ...int) error {
+	odp.publishLock.Lock()
+	defer odp.publishLock.Unlock()
+	odp.powerLock.Lock()
+	states := make(map[string]string, len(odp.powerState))
+	for boardID, state := range odp.powerState {
+		states[strconv.Itoa(boardID)] = state
+		powered := 0.0
+		if state == common.BoardPowerOn {
+			powered = 1
+		}
+		metrics.BoardPowered.WithLabelValues(strconv.Itoa(boardID)).Set(powered)
+	}
+	odp.powerLock.Unlock()
+	data, _ := json.Marshal(states)
+	if (string(data) == odp.powerPublished && len(woken) == 0) || odp.ClientSet == nil {
+		return nil
+	}
+	annotations := map[string]interface{}{common.AnnotationNodeOrinBoardPower: string(data)}
+	for _, boardID := range woken {
+		annotations[manager.WakeBoardAnnotation(boardID)] = nil
+	}
+	patch, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if _, err := odp.ClientSet.CoreV1().Nodes().Patch(context.TODO(), odp.NodeName, k8stypes.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		// published again in next sync
+		klog.ErrorS(err, "patch node board power annotation error")
+		return err
+	}
+	odp.powerPublished = string(data)
+	return nil
+}
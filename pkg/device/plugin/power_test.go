@@ -0,0 +1,205 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/provider"
+	"github.com/superedge/orin-device-system/pkg/scheduler/manager"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// fakePower record power switches, fail power on if failOn set, and block
+// power off until offBlock closed if set
+type fakePower struct {
+	lock     sync.Mutex
+	calls    []string
+	failOn   bool
+	offBlock chan struct{}
+}
+
+func (f *fakePower) PowerSupported() bool { return true }
+
+func (f *fakePower) PowerOn(ctx context.Context, boardID int) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.calls = append(f.calls, fmt.Sprintf("on-%d", boardID))
+	if f.failOn {
+		return fmt.Errorf("board %d not responding", boardID)
+	}
+	return nil
+}
+
+func (f *fakePower) PowerOff(ctx context.Context, boardID int) error {
+	if f.offBlock != nil {
+		<-f.offBlock
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.calls = append(f.calls, fmt.Sprintf("off-%d", boardID))
+	return nil
+}
+
+func (f *fakePower) Calls() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]string{}, f.calls...)
+}
+
+func newPowerTestPlugin(node *v1.Node, pods []*v1.Pod, power *fakePower) *OrinDevicePlugin {
+	p := &provider.FileDeviceProvider{FileDevice: &provider.OrinFileDevice{BoardDevices: []*provider.Device{
+		{ID: 1, OrinSocs: []*provider.OrinSoc{{ID: 1}, {ID: 2}}},
+		{ID: 2, OrinSocs: []*provider.OrinSoc{{ID: 1}}},
+	}}}
+	return &OrinDevicePlugin{
+		OrinDeviceConfig: &OrinDeviceConfig{
+			DeviceProvider:   p,
+			Sitter:           &fakeSitter{node: node, pods: pods},
+			Recorder:         record.NewFakeRecorder(10),
+			NodeName:         "node1",
+			PowerIdleTimeout: time.Millisecond,
+		},
+		Health:      NewHealthView("1-1", "1-2", "2-1"),
+		quarantined: sets.NewString(),
+		power:       power,
+		powerState:  make(map[int]string),
+		idleSince:   make(map[int]time.Time),
+	}
+}
+
+func TestSyncPowerIdle(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	pods := []*v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "running", Annotations: map[string]string{common.AnnotationPodBindToBoard: "1"}}},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "finished", Annotations: map[string]string{common.AnnotationPodBindToBoard: "2"}},
+			Status:     v1.PodStatus{Phase: v1.PodSucceeded},
+		},
+	}
+	power := &fakePower{}
+	odp := newPowerTestPlugin(node, pods, power)
+	odp.recoverPower()
+	time.Sleep(2 * time.Millisecond)
+	odp.syncPower()
+
+	if err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+		return odp.boardPoweredOff("2-1") && len(power.Calls()) != 0, nil
+	}); err != nil {
+		t.Fatalf("expect board 2 powered off")
+	}
+	if calls := power.Calls(); len(calls) != 1 || calls[0] != "off-2" {
+		t.Fatalf("expect only idle board 2 powered off, actual %v", calls)
+	}
+	if odp.Health.Health("2-1") != v1beta1.Unhealthy || odp.Health.Health("1-1") != v1beta1.Healthy {
+		t.Fatalf("expect socs of powered off board unhealthy")
+	}
+	if !odp.boardPoweredOff("2-1") || odp.boardPoweredOff("1-1") {
+		t.Fatalf("expect board 2 powered off in state")
+	}
+}
+
+func TestSyncPowerWake(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Annotations: map[string]string{
+		common.AnnotationNodeOrinBoardPower: `{"1":"on","2":"off"}`,
+	}}}
+	power := &fakePower{failOn: true}
+	odp := newPowerTestPlugin(node, nil, power)
+	odp.PowerIdleTimeout = time.Hour
+	odp.recoverPower()
+	if odp.Health.Health("2-1") != v1beta1.Unhealthy {
+		t.Fatalf("expect socs of board powered off before restart unhealthy")
+	}
+
+	node.Annotations[manager.WakeBoardAnnotation(2)] = time.Now().Format(time.RFC3339)
+	waitPowerState := func(state string) {
+		err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+			odp.powerLock.Lock()
+			defer odp.powerLock.Unlock()
+			return odp.powerState[2] == state, nil
+		})
+		if err != nil {
+			t.Fatalf("expect board 2 %s, actual %s", state, odp.powerState[2])
+		}
+	}
+	// failed wake is tried again in next sync
+	odp.syncPower()
+	waitPowerState(common.BoardPowerOff)
+	if odp.Health.Health("2-1") != v1beta1.Unhealthy {
+		t.Fatalf("expect socs unhealthy after failed wake")
+	}
+
+	power.lock.Lock()
+	power.failOn = false
+	power.lock.Unlock()
+	odp.syncPower()
+	waitPowerState(common.BoardPowerOn)
+	if err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+		return odp.Health.Health("2-1") == v1beta1.Healthy, nil
+	}); err != nil {
+		t.Fatalf("expect socs healthy after wake")
+	}
+	if calls := power.Calls(); len(calls) != 2 || calls[1] != "on-2" {
+		t.Fatalf("expect board 2 powered on twice, actual %v", calls)
+	}
+}
+
+func TestPowerOffBusy(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	power := &fakePower{}
+	odp := newPowerTestPlugin(node, nil, power)
+	odp.recoverPower()
+	odp.powerState[2] = common.BoardPowerPoweringOff
+	if !odp.boardPoweredOff("2-1") {
+		t.Fatalf("expect board powering off treated as powered off")
+	}
+
+	// a pod is bound to the board before scheduler sees it powering off
+	odp.Sitter.(*fakeSitter).pods = []*v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "late", Annotations: map[string]string{common.AnnotationPodBindToBoard: "2"}}},
+	}
+	odp.powerOff(2)
+	if calls := power.Calls(); len(calls) != 0 {
+		t.Fatalf("expect power off of busy board aborted, actual %v", calls)
+	}
+	if odp.boardPoweredOff("2-1") || odp.Health.Health("2-1") != v1beta1.Healthy {
+		t.Fatalf("expect board 2 kept powered on")
+	}
+}
+
+func TestPowerOffNotBlockWake(t *testing.T) {
+	node := &v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", Annotations: map[string]string{
+		common.AnnotationNodeOrinBoardPower: `{"1":"off","2":"on"}`,
+	}}}
+	power := &fakePower{offBlock: make(chan struct{})}
+	defer close(power.offBlock)
+	odp := newPowerTestPlugin(node, nil, power)
+	odp.recoverPower()
+	time.Sleep(2 * time.Millisecond)
+
+	// board 2 is idle and its power off hangs, board 1 is asked to wake
+	node.Annotations[manager.WakeBoardAnnotation(1)] = time.Now().Format(time.RFC3339)
+	odp.syncPower()
+	if err := wait.PollImmediate(time.Millisecond, time.Second, func() (bool, error) {
+		return !odp.boardPoweredOff("1-1"), nil
+	}); err != nil {
+		t.Fatalf("expect board 1 woken while board 2 powering off, calls %v", power.Calls())
+	}
+	// the board powering off is not picked again
+	odp.syncPower()
+	odp.powerLock.Lock()
+	state := odp.powerState[2]
+	odp.powerLock.Unlock()
+	if state != common.BoardPowerPoweringOff {
+		t.Fatalf("expect board 2 powering off, actual %s", state)
+	}
+}
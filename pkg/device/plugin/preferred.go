@@ -0,0 +1,134 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+	"github.com/superedge/orin-device-system/pkg/scheduler/manager"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+// GetPreferredAllocation prefer devices on the boards and orins the scheduler
+// extender bound the pod to, the request has no pod, so it is the oldest
+// pending pod whose devices are available
+func (odp *OrinDevicePlugin) GetPreferredAllocation(ctx context.Context, request *v1beta1.PreferredAllocationRequest) (*v1beta1.PreferredAllocationResponse, error) {
+	r, err := odp.resource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := odp.Sitter.ListPods()
+	if err != nil {
+		klog.ErrorS(err, "list pods for preferred allocation error")
+		pods = nil
+	}
+	// kubelet admits pods one by one in creation order
+	sort.Slice(pods, func(i, j int) bool {
+		if !pods[i].CreationTimestamp.Equal(&pods[j].CreationTimestamp) {
+			return pods[i].CreationTimestamp.Before(&pods[j].CreationTimestamp)
+		}
+		return pods[i].Name < pods[j].Name
+	})
+	resp := &v1beta1.PreferredAllocationResponse{}
+	for _, req := range request.ContainerRequests {
+		devices := odp.preferredDevices(r, pods, req)
+		klog.V(4).InfoS("preferred allocation", "resource", r.ResourceName, "available", req.AvailableDeviceIDs, "size", req.AllocationSize, "preferred", devices)
+		resp.ContainerResponses = append(resp.ContainerResponses, &v1beta1.ContainerPreferredAllocationResponse{DeviceIDs: devices})
+	}
+	return resp, nil
+}
+
+// preferredDevices return devices of the first pending pod which fill the
+// request, nil lets kubelet choose
+func (odp *OrinDevicePlugin) preferredDevices(r *orinResource, pods []*v1.Pod, req *v1beta1.ContainerPreferredAllocationRequest) []string {
+	available := sets.NewString(req.AvailableDeviceIDs...)
+	must := sets.NewString(req.MustIncludeDeviceIDs...)
+	size := int(req.AllocationSize)
+	for _, pod := range pods {
+		if pod.Status.Phase != v1.PodPending || podTerminated(pod) {
+			continue
+		}
+		wanted := odp.wantedDevices(r, pod)
+		if len(wanted) == 0 {
+			continue
+		}
+		res := must.List()
+		for _, id := range wanted {
+			if len(res) >= size {
+				break
+			}
+			if available.Has(id) && !must.Has(id) {
+				res = append(res, id)
+			}
+		}
+		if len(res) == size {
+			return res
+		}
+	}
+	return nil
+}
+
+// wantedDevices return devices of resource on the boards pod is bound to
+func (odp *OrinDevicePlugin) wantedDevices(r *orinResource, pod *v1.Pod) []string {
+	if r.Any {
+		return podDevices(manager.PodCountOrins(pod))
+	}
+	boardID, err := strconv.Atoi(pod.Annotations[common.AnnotationPodBindToBoard])
+	if err != nil {
+		return nil
+	}
+	if !manager.BuildRequestOrinSet(pod).Has(r.OrinID) {
+		return nil
+	}
+	return []string{types.OrinDeviceID(boardID, r.OrinID)}
+}
+
+// injectedDevices return devices injected for devices of resource, devices
+// of any orin resource are the orins on the bound boards chosen by scheduler
+// extender, it fails if kubelet did not take the preference, as the socs the
+// container would use are not the devices kubelet accounted for it
+func (odp *OrinDevicePlugin) injectedDevices(r *orinResource, pod *v1.Pod, devicesIDs []string) ([]string, error) {
+	if !r.Any {
+		boardID, _ := strconv.Atoi(pod.Annotations[common.AnnotationPodBindToBoard])
+		return []string{types.OrinDeviceID(boardID, r.OrinID)}, nil
+	}
+	chosen := sets.NewString(podDevices(manager.PodCountOrins(pod))...)
+	if !chosen.HasAll(devicesIDs...) {
+		return nil, fmt.Errorf("devices %v are not the orins %v chosen by scheduler extender", devicesIDs, chosen.List())
+	}
+	return sets.NewString(devicesIDs...).List(), nil
+}
+
+// podDevices return sorted devices of orins on every board
+func podDevices(boards map[int]sets.Int) []string {
+	res := []string{}
+	for boardID, orins := range boards {
+		for _, orinID := range orins.List() {
+			res = append(res, types.OrinDeviceID(boardID, orinID))
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+// socsAttrs return attrs of every soc device, the config of any orin resource
+func (odp *OrinDevicePlugin) socsAttrs(deviceIDs []string) []map[string]interface{} {
+	res := []map[string]interface{}{}
+	for _, id := range deviceIDs {
+		boardID, orinID, err := types.ParseOrinDeviceID(id)
+		if err != nil {
+			continue
+		}
+		if attrs := odp.orinAttrs(boardID, orinID); len(attrs) != 0 {
+			res = append(res, attrs)
+		}
+	}
+	return res
+}
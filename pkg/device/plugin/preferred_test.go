@@ -0,0 +1,137 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+func preferredPod(name, resourceName, quantity string, annotations map[string]string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Annotations: annotations},
+		Spec: v1.PodSpec{Containers: []v1.Container{{
+			Name: "c",
+			Resources: v1.ResourceRequirements{Limits: v1.ResourceList{
+				v1.ResourceName(resourceName): resource.MustParse(quantity),
+			}},
+		}}},
+		Status: v1.PodStatus{Phase: v1.PodPending},
+	}
+}
+
+func TestPreferredDevices(t *testing.T) {
+	orin1 := &orinResource{OrinID: 1, ResourceName: "superedge.io/device-orin-1", DeviceIDs: []string{"1-1", "2-1"}}
+	anyOrin := &orinResource{ResourceName: common.ExtendResouceTypeOrin, DeviceIDs: []string{"1-1", "1-2", "1-3", "2-1", "2-2", "2-3"}, Any: true}
+	onBoard2 := preferredPod("p1", "superedge.io/device-orin-1", "1", map[string]string{common.AnnotationPodBindToBoard: "2"})
+	count := preferredPod("p2", common.ExtendResouceTypeOrin, "2", map[string]string{
+		common.AnnotationPodBindToBoard: "1",
+		common.AnnotationPodBindOrins:   "2,3",
+	})
+	multiBoard := preferredPod("p3", common.ExtendResouceTypeOrin, "3", map[string]string{
+		common.AnnotationPodBindToBoard: "1",
+		common.AnnotationPodBindBoards:  `{"1":[1,2],"2":[3]}`,
+	})
+	running := onBoard2.DeepCopy()
+	running.Status.Phase = v1.PodRunning
+
+	testCases := []struct {
+		name   string
+		r      *orinResource
+		pods   []*v1.Pod
+		req    *v1beta1.ContainerPreferredAllocationRequest
+		expect []string
+	}{
+		{
+			name:   "orin-N on bound board",
+			r:      orin1,
+			pods:   []*v1.Pod{count, onBoard2},
+			req:    &v1beta1.ContainerPreferredAllocationRequest{AvailableDeviceIDs: []string{"1-1", "2-1"}, AllocationSize: 1},
+			expect: []string{"2-1"},
+		},
+		{
+			name:   "count on chosen orins",
+			r:      anyOrin,
+			pods:   []*v1.Pod{onBoard2, count},
+			req:    &v1beta1.ContainerPreferredAllocationRequest{AvailableDeviceIDs: anyOrin.DeviceIDs, AllocationSize: 2},
+			expect: []string{"1-2", "1-3"},
+		},
+		{
+			name:   "count with must include",
+			r:      anyOrin,
+			pods:   []*v1.Pod{count},
+			req:    &v1beta1.ContainerPreferredAllocationRequest{AvailableDeviceIDs: anyOrin.DeviceIDs, MustIncludeDeviceIDs: []string{"1-3"}, AllocationSize: 2},
+			expect: []string{"1-3", "1-2"},
+		},
+		{
+			name:   "multi board on bound boards",
+			r:      anyOrin,
+			pods:   []*v1.Pod{multiBoard},
+			req:    &v1beta1.ContainerPreferredAllocationRequest{AvailableDeviceIDs: anyOrin.DeviceIDs, AllocationSize: 3},
+			expect: []string{"1-1", "1-2", "2-3"},
+		},
+		{
+			name: "bound device unavailable",
+			r:    orin1,
+			pods: []*v1.Pod{onBoard2},
+			req:  &v1beta1.ContainerPreferredAllocationRequest{AvailableDeviceIDs: []string{"1-1"}, AllocationSize: 1},
+		},
+		{
+			name: "no pending pod",
+			r:    orin1,
+			pods: []*v1.Pod{running},
+			req:  &v1beta1.ContainerPreferredAllocationRequest{AvailableDeviceIDs: []string{"1-1", "2-1"}, AllocationSize: 1},
+		},
+	}
+	odp := &OrinDevicePlugin{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := odp.preferredDevices(tc.r, tc.pods, tc.req)
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("expect %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestInjectedDevices(t *testing.T) {
+	anyOrin := &orinResource{ResourceName: common.ExtendResouceTypeOrin, Any: true}
+	pod := preferredPod("p", common.ExtendResouceTypeOrin, "2", map[string]string{
+		common.AnnotationPodBindToBoard: "1",
+		common.AnnotationPodBindOrins:   "2,3",
+	})
+	multiBoard := preferredPod("p", common.ExtendResouceTypeOrin, "3", map[string]string{
+		common.AnnotationPodBindToBoard: "1",
+		common.AnnotationPodBindBoards:  `{"1":[1,2],"2":[1]}`,
+	})
+	testCases := []struct {
+		name    string
+		r       *orinResource
+		pod     *v1.Pod
+		devices []string
+		expect  []string
+		err     bool
+	}{
+		{name: "orin-N", r: &orinResource{OrinID: 1}, pod: pod, devices: []string{"2-1"}, expect: []string{"1-1"}},
+		{name: "preferred taken", r: anyOrin, pod: pod, devices: []string{"1-3", "1-2"}, expect: []string{"1-2", "1-3"}},
+		{name: "preferred ignored", r: anyOrin, pod: pod, devices: []string{"2-1", "1-2"}, err: true},
+		{name: "multi board", r: anyOrin, pod: multiBoard, devices: []string{"2-1", "1-2", "1-1"}, expect: []string{"1-1", "1-2", "2-1"}},
+	}
+	odp := &OrinDevicePlugin{}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := odp.injectedDevices(tc.r, tc.pod, tc.devices)
+			if (err != nil) != tc.err {
+				t.Fatalf("expect error %v, got %v", tc.err, err)
+			}
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Errorf("expect %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
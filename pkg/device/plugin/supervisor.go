@@ -0,0 +1,219 @@
+package plugin
+
+import (
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/superedge/orin-device-system/pkg/device/metrics"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+	"k8s.io/kubelet/pkg/apis/deviceplugin/v1beta1"
+)
+
+const (
+	DefaultBackoffInitial = time.Second
+	DefaultBackoffMax     = 2 * time.Minute
+	DefaultCheckPeriod    = 30 * time.Second
+)
+
+// supervisedServer is a plugin server managed by Supervisor
+type supervisedServer interface {
+	Start() error
+	Stop()
+	Exited() <-chan error
+	Healthy() error
+	setState(state ServerState, err error)
+	name() string
+}
+
+// Supervisor manage every plugin server, restart it with exponential backoff
+// when serve or register fail, and re-register it when kubelet restart
+type Supervisor struct {
+	BackoffInitial time.Duration
+	BackoffMax     time.Duration
+	// CheckPeriod is the interval to check plugin socket and kubelet socket
+	// in case of fsnotify event lost
+	CheckPeriod time.Duration
+	// PluginPath is watched for KubeletSocket, which kubelet recreate when
+	// it restart
+	PluginPath    string
+	KubeletSocket string
+
+	servers []supervisedServer
+
+	lock     sync.Mutex
+	restarts []chan struct{}
+}
+
+func NewSupervisor(servers ...*DevicePluginServer) *Supervisor {
+	supervised := make([]supervisedServer, 0, len(servers))
+	for _, p := range servers {
+		supervised = append(supervised, p)
+	}
+	return newSupervisor(supervised...)
+}
+
+func newSupervisor(servers ...supervisedServer) *Supervisor {
+	return &Supervisor{
+		BackoffInitial: DefaultBackoffInitial,
+		BackoffMax:     DefaultBackoffMax,
+		CheckPeriod:    DefaultCheckPeriod,
+		PluginPath:     v1beta1.DevicePluginPath,
+		KubeletSocket:  v1beta1.KubeletSocket,
+		servers:        servers,
+	}
+}
+
+func (s *Supervisor) Run(stop <-chan struct{}) {
+	for _, p := range s.servers {
+		restart := make(chan struct{}, 1)
+		s.lock.Lock()
+		s.restarts = append(s.restarts, restart)
+		s.lock.Unlock()
+		klog.InfoS("start plugin", "name", p.name())
+		go s.supervise(p, restart, stop)
+	}
+	go s.watchKubelet(stop)
+}
+
+func (s *Supervisor) newBackoff() wait.Backoff {
+	return wait.Backoff{
+		Duration: s.BackoffInitial,
+		Factor:   2,
+		Jitter:   0.1,
+		Steps:    math.MaxInt32,
+		Cap:      s.BackoffMax,
+	}
+}
+
+// restartAll notify every plugin server to restart, it never block
+func (s *Supervisor) restartAll() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for _, r := range s.restarts {
+		select {
+		case r <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (s *Supervisor) supervise(p supervisedServer, restart <-chan struct{}, stop <-chan struct{}) {
+	backoff := s.newBackoff()
+	ticker := time.NewTicker(s.CheckPeriod)
+	defer ticker.Stop()
+	for {
+		if err := p.Start(); err != nil {
+			delay := backoff.Step()
+			klog.ErrorS(err, "start plugin server error, retry later", "resource", p.name(), "delay", delay)
+			p.setState(ServerStateBackoff, err)
+			select {
+			case <-time.After(delay):
+				continue
+			case <-restart:
+				// kubelet socket changed, retry immediately
+				backoff = s.newBackoff()
+				continue
+			case <-stop:
+				p.Stop()
+				p.setState(ServerStateStopped, nil)
+				return
+			}
+		}
+		backoff = s.newBackoff()
+		klog.InfoS("plugin server registered", "resource", p.name())
+
+	running:
+		for {
+			select {
+			case <-restart:
+				klog.InfoS("kubelet restarted, re-register plugin", "resource", p.name())
+				break running
+			case err := <-p.Exited():
+				klog.ErrorS(err, "plugin server exited unexpectedly", "resource", p.name())
+				break running
+			case <-ticker.C:
+				if err := p.Healthy(); err != nil {
+					klog.ErrorS(err, "plugin server is unhealthy", "resource", p.name())
+					break running
+				}
+			case <-stop:
+				p.Stop()
+				p.setState(ServerStateStopped, nil)
+				return
+			}
+		}
+		p.Stop()
+	}
+}
+
+// watchKubelet watch kubelet socket, kubelet recreate its socket and remove
+// all plugin sockets when restart
+func (s *Supervisor) watchKubelet(stop <-chan struct{}) {
+	backoff := s.newBackoff()
+	var watcher *fsnotify.Watcher
+	for {
+		var err error
+		if watcher, err = NewFSWatcher(s.PluginPath); err == nil {
+			break
+		}
+		delay := backoff.Step()
+		klog.ErrorS(err, "create fswatch failed, retry later", "path", s.PluginPath, "delay", delay)
+		select {
+		case <-time.After(delay):
+		case <-stop:
+			return
+		}
+	}
+	defer watcher.Close()
+
+	ticker := time.NewTicker(s.CheckPeriod)
+	defer ticker.Stop()
+	lastKubelet := kubeletSocketIdentity(s.KubeletSocket)
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Name == s.KubeletSocket && event.Op&(fsnotify.Create|fsnotify.Remove) != 0 {
+				klog.InfoS("inotify kubelet socket event, restarting", "socket", s.KubeletSocket, "op", event.Op.String())
+				lastKubelet = kubeletSocketIdentity(s.KubeletSocket)
+				if event.Op&fsnotify.Create != 0 {
+					metrics.KubeletRestartTotal.Inc()
+				}
+				// plugin will fail to register until kubelet socket created,
+				// backoff will take care of it
+				s.restartAll()
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			klog.ErrorS(err, "fswatch error", "path", s.PluginPath)
+		case <-ticker.C:
+			// event may be lost, compare kubelet socket identity periodically
+			if curr := kubeletSocketIdentity(s.KubeletSocket); !curr.Equal(lastKubelet) {
+				klog.InfoS("kubelet socket changed, restarting", "socket", s.KubeletSocket)
+				lastKubelet = curr
+				metrics.KubeletRestartTotal.Inc()
+				s.restartAll()
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// kubeletSocketIdentity return modify time of kubelet socket, which change
+// every time kubelet recreate it
+func kubeletSocketIdentity(socket string) time.Time {
+	info, err := os.Stat(socket)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,158 @@
+package plugin
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// fakeServer fail to start failures times, then start and run until stopped
+type fakeServer struct {
+	lock     sync.Mutex
+	failures int
+	starts   []time.Time
+	stops    int
+	states   []ServerState
+	exit     chan error
+}
+
+func newFakeServer(failures int) *fakeServer {
+	return &fakeServer{failures: failures, exit: make(chan error, 1)}
+}
+
+func (f *fakeServer) Start() error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.starts = append(f.starts, time.Now())
+	if f.failures > 0 {
+		f.failures--
+		return fmt.Errorf("register to kubelet failed")
+	}
+	return nil
+}
+
+func (f *fakeServer) Stop() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.stops++
+}
+
+func (f *fakeServer) Exited() <-chan error { return f.exit }
+
+func (f *fakeServer) Healthy() error { return nil }
+
+func (f *fakeServer) setState(state ServerState, err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.states = append(f.states, state)
+}
+
+func (f *fakeServer) name() string { return "fake" }
+
+func (f *fakeServer) startCount() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return len(f.starts)
+}
+
+func waitStarts(t *testing.T, f *fakeServer, n int) {
+	if err := wait.PollImmediate(5*time.Millisecond, 5*time.Second, func() (bool, error) { return f.startCount() >= n, nil }); err != nil {
+		t.Fatalf("expect %d starts, actual %d", n, f.startCount())
+	}
+}
+
+func testSupervisor(dir string, servers ...supervisedServer) *Supervisor {
+	s := newSupervisor(servers...)
+	s.BackoffInitial = 20 * time.Millisecond
+	s.BackoffMax = 80 * time.Millisecond
+	s.CheckPeriod = time.Hour
+	s.PluginPath = dir
+	s.KubeletSocket = path.Join(dir, "kubelet.sock")
+	return s
+}
+
+func TestSupervisorBackoff(t *testing.T) {
+	f := newFakeServer(4)
+	s := testSupervisor(t.TempDir(), f)
+	stop := make(chan struct{})
+	s.Run(stop)
+	waitStarts(t, f, 5)
+	close(stop)
+
+	// delay double from initial until max, jitter only adds to it
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	expected := []time.Duration{20 * time.Millisecond, 40 * time.Millisecond, 80 * time.Millisecond, 80 * time.Millisecond}
+	for i, min := range expected {
+		if delay := f.starts[i+1].Sub(f.starts[i]); delay < min {
+			t.Errorf("expect retry %d after at least %v, actual %v", i+1, min, delay)
+		}
+	}
+	if f.states[0] != ServerStateBackoff {
+		t.Errorf("expect server in backoff after start failure, actual %v", f.states)
+	}
+}
+
+func TestSupervisorRestartAll(t *testing.T) {
+	servers := []*fakeServer{newFakeServer(0), newFakeServer(0)}
+	s := testSupervisor(t.TempDir(), servers[0], servers[1])
+	stop := make(chan struct{})
+	defer close(stop)
+	s.Run(stop)
+	for _, f := range servers {
+		waitStarts(t, f, 1)
+	}
+
+	// restartAll never block, pending restarts are merged
+	for i := 0; i < 10; i++ {
+		s.restartAll()
+	}
+	for _, f := range servers {
+		waitStarts(t, f, 2)
+	}
+
+	// server exited is restarted too
+	servers[0].exit <- fmt.Errorf("serve error")
+	waitStarts(t, servers[0], 3)
+	servers[0].lock.Lock()
+	defer servers[0].lock.Unlock()
+	if servers[0].stops < 2 {
+		t.Errorf("expect server stopped before restart, actual %d stops", servers[0].stops)
+	}
+}
+
+func TestSupervisorWatchKubelet(t *testing.T) {
+	dir := t.TempDir()
+	f := newFakeServer(0)
+	s := testSupervisor(dir, f)
+	stop := make(chan struct{})
+	defer close(stop)
+	s.Run(stop)
+	waitStarts(t, f, 1)
+	// wait the watcher added
+	time.Sleep(50 * time.Millisecond)
+
+	// kubelet restart remove and recreate its socket
+	if err := os.WriteFile(s.KubeletSocket, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	waitStarts(t, f, 2)
+	if err := os.Remove(s.KubeletSocket); err != nil {
+		t.Fatal(err)
+	}
+	waitStarts(t, f, 3)
+
+	// other plugin sockets are ignored
+	if err := os.WriteFile(path.Join(dir, "other.sock"), nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := f.startCount(); n != 3 {
+		t.Errorf("expect no restart for other sockets, actual %d starts", n)
+	}
+}
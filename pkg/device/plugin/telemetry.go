@@ -0,0 +1,211 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/agent/api"
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/metrics"
+	"github.com/superedge/orin-device-system/pkg/device/provider"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+	"github.com/superedge/orin-device-system/pkg/scheduler/manager"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/klog/v2"
+)
+
+const (
+	TelemetrySourceAgent = "agent"
+	TelemetrySourceHTTP  = "http"
+
+	DefaultTelemetryPeriod = time.Minute
+	telemetryCallTimeout   = 5 * time.Second
+	// telemetryIPPlaceholder in TelemetryURL is replaced by soc ip
+	telemetryIPPlaceholder = "{ip}"
+)
+
+// TelemetryConfig collect utilization, temperature and power of every soc
+type TelemetryConfig struct {
+	// TelemetryPeriod is the period of collecting, zero means disable
+	TelemetryPeriod time.Duration
+	// TelemetrySource is agent or http, agent needs AgentPort
+	TelemetrySource string
+	// TelemetryURL is the http endpoint serving api.Telemetry json on every
+	// soc, e.g. http://{ip}:9421/telemetry
+	TelemetryURL string
+	// TelemetryAnnotation publish a compact summary to node annotation for
+	// the scheduler
+	TelemetryAnnotation bool
+}
+
+// telemetrySource get telemetry of one soc by its ip
+type telemetrySource func(ctx context.Context, id, ip string) (*api.Telemetry, error)
+
+func (odp *OrinDevicePlugin) telemetrySource() (telemetrySource, error) {
+	switch odp.TelemetrySource {
+	case TelemetrySourceAgent, "":
+		if odp.AgentPort <= 0 {
+			return nil, fmt.Errorf("telemetry source %s needs agent port", TelemetrySourceAgent)
+		}
+		return odp.agentTelemetry, nil
+	case TelemetrySourceHTTP:
+		if !strings.Contains(odp.TelemetryURL, telemetryIPPlaceholder) {
+			return nil, fmt.Errorf("telemetry url %q has no %s", odp.TelemetryURL, telemetryIPPlaceholder)
+		}
+		return odp.httpTelemetry, nil
+	default:
+		return nil, fmt.Errorf("unknown telemetry source %q", odp.TelemetrySource)
+	}
+}
+
+func (odp *OrinDevicePlugin) agentTelemetry(ctx context.Context, id, ip string) (*api.Telemetry, error) {
+	a, err := odp.agent(id, net.JoinHostPort(ip, strconv.Itoa(odp.AgentPort)))
+	if err != nil {
+		return nil, err
+	}
+	return a.client.GetTelemetry(ctx, &api.Empty{})
+}
+
+func (odp *OrinDevicePlugin) httpTelemetry(ctx context.Context, id, ip string) (*api.Telemetry, error) {
+	url := strings.ReplaceAll(odp.TelemetryURL, telemetryIPPlaceholder, ip)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("get %s: %s", url, res.Status)
+	}
+	t := &api.Telemetry{}
+	if err := json.NewDecoder(res.Body).Decode(t); err != nil {
+		return nil, fmt.Errorf("decode %s: %v", url, err)
+	}
+	return t, nil
+}
+
+// collectTelemetry get telemetry of every powered on soc, export it as
+// metrics and publish the summary to node
+func (odp *OrinDevicePlugin) collectTelemetry(source telemetrySource) {
+	owners := map[string]string{}
+	pods, err := odp.DeviceLocator.List()
+	if err != nil {
+		klog.ErrorS(err, "list pod devices for telemetry error")
+	}
+	for _, pi := range pods {
+		for _, id := range pi.DeviceIDs() {
+			owners[id] = pi.Namespace + "/" + pi.Name
+		}
+	}
+
+	lock := sync.Mutex{}
+	collected := map[string]*api.Telemetry{}
+	wg := sync.WaitGroup{}
+	for _, boardID := range odp.DeviceProvider.GetBoards() {
+		for _, orinID := range odp.DeviceProvider.GetBoardOrins(boardID) {
+			id := types.OrinDeviceID(boardID, orinID)
+			ip, _ := odp.DeviceProvider.GetOrinAttrs(boardID, orinID)[provider.AttrKeyOrinIp].(string)
+			if ip == "" || odp.boardPoweredOff(id) {
+				continue
+			}
+			wg.Add(1)
+			go func(id, ip string) {
+				defer wg.Done()
+				ctx, cancel := context.WithTimeout(context.Background(), telemetryCallTimeout)
+				defer cancel()
+				t, err := source(ctx, id, ip)
+				if err != nil {
+					klog.V(4).InfoS("collect soc telemetry error", "device", id, "ip", ip, "err", err)
+					return
+				}
+				lock.Lock()
+				collected[id] = t
+				lock.Unlock()
+			}(id, ip)
+		}
+	}
+	wg.Wait()
+
+	metrics.ResetTelemetry()
+	summary := make(map[string]*manager.SocTelemetry, len(collected))
+	for id, t := range collected {
+		boardID, orinID, _ := types.ParseOrinDeviceID(id)
+		deviceNum, _ := odp.DeviceProvider.GetBoardAttrs(boardID)[provider.AttrKeyBoardDeviceNum].(string)
+		soc, _ := odp.DeviceProvider.GetOrinAttrs(boardID, orinID)[provider.AttrKeyOrinName].(string)
+		labels := metrics.TelemetryLabels(deviceNum, soc, owners[id])
+		metrics.SocCPUUtilization.With(labels).Set(t.CPUPercent)
+		metrics.SocGPUUtilization.With(labels).Set(t.GPUPercent)
+		metrics.SocMemoryUsed.With(labels).Set(float64(t.MemoryUsedBytes))
+		metrics.SocMemoryTotal.With(labels).Set(float64(t.MemoryTotalBytes))
+		for dla, v := range t.DLAPercent {
+			metrics.SocDLAUtilization.With(withLabel(labels, "dla", dla)).Set(v)
+		}
+		for zone, v := range t.TemperatureCelsius {
+			metrics.SocTemperature.With(withLabel(labels, "zone", zone)).Set(v)
+		}
+		for rail, v := range t.PowerMilliwatts {
+			metrics.SocPower.With(withLabel(labels, "rail", rail)).Set(v)
+		}
+		summary[id] = summarize(t)
+	}
+	if odp.TelemetryAnnotation {
+		odp.publishTelemetry(summary)
+	}
+}
+
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	res := make(map[string]string, len(labels)+1)
+	for k, v := range labels {
+		res[k] = v
+	}
+	res[name] = value
+	return res
+}
+
+// summarize round telemetry to the compact summary in node annotation
+func summarize(t *api.Telemetry) *manager.SocTelemetry {
+	s := &manager.SocTelemetry{GPU: int(math.Round(t.GPUPercent))}
+	if t.MemoryTotalBytes > 0 {
+		s.Memory = int(math.Round(float64(t.MemoryUsedBytes) * 100 / float64(t.MemoryTotalBytes)))
+	}
+	var temp, power float64
+	for _, v := range t.TemperatureCelsius {
+		temp = math.Max(temp, v)
+	}
+	for _, v := range t.PowerMilliwatts {
+		power += v
+	}
+	s.Temperature, s.Power = int(math.Round(temp)), int(math.Round(power))
+	return s
+}
+
+// publishTelemetry patch telemetry summary to node if it changed
+func (odp *OrinDevicePlugin) publishTelemetry(summary map[string]*manager.SocTelemetry) {
+	data, _ := json.Marshal(summary)
+	if string(data) == odp.telemetryPublished || odp.ClientSet == nil {
+		return
+	}
+	patch, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{common.AnnotationNodeOrinTelemetry: string(data)},
+		},
+	})
+	if _, err := odp.ClientSet.CoreV1().Nodes().Patch(context.TODO(), odp.NodeName, k8stypes.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		klog.ErrorS(err, "patch node telemetry annotation error")
+		return
+	}
+	odp.telemetryPublished = string(data)
+}
@@ -0,0 +1,106 @@
+package plugin
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/superedge/orin-device-system/pkg/agent/api"
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/device/metrics"
+	"github.com/superedge/orin-device-system/pkg/device/provider"
+	"github.com/superedge/orin-device-system/pkg/device/types"
+	"github.com/superedge/orin-device-system/pkg/scheduler/manager"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollectTelemetry(t *testing.T) {
+	// stand-in of the telemetry endpoint on soc
+	telemetry := &api.Telemetry{
+		CPUPercent:         12.5,
+		GPUPercent:         80.4,
+		MemoryUsedBytes:    8 << 30,
+		MemoryTotalBytes:   32 << 30,
+		DLAPercent:         map[string]float64{"dla0": 30},
+		TemperatureCelsius: map[string]float64{"cpu-thermal": 55.2, "gpu-thermal": 61.5},
+		PowerMilliwatts:    map[string]float64{"VDD_GPU_SOC": 6000, "VDD_CPU_CV": 2500.4},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(telemetry)
+	}))
+	defer server.Close()
+	_, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+
+	// board 2 is powered off, soc 1-2 has no ip
+	p := &provider.FileDeviceProvider{FileDevice: &provider.OrinFileDevice{BoardDevices: []*provider.Device{
+		{ID: 1, DeviceNum: "board-1", OrinSocs: []*provider.OrinSoc{{ID: 1, Name: "soc-1", IP: "127.0.0.1"}, {ID: 2, Name: "soc-2"}}},
+		{ID: 2, DeviceNum: "board-2", OrinSocs: []*provider.OrinSoc{{ID: 1, Name: "soc-1", IP: "127.0.0.1"}}},
+	}}}
+	pi := types.NewPI("default", "p1")
+	pi.AddDevice("c1", types.NewDevice([]string{"1-1"}, common.ExtendResouceTypeOrinPrefix+"1"))
+	odp := &OrinDevicePlugin{
+		OrinDeviceConfig: &OrinDeviceConfig{
+			DeviceProvider: p,
+			DeviceLocator:  &listLocator{pods: []*types.PodInfo{pi}},
+			TelemetryConfig: TelemetryConfig{
+				TelemetrySource: TelemetrySourceHTTP,
+				TelemetryURL:    "http://{ip}:" + port + "/telemetry",
+			},
+		},
+		powerState: map[int]string{2: common.BoardPowerOff},
+	}
+	source, err := odp.telemetrySource()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// stale soc of last collecting is removed
+	metrics.SocGPUUtilization.With(metrics.TelemetryLabels("board-2", "soc-1", "")).Set(1)
+	odp.collectTelemetry(source)
+
+	labels := metrics.TelemetryLabels("board-1", "soc-1", "default/p1")
+	if n := testutil.CollectAndCount(metrics.SocGPUUtilization); n != 1 {
+		t.Fatalf("expect gpu utilization of one soc, actual %d", n)
+	}
+	if v := testutil.ToFloat64(metrics.SocGPUUtilization.With(labels)); v != 80.4 {
+		t.Errorf("expect gpu utilization 80.4, actual %v", v)
+	}
+	if v := testutil.ToFloat64(metrics.SocTemperature.With(withLabel(labels, "zone", "gpu-thermal"))); v != 61.5 {
+		t.Errorf("expect gpu temperature 61.5, actual %v", v)
+	}
+	if v := testutil.ToFloat64(metrics.SocDLAUtilization.With(withLabel(labels, "dla", "dla0"))); v != 30 {
+		t.Errorf("expect dla0 utilization 30, actual %v", v)
+	}
+
+	expect := &manager.SocTelemetry{GPU: 80, Memory: 25, Temperature: 62, Power: 8500}
+	if s := summarize(telemetry); !reflect.DeepEqual(s, expect) {
+		t.Errorf("expect summary %+v, actual %+v", expect, s)
+	}
+}
+
+func TestTelemetrySource(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  TelemetryConfig
+		agent   int
+		wantErr bool
+	}{
+		{name: "agent", config: TelemetryConfig{TelemetrySource: TelemetrySourceAgent}, agent: api.DefaultPort},
+		{name: "agent without port", config: TelemetryConfig{TelemetrySource: TelemetrySourceAgent}, wantErr: true},
+		{name: "http", config: TelemetryConfig{TelemetrySource: TelemetrySourceHTTP, TelemetryURL: "http://{ip}:9421/telemetry"}},
+		{name: "http without ip", config: TelemetryConfig{TelemetrySource: TelemetrySourceHTTP, TelemetryURL: "http://soc:9421"}, wantErr: true},
+		{name: "unknown", config: TelemetryConfig{TelemetrySource: "snmp"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			odp := &OrinDevicePlugin{OrinDeviceConfig: &OrinDeviceConfig{AgentPort: tt.agent, TelemetryConfig: tt.config}}
+			if _, err := odp.telemetrySource(); (err != nil) != tt.wantErr {
+				t.Errorf("telemetrySource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
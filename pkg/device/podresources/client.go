@@ -26,6 +26,7 @@ import (
 
 	"github.com/superedge/orin-device-system/pkg/device/podresources/v1alpha1"
 	"k8s.io/klog/v2"
+	podresourcesv1 "k8s.io/kubelet/pkg/apis/podresources/v1"
 
 	"google.golang.org/grpc"
 )
@@ -59,6 +60,23 @@ func GetClient(socket string, connectionTimeout time.Duration, maxMsgSize int) (
 	return v1alpha1.NewPodResourcesListerClient(conn), conn, nil
 }
 
+// GetV1Client returns a client of the v1 kubelet pod resources api, which
+// is served by kubelet since k8s 1.20
+func GetV1Client(socket string, connectionTimeout time.Duration, maxMsgSize int) (podresourcesv1.PodResourcesListerClient, *grpc.ClientConn, error) {
+	addr, dialer, err := GetAddressAndDialer(socket)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), connectionTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, addr, grpc.WithInsecure(), grpc.WithDialer(dialer), grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(maxMsgSize)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("error dialing socket %s: %v", socket, err)
+	}
+	return podresourcesv1.NewPodResourcesListerClient(conn), conn, nil
+}
+
 func GetAddressAndDialer(endpoint string) (string, func(addr string, timeout time.Duration) (net.Conn, error), error) {
 	protocol, addr, err := parseEndpointWithFallbackProtocol(endpoint, unixProtocol)
 	if err != nil {
@@ -1,6 +1,7 @@
 package provider
 
 import (
+	"fmt"
 	"io/ioutil"
 
 	"gopkg.in/yaml.v3"
@@ -18,6 +19,8 @@ func (f *OrinFileDeviceFactory) Create(config string) (DeviceProvider, error) {
 type OrinFileDevice struct {
 	NucIP        string    `yaml:"nuc_ip"`
 	BoardDevices []*Device `yaml:"device"`
+	// Power switch board power, nil means unsupported
+	Power *PowerConfig `yaml:"power"`
 }
 
 type Device struct {
@@ -28,6 +31,8 @@ type Device struct {
 	Lidar       bool       `yaml:"lidar"`
 	Camera      string     `yaml:"camera"`
 	OrinSocs    []*OrinSoc `yaml:"socs"`
+	// Links is the interconnect between socs of the board
+	Links []*OrinLink `yaml:"links"`
 }
 
 type OrinSoc struct {
@@ -36,6 +41,33 @@ type OrinSoc struct {
 	IP   string `yaml:"ip"`
 }
 
+// OrinLink is a link between two socs, socs without a link talk through the
+// default network
+type OrinLink struct {
+	Socs          []int `yaml:"socs"`
+	BandwidthMbps int   `yaml:"bandwidth_mbps"`
+	LatencyMicros int   `yaml:"latency_us"`
+}
+
+func (d *Device) validateLinks() error {
+	socs := sets.NewInt()
+	for _, s := range d.OrinSocs {
+		socs.Insert(s.ID)
+	}
+	for _, l := range d.Links {
+		if len(l.Socs) != 2 || l.Socs[0] == l.Socs[1] {
+			return fmt.Errorf("link of board %d must have two different socs, actual %v", d.ID, l.Socs)
+		}
+		if !socs.HasAll(l.Socs...) {
+			return fmt.Errorf("link %v of board %d has unknown soc", l.Socs, d.ID)
+		}
+		if l.BandwidthMbps <= 0 || l.LatencyMicros < 0 {
+			return fmt.Errorf("link %v of board %d must have positive bandwidth and non-negative latency", l.Socs, d.ID)
+		}
+	}
+	return nil
+}
+
 type FileDeviceProvider struct {
 	FilePath   string
 	FileDevice *OrinFileDevice
@@ -52,6 +84,16 @@ func NewFileDeviceProvider(filePath string) (*FileDeviceProvider, error) {
 	if err := yaml.Unmarshal(yamlData, fod); err != nil {
 		return nil, err
 	}
+	if fod.Power != nil {
+		if err := fod.Power.validate(); err != nil {
+			return nil, err
+		}
+	}
+	for _, b := range fod.BoardDevices {
+		if err := b.validateLinks(); err != nil {
+			return nil, err
+		}
+	}
 	return &FileDeviceProvider{FilePath: filePath, FileDevice: fod}, nil
 }
 
@@ -119,3 +161,15 @@ func (fp *FileDeviceProvider) GetBoardOrins(boardID int) []int {
 	}
 	return res
 }
+
+func (fp *FileDeviceProvider) GetBoardLinks(boardID int) []Link {
+	res := []Link{}
+	for _, b := range fp.FileDevice.BoardDevices {
+		if b.ID == boardID {
+			for _, l := range b.Links {
+				res = append(res, Link{Socs: [2]int{l.Socs[0], l.Socs[1]}, BandwidthMbps: l.BandwidthMbps, LatencyMicros: l.LatencyMicros})
+			}
+		}
+	}
+	return res
+}
@@ -1,6 +1,10 @@
 package provider
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 
@@ -33,3 +37,109 @@ func TestGetOrinClasses(t *testing.T) {
 		}
 	}
 }
+
+func TestFilePower(t *testing.T) {
+	dir := t.TempDir()
+	config := filepath.Join(dir, "device.yaml")
+	content := fmt.Sprintf(`
+device:
+- id: 1
+  device_num: car-1
+  socs:
+  - id: 1
+power:
+  on:
+    exec: ["sh", "-c", "echo {{.Board.device_num}} > %s/on-{{.BoardID}}"]
+  off:
+    exec: ["sh", "-c", "exit 1"]
+`, dir)
+	if err := os.WriteFile(config, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fp, err := NewFileDeviceProvider(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pc := GetPowerController(fp)
+	if pc == nil {
+		t.Fatalf("expect power supported")
+	}
+	if err := pc.PowerOn(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "on-1")); err != nil || string(data) != "car-1\n" {
+		t.Fatalf("expect power on rendered with board, actual %q, %v", data, err)
+	}
+	if err := pc.PowerOff(context.Background(), 1); err == nil {
+		t.Fatalf("expect power off error")
+	}
+
+	if GetPowerController(&FileDeviceProvider{FileDevice: &OrinFileDevice{}}) != nil {
+		t.Fatalf("expect power unsupported without config")
+	}
+}
+
+func TestFileLinks(t *testing.T) {
+	testcases := []struct {
+		name        string
+		links       string
+		expected    []Link
+		expectError bool
+	}{
+		{
+			name:     "1.no links",
+			expected: []Link{},
+		},
+		{
+			name: "2.links",
+			links: `
+  links:
+  - socs: [1, 2]
+    bandwidth_mbps: 10000
+    latency_us: 5
+  - socs: [2, 3]
+    bandwidth_mbps: 1000`,
+			expected: []Link{{Socs: [2]int{1, 2}, BandwidthMbps: 10000, LatencyMicros: 5}, {Socs: [2]int{2, 3}, BandwidthMbps: 1000}},
+		},
+		{
+			name: "3.unknown soc",
+			links: `
+  links:
+  - socs: [1, 4]
+    bandwidth_mbps: 1000`,
+			expectError: true,
+		},
+		{
+			name: "4.no bandwidth",
+			links: `
+  links:
+  - socs: [1, 2]`,
+			expectError: true,
+		},
+	}
+	dir := t.TempDir()
+	for i, tc := range testcases {
+		config := filepath.Join(dir, fmt.Sprintf("device-%d.yaml", i))
+		content := `
+device:
+- id: 1
+  socs:
+  - id: 1
+  - id: 2
+  - id: 3` + tc.links + "\n"
+		if err := os.WriteFile(config, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		fp, err := NewFileDeviceProvider(config)
+		if (err != nil) != tc.expectError {
+			t.Errorf("test case %s, expect error %v, actual %v", tc.name, tc.expectError, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if actual := fp.GetBoardLinks(1); !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("test case %s, expect %v, actual %v", tc.name, tc.expected, actual)
+		}
+	}
+}
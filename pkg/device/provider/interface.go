@@ -1,6 +1,10 @@
 package provider
 
-import "k8s.io/apimachinery/pkg/util/sets"
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
 
 const (
 	AttrKeyBoardDeviceNum   = "device_num"
@@ -11,6 +15,12 @@ const (
 
 	AttrKeyOrinIp   = "ip"
 	AttrKeyOrinName = "name"
+
+	// soc attrs reported by the agent on soc
+	AttrKeyOrinBoardSerial    = "board_serial"
+	AttrKeyOrinHostname       = "hostname"
+	AttrKeyOrinL4TVersion     = "l4t_version"
+	AttrKeyOrinJetPackVersion = "jetpack_version"
 )
 
 var ProviderMap = map[string]DeviceProviderFactory{FileDeviceProviderName: &OrinFileDeviceFactory{}}
@@ -33,3 +43,34 @@ type DeviceProvider interface {
 	GetBoards() []int
 	GetBoardOrins(boardID int) []int
 }
+
+// PowerController is the optional capability of DeviceProvider switching board
+// power, PowerOn return after socs of board are up
+type PowerController interface {
+	PowerSupported() bool
+	PowerOn(ctx context.Context, boardID int) error
+	PowerOff(ctx context.Context, boardID int) error
+}
+
+// Link is a direct interconnect between two socs of one board
+type Link struct {
+	Socs          [2]int
+	BandwidthMbps int
+	LatencyMicros int
+}
+
+// InterconnectProvider is the optional capability of DeviceProvider
+// describing links between socs of one board, like a shared pcie switch
+type InterconnectProvider interface {
+	GetBoardLinks(boardID int) []Link
+}
+
+// GetPowerController return power controller of provider, nil if provider can
+// not switch board power
+func GetPowerController(p DeviceProvider) PowerController {
+	pc, ok := p.(PowerController)
+	if !ok || !pc.PowerSupported() {
+		return nil
+	}
+	return pc
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/superedge/orin-device-system/pkg/device/hook"
+)
+
+// PowerConfig is the exec commands or http calls switching board power, they
+// are rendered with hook.Data of the board, power on must not return before
+// socs of the board are up
+type PowerConfig struct {
+	On  *hook.Hook `yaml:"on"`
+	Off *hook.Hook `yaml:"off"`
+}
+
+func (c *PowerConfig) validate() error {
+	if c.On == nil || c.Off == nil {
+		return fmt.Errorf("power must have both on and off")
+	}
+	if c.On.Name == "" {
+		c.On.Name = "power-on"
+	}
+	if c.Off.Name == "" {
+		c.Off.Name = "power-off"
+	}
+	if err := c.On.Validate(); err != nil {
+		return err
+	}
+	return c.Off.Validate()
+}
+
+func (fp *FileDeviceProvider) PowerSupported() bool {
+	return fp.FileDevice.Power != nil
+}
+
+func (fp *FileDeviceProvider) PowerOn(ctx context.Context, boardID int) error {
+	if fp.FileDevice.Power == nil {
+		return fmt.Errorf("power of provider %s unsupported", fp.Name())
+	}
+	return fp.FileDevice.Power.On.Run(ctx, fp.powerData(boardID))
+}
+
+func (fp *FileDeviceProvider) PowerOff(ctx context.Context, boardID int) error {
+	if fp.FileDevice.Power == nil {
+		return fmt.Errorf("power of provider %s unsupported", fp.Name())
+	}
+	return fp.FileDevice.Power.Off.Run(ctx, fp.powerData(boardID))
+}
+
+func (fp *FileDeviceProvider) powerData(boardID int) *hook.Data {
+	return &hook.Data{BoardID: boardID, Board: fp.GetBoardAttrs(boardID)}
+}
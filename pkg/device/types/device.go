@@ -3,9 +3,12 @@ package types
 import (
 	"crypto/sha256"
 	"encoding/hex"
-	v1 "k8s.io/api/core/v1"
+	"fmt"
 	"sort"
+	"strconv"
 	"strings"
+
+	v1 "k8s.io/api/core/v1"
 )
 
 type Device struct {
@@ -52,3 +55,25 @@ func hash(deviceList []string) string {
 	}
 	return hex.EncodeToString(to(sha256.Sum256([]byte(strings.Join(deviceList, ":")))))[0:8]
 }
+
+// OrinDeviceID build device id which kubelet allocate, format is <board>-<orin>
+func OrinDeviceID(boardID, orinID int) string {
+	return fmt.Sprintf("%d-%d", boardID, orinID)
+}
+
+// ParseOrinDeviceID parse device id like <board>-<orin>
+func ParseOrinDeviceID(id string) (int, int, error) {
+	arr := strings.Split(id, "-")
+	if len(arr) != 2 {
+		return 0, 0, fmt.Errorf("invalid orin device id %s", id)
+	}
+	boardID, err := strconv.Atoi(arr[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid orin device id %s: %v", id, err)
+	}
+	orinID, err := strconv.Atoi(arr[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid orin device id %s: %v", id, err)
+	}
+	return boardID, orinID, nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"path"
+	"sort"
 	"strings"
 )
 
@@ -24,15 +25,19 @@ func (pc *PodContainer) Pod() string {
 type PodInfo struct {
 	Namespace string
 	Name      string
+	// UID is set by checkpoint, empty if unknown
+	UID string `json:",omitempty"`
 
-	ContainerDeviceMap map[string]*Device
+	// ContainerDeviceMap is container name to its devices, one device per
+	// resource name
+	ContainerDeviceMap map[string][]*Device
 }
 
 func NewPI(namespace, name string) *PodInfo {
 	return &PodInfo{
 		Namespace:          namespace,
 		Name:               name,
-		ContainerDeviceMap: map[string]*Device{},
+		ContainerDeviceMap: map[string][]*Device{},
 	}
 }
 
@@ -48,6 +53,30 @@ func NewPIFromRaw(key, val []byte) (*PodInfo, error) {
 	return pi, nil
 }
 
+// AddDevice add device to container, it will replace the device with the same
+// resource name
+func (i *PodInfo) AddDevice(container string, device *Device) {
+	for idx, d := range i.ContainerDeviceMap[container] {
+		if d.ResourceName == device.ResourceName {
+			i.ContainerDeviceMap[container][idx] = device
+			return
+		}
+	}
+	i.ContainerDeviceMap[container] = append(i.ContainerDeviceMap[container], device)
+}
+
+// DeviceIDs return all device ids of pod
+func (i *PodInfo) DeviceIDs() []string {
+	ids := []string{}
+	for _, devices := range i.ContainerDeviceMap {
+		for _, d := range devices {
+			ids = append(ids, d.List...)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
 func (i *PodInfo) Key() []byte {
 	return []byte(path.Join(i.Namespace, i.Name))
 }
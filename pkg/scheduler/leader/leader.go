@@ -0,0 +1,124 @@
+package leader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog/v2"
+)
+
+// same as kube-scheduler
+const (
+	DefaultLeaseDuration = 15 * time.Second
+	DefaultRenewDeadline = 10 * time.Second
+	DefaultRetryPeriod   = 2 * time.Second
+)
+
+// Elector elect one leader of scheduler replicas with a lease. Every replica
+// keeps its cache warm, only the leader binds pods.
+type Elector struct {
+	config leaderelection.LeaderElectionConfig
+
+	mu      sync.RWMutex
+	leading bool
+	leader  string
+}
+
+func NewElector(clientset kubernetes.Interface, namespace, name, identity string) (*Elector, error) {
+	if identity == "" {
+		return nil, fmt.Errorf("leader election identity is empty")
+	}
+	e := &Elector{}
+	e.config = leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta:  metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Client:     clientset.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+		},
+		LeaseDuration:   DefaultLeaseDuration,
+		RenewDeadline:   DefaultRenewDeadline,
+		RetryPeriod:     DefaultRetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            name,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) {
+				klog.InfoS("started leading", "lease", klog.KRef(namespace, name), "identity", identity)
+				e.setLeading(true)
+			},
+			OnStoppedLeading: func() {
+				klog.InfoS("stopped leading", "lease", klog.KRef(namespace, name), "identity", identity)
+				e.setLeading(false)
+			},
+			OnNewLeader: func(leader string) {
+				klog.InfoS("new leader elected", "lease", klog.KRef(namespace, name), "leader", leader)
+				e.mu.Lock()
+				e.leader = leader
+				e.mu.Unlock()
+			},
+		},
+	}
+	if _, err := leaderelection.NewLeaderElector(e.config); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *Elector) setLeading(leading bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.leading = leading
+}
+
+// IsLeader return true if this replica holds the lease
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leading
+}
+
+// Leader return identity of the current leader
+func (e *Elector) Leader() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leader
+}
+
+// Ready return nil if this replica is the leader, it is used for readiness
+// so that service only routes to the leader
+func (e *Elector) Ready() error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if !e.leading {
+		return fmt.Errorf("not the leader, current leader is %q", e.leader)
+	}
+	return nil
+}
+
+// Run campaign for the lease until stopCh is closed, a replica losing the
+// lease stays as standby and campaigns again
+func (e *Elector) Run(stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+	for {
+		le, err := leaderelection.NewLeaderElector(e.config)
+		if err != nil {
+			klog.ErrorS(err, "create leader elector error")
+			return
+		}
+		le.Run(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package leader
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestElector(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+
+	first, err := NewElector(clientset, "kube-system", "orin-device-scheduler", "replica-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Ready() == nil {
+		t.Fatalf("expect not ready before elected")
+	}
+	go first.Run(stopCh)
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) { return first.IsLeader(), nil }); err != nil {
+		t.Fatalf("expect replica-1 elected: %v", err)
+	}
+	if err := first.Ready(); err != nil {
+		t.Fatalf("expect leader ready, actual %v", err)
+	}
+
+	second, err := NewElector(clientset, "kube-system", "orin-device-scheduler", "replica-2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go second.Run(stopCh)
+	// the lease is held by replica-1
+	if err := wait.PollImmediate(10*time.Millisecond, 5*time.Second, func() (bool, error) { return second.Ready() != nil && second.Leader() == "replica-1", nil }); err != nil {
+		t.Fatalf("expect replica-2 standby, actual ready %v", second.Ready())
+	}
+	if second.IsLeader() {
+		t.Fatalf("expect only one leader")
+	}
+
+	if _, err := NewElector(clientset, "kube-system", "orin-device-scheduler", ""); err == nil {
+		t.Fatalf("expect error of empty identity")
+	}
+}
@@ -11,38 +11,46 @@ import (
 const (
 	AllocatorPolicyBinPack = "binpack"
 	AllocatorPolicySpread  = "spread"
+	// AllocatorPolicyTopology choose the orins with the best interconnect
+	AllocatorPolicyTopology = "topology"
 
 	BoardIDNotFount = -1
 )
 
-var (
-	AllocatorMap = map[string]Allocator{AllocatorPolicyBinPack: &BinpackAllocator{}}
-)
-
 type Allocator interface {
-	Allocate(canAlloc topo.BoardDetails, orinRequest sets.Int) *AllocatorResult
+	Allocate(canAlloc topo.BoardDetails, request *OrinRequest) *AllocatorResult
+}
+
+// TopologyAwareAllocator is the optional capability of Allocator choosing
+// orins by the interconnect between orins of a board
+type TopologyAwareAllocator interface {
+	AllocateOnTopology(canAlloc topo.BoardDetails, links topo.Interconnect, request *OrinRequest) *AllocatorResult
 }
 
 type AllocatorResult struct {
 	score   int
 	boardID int
+	// orins is every orin allocated on board
+	orins sets.Int
 }
 
 type BinpackAllocator struct{}
 
-func (ba *BinpackAllocator) Allocate(canAlloc topo.BoardDetails, orinRequest sets.Int) *AllocatorResult {
+func (ba *BinpackAllocator) Allocate(canAlloc topo.BoardDetails, request *OrinRequest) *AllocatorResult {
 	bestFitBoardID := BoardIDNotFount
 	bestFitBoardScore := math.MaxInt
+	var bestFitOrins sets.Int
 	nodeScore := 100
 	// 1. list all predicate board
 	for boardID, orinInfo := range canAlloc {
 		availOrinSet := orinInfo.OrinSet()
-		if availOrinSet.IsSuperset(orinRequest) {
+		if orins, ok := request.Fit(availOrinSet); ok {
 			// caculate fit score, lower is better
 			tmpScore := availOrinSet.Len()
 			if tmpScore < bestFitBoardScore {
 				bestFitBoardScore = tmpScore
 				bestFitBoardID = boardID
+				bestFitOrins = orins
 			}
 		}
 	}
@@ -56,12 +64,118 @@ func (ba *BinpackAllocator) Allocate(canAlloc topo.BoardDetails, orinRequest set
 	} else {
 		nodeScore = 0
 	}
-	return &AllocatorResult{score: nodeScore, boardID: bestFitBoardID}
+	return &AllocatorResult{score: nodeScore, boardID: bestFitBoardID, orins: bestFitOrins}
 }
 
+// SpreadAllocator place pod on the board with the most free orins, and
+// prefer the node with the most free orins, to spread thermal and network load
 type SpreadAllocator struct{}
 
-// TODO implement
-func (sa *SpreadAllocator) Allocate(canAlloc topo.BoardDetails, orinRequest sets.Int) *AllocatorResult {
-	panic("unimplement")
+func (sa *SpreadAllocator) Allocate(canAlloc topo.BoardDetails, request *OrinRequest) *AllocatorResult {
+	bestFitBoardID := BoardIDNotFount
+	bestFitBoardScore := -1
+	var bestFitOrins sets.Int
+	nodeScore := 0
+	// 1. list all predicate board, in order so that equal boards are chosen
+	// stably
+	for _, boardID := range canAlloc.BoardSet().List() {
+		availOrinSet := canAlloc[boardID].OrinSet()
+		if orins, ok := request.Fit(availOrinSet); ok {
+			// caculate fit score, higher is better
+			tmpScore := availOrinSet.Len()
+			if tmpScore > bestFitBoardScore {
+				bestFitBoardScore = tmpScore
+				bestFitBoardID = boardID
+				bestFitOrins = orins
+			}
+		}
+	}
+	// 2. return the most free board, node score is its free orins
+
+	if bestFitBoardID != BoardIDNotFount {
+		for _, orinInfo := range canAlloc {
+			nodeScore += len(orinInfo)
+		}
+	}
+	return &AllocatorResult{score: nodeScore, boardID: bestFitBoardID, orins: bestFitOrins}
+}
+
+// TopologyAllocator choose the orins with the best interconnect on every
+// board for count requests, place pod on the board with the best of them and
+// pack boards like binpack if they are equal
+type TopologyAllocator struct{}
+
+func (ta *TopologyAllocator) Allocate(canAlloc topo.BoardDetails, request *OrinRequest) *AllocatorResult {
+	return ta.AllocateOnTopology(canAlloc, nil, request)
+}
+
+func (ta *TopologyAllocator) AllocateOnTopology(canAlloc topo.BoardDetails, links topo.Interconnect, request *OrinRequest) *AllocatorResult {
+	bestFitBoardID := BoardIDNotFount
+	bestFitBoardFree := math.MaxInt
+	var bestFitConnectivity topo.Connectivity
+	var bestFitOrins sets.Int
+	nodeScore := 100
+	// 1. list all predicate board in order, so that equal boards are chosen
+	// stably
+	for _, boardID := range canAlloc.BoardSet().List() {
+		availOrinSet := canAlloc[boardID].OrinSet()
+		orins, c, ok := bestConnectedOrins(links, boardID, availOrinSet, request)
+		if !ok {
+			continue
+		}
+		if bestFitBoardID == BoardIDNotFount || c.Better(bestFitConnectivity) ||
+			(c == bestFitConnectivity && availOrinSet.Len() < bestFitBoardFree) {
+			bestFitBoardID = boardID
+			bestFitBoardFree = availOrinSet.Len()
+			bestFitConnectivity = c
+			bestFitOrins = orins
+		}
+	}
+	// 2. node score is the same as binpack, interconnect is within a board
+
+	if bestFitBoardID != BoardIDNotFount {
+		for _, orinInfo := range canAlloc {
+			nodeScore -= len(orinInfo)
+		}
+	} else {
+		nodeScore = 0
+	}
+	return &AllocatorResult{score: nodeScore, boardID: bestFitBoardID, orins: bestFitOrins}
+}
+
+// bestConnectedOrins return the orins fit request on board with the best
+// connectivity, the lowest of equal ones
+func bestConnectedOrins(links topo.Interconnect, boardID int, free sets.Int, request *OrinRequest) (sets.Int, topo.Connectivity, bool) {
+	if _, ok := request.Fit(free); !ok {
+		return nil, topo.Connectivity{}, false
+	}
+	var best sets.Int
+	var bestConnectivity topo.Connectivity
+	others := free.Difference(request.Orins).List()
+	combinations(others, request.Count, func(chosen []int) {
+		orins := sets.NewInt(chosen...).Union(request.Orins)
+		c := links.Connectivity(boardID, orins.List())
+		if best == nil || c.Better(bestConnectivity) {
+			best, bestConnectivity = orins, c
+		}
+	})
+	return best, bestConnectivity, true
+}
+
+// combinations call fn with every k of items in lexicographic order
+func combinations(items []int, k int, fn func([]int)) {
+	chosen := make([]int, 0, k)
+	var walk func(start int)
+	walk = func(start int) {
+		if len(chosen) == k {
+			fn(chosen)
+			return
+		}
+		for i := start; i <= len(items)-(k-len(chosen)); i++ {
+			chosen = append(chosen, items[i])
+			walk(i + 1)
+			chosen = chosen[:len(chosen)-1]
+		}
+	}
+	walk(0)
 }
@@ -60,7 +60,76 @@ func TestAllocate(t *testing.T) {
 	}
 
 	for _, tc := range testcases {
-		ar := alloc.Allocate(tc.canAlloc, tc.request)
+		ar := alloc.Allocate(tc.canAlloc, NewOrinRequest(tc.request, 0))
+		if tc.expected.boardID != ar.boardID || tc.expected.score != ar.score {
+			t.Errorf("test case %s, is not same, expect boardID=%v,score=%v, actual boardID=%v,score=%v", tc.name, tc.expected.boardID, tc.expected.score, ar.boardID, ar.score)
+		}
+	}
+}
+
+func TestSpreadAllocate(t *testing.T) {
+
+	alloc := SpreadAllocator{}
+
+	case2Alloc := topo.NewBoardDetails()
+	case2Alloc[0] = topo.NewOrinDetails().Add(0, 0).Add(0, 1)
+	case2Alloc[1] = topo.NewOrinDetails().Add(1, 0).Add(1, 1)
+
+	case3Alloc := topo.NewBoardDetails()
+	case3Alloc[0] = topo.NewOrinDetails().Add(0, 0).Add(0, 1)
+	case3Alloc[1] = topo.NewOrinDetails().Add(1, 1).Add(1, 2)
+	case3Alloc[2] = topo.NewOrinDetails().Add(2, 1).Add(2, 2)
+
+	case4Alloc := topo.NewBoardDetails()
+	case4Alloc[0] = topo.NewOrinDetails().Add(0, 0).Add(0, 1).Add(0, 2)
+	case4Alloc[1] = topo.NewOrinDetails().Add(1, 0).Add(1, 1)
+	case4Alloc[2] = topo.NewOrinDetails().Add(2, 0).Add(2, 1).Add(2, 2).Add(2, 3)
+
+	case5Alloc := topo.NewBoardDetails()
+	case5Alloc[3] = topo.NewOrinDetails().Add(3, 0).Add(3, 1)
+	case5Alloc[1] = topo.NewOrinDetails().Add(1, 0).Add(1, 1)
+	case5Alloc[2] = topo.NewOrinDetails().Add(2, 0).Add(2, 1)
+
+	testcases := []struct {
+		name     string
+		canAlloc topo.BoardDetails
+		request  sets.Int
+		expected AllocatorResult
+	}{
+		{
+			name:     "1.empty available resouce",
+			canAlloc: topo.NewBoardDetails(),
+			request:  sets.NewInt(),
+			expected: AllocatorResult{score: 0, boardID: BoardIDNotFount},
+		},
+		{
+			name:     "2.resouce not enough",
+			canAlloc: case2Alloc,
+			request:  sets.NewInt(1, 2),
+			expected: AllocatorResult{score: 0, boardID: BoardIDNotFount},
+		},
+		{
+			name:     "3.resouce enough  only 1 board can allocate",
+			canAlloc: case3Alloc,
+			request:  sets.NewInt(0, 1),
+			expected: AllocatorResult{score: 6, boardID: 0},
+		},
+		{
+			name:     "4.resouce enough  3 board can allocate, allocate a most free one",
+			canAlloc: case4Alloc,
+			request:  sets.NewInt(0, 1),
+			expected: AllocatorResult{score: 9, boardID: 2},
+		},
+		{
+			name:     "5.equal free boards, allocate the lowest board id",
+			canAlloc: case5Alloc,
+			request:  sets.NewInt(1),
+			expected: AllocatorResult{score: 6, boardID: 1},
+		},
+	}
+
+	for _, tc := range testcases {
+		ar := alloc.Allocate(tc.canAlloc, NewOrinRequest(tc.request, 0))
 		if tc.expected.boardID != ar.boardID || tc.expected.score != ar.score {
 			t.Errorf("test case %s, is not same, expect boardID=%v,score=%v, actual boardID=%v,score=%v", tc.name, tc.expected.boardID, tc.expected.score, ar.boardID, ar.score)
 		}
@@ -2,9 +2,9 @@ package manager
 
 import (
 	"fmt"
-	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/superedge/orin-device-system/pkg/common"
 	"github.com/superedge/orin-device-system/pkg/scheduler/manager/topo"
@@ -26,17 +26,21 @@ type NodeInfo struct {
 	Allocatable topo.BoardDetails
 	Requested   topo.BoardDetails
 	Total       topo.BoardDetails
+
+	// PoweredOff is boards available on wake, they stay in Total
+	PoweredOff sets.Int
+
+	// Links is the interconnect between orins of every board
+	Links topo.Interconnect
 }
 
 // addPod only focus pod which has bind to node and board
 func (ni *NodeInfo) addPod(pod *v1.Pod) error {
-	bindBoardIDStr, ok := pod.Annotations[common.AnnotationPodBindToBoard]
-	if !ok {
+	if _, ok := pod.Annotations[common.AnnotationPodBindToBoard]; !ok {
 		return nil
 	}
-	bindBoardID, err := strconv.ParseInt(bindBoardIDStr, 10, 0)
-	if err != nil {
-		klog.ErrorS(err, "find a invalid pod bind boardID", "annotation value", bindBoardIDStr)
+	boards := PodBoardOrins(pod)
+	if len(boards) == 0 {
 		return nil
 	}
 	// pod has exist in nodeinfo cache, update it
@@ -45,36 +49,16 @@ func (ni *NodeInfo) addPod(pod *v1.Pod) error {
 			return err
 		}
 	}
-	// 1. check pod has orin request
-	orinSet := BuildRequestOrinSet(pod)
-	var od topo.OrinDetails
-	// 2. caculate request
-	if existOd, ok := ni.Requested[int(bindBoardID)]; ok {
-		od = existOd
-	} else {
-		od = topo.NewOrinDetails()
-	}
-	for _, o := range orinSet.UnsortedList() {
-		od.Add(int(bindBoardID), o)
-	}
-	ni.Requested[int(bindBoardID)] = od
-	// 3. caculate allocatable
-	newAllocatable, err := ni.Total.DifferenceFromSuperset(ni.Requested)
-	if err != nil {
-		klog.ErrorS(err, "Caculate node allocatable error", "nodename", ni.Node.Name, "total", ni.Total, "request", ni.Requested)
+	if err := ni.charge(boards); err != nil {
 		return err
 	}
-	ni.Allocatable = newAllocatable
-	// 4. add pod to cache
 	ni.Pods[pod.UID] = pod
 	klog.V(4).InfoS("after node info add pod", "node name", ni.Node.Name, "pod name", pod.Name, "total details", ni.Total, "request", ni.Requested, "allocatable", ni.Allocatable)
 
 	return nil
 }
 func (ni *NodeInfo) deletePod(pod *v1.Pod) error {
-
-	bindBoardIDStr, ok := pod.Annotations[common.AnnotationPodBindToBoard]
-	if !ok {
+	if _, ok := pod.Annotations[common.AnnotationPodBindToBoard]; !ok {
 		return nil
 	}
 
@@ -82,20 +66,49 @@ func (ni *NodeInfo) deletePod(pod *v1.Pod) error {
 		return fmt.Errorf("node %s has not contain pod %s,uid %v", ni.Node.Name, pod.Name, pod.UID)
 	}
 
-	bindBoardID, err := strconv.ParseInt(bindBoardIDStr, 10, 0)
-	if err != nil {
-		klog.ErrorS(err, "find a invalid pod bind boardID", "annotation value", bindBoardIDStr)
+	boards := PodBoardOrins(pod)
+	if len(boards) == 0 {
 		return nil
 	}
-	// 1. sum pod orin request
-	needReleaseOrinSet := BuildRequestOrinSet(pod)
-	needReleaseOrinDetails := topo.NewOrinDetails()
+	if err := ni.release(boards); err != nil {
+		return err
+	}
+	// TODO need check request plus allocatable is equal to total
+	delete(ni.Pods, pod.UID)
 
-	for _, o := range needReleaseOrinSet.UnsortedList() {
-		needReleaseOrinDetails.Add(int(bindBoardID), o)
+	klog.V(4).InfoS("after node info delete pod", "node name", ni.Node.Name, "pod name", pod.Name, "total details", ni.Total, "request", ni.Requested, "allocatable", ni.Allocatable)
+	return nil
+
+}
+
+// charge add orins of every board to requested, they are held by a pod or
+// reserved for a pod group
+func (ni *NodeInfo) charge(boards map[int]sets.Int) error {
+	// 1. caculate request of every board
+	for boardID, orinSet := range boards {
+		od, ok := ni.Requested[boardID]
+		if !ok {
+			od = topo.NewOrinDetails()
+		}
+		for _, o := range orinSet.UnsortedList() {
+			od.Add(boardID, o)
+		}
+		ni.Requested[boardID] = od
 	}
+	// 2. caculate allocatable
+	newAllocatable, err := ni.Total.DifferenceFromSuperset(ni.Requested)
+	if err != nil {
+		klog.ErrorS(err, "Caculate node allocatable error", "nodename", ni.Node.Name, "total", ni.Total, "request", ni.Requested)
+		return err
+	}
+	ni.Allocatable = newAllocatable
+	return nil
+}
 
-	needReleaseBoardDetails := topo.NewBoardDetails().Add(int(bindBoardID), needReleaseOrinDetails)
+// release return orins of every board to allocatable
+func (ni *NodeInfo) release(boards map[int]sets.Int) error {
+	// 1. sum orins of every board
+	needReleaseBoardDetails := boardDetails(boards)
 	// 2. caculate request
 	newRequest, err := ni.Requested.DifferenceFromSuperset(needReleaseBoardDetails)
 	if err != nil {
@@ -104,15 +117,23 @@ func (ni *NodeInfo) deletePod(pod *v1.Pod) error {
 	}
 	ni.Requested = newRequest
 	// 3. caculate allocatable
-	ni.Allocatable.Add(int(bindBoardID), needReleaseOrinDetails)
-
-	// TODO need check request plus allocatable is equal to total
-	// 4. delete pod in cache
-	delete(ni.Pods, pod.UID)
-
-	klog.V(4).InfoS("after node info delete pod", "node name", ni.Node.Name, "pod name", pod.Name, "total details", ni.Total, "request", ni.Requested, "allocatable", ni.Allocatable)
+	for boardID, od := range needReleaseBoardDetails {
+		ni.Allocatable.Add(boardID, od)
+	}
 	return nil
+}
 
+// boardDetails return details of orins of every board
+func boardDetails(boards map[int]sets.Int) topo.BoardDetails {
+	res := topo.NewBoardDetails()
+	for boardID, orinSet := range boards {
+		od := topo.NewOrinDetails()
+		for _, o := range orinSet.UnsortedList() {
+			od.Add(boardID, o)
+		}
+		res.Add(boardID, od)
+	}
+	return res
 }
 
 // func (ni *NodeInfo) updatePod(pod *v1.Pod) error {
@@ -147,27 +168,75 @@ type Cache interface {
 	AssumePod(pod *v1.Pod, node string) error
 	// ForgetPod will clear assume cache, like bind error
 	ForgetPod(pod *v1.Pod, node string) error
+	// FinishBinding start expiring assumed pod, it is forgot if informer
+	// does not see it bound within ttl
+	FinishBinding(pod *v1.Pod) error
+	// CleanupAssumedPods forget assumed pods expired
+	CleanupAssumedPods(now time.Time)
+
+	// WaitGroupMember record a pending member of group, and return the
+	// number of members seen within timeout and in cache
+	WaitGroupMember(group *PodGroup, pod *v1.Pod, timeout time.Duration) int
+	// GroupStatus return where members of group are placed
+	GroupStatus(key string) GroupStatus
+	// ReserveGroup place group on node and board, and reserve orins for
+	// members not bound yet until timeout
+	ReserveGroup(key, node string, board int, reserved map[int]sets.Int, timeout time.Duration) error
+	// AssumeGroupPod assume pod with orins taken from reservation of group
+	AssumeGroupPod(pod *v1.Pod, node, key string) error
+	// ForgetGroupPod forget pod assumed by AssumeGroupPod, its orins are
+	// reserved for group again
+	ForgetGroupPod(pod *v1.Pod, node, key string) error
+	// UnreserveGroup release orins reserved for group and forget its place
+	UnreserveGroup(key string)
+	// ExpireGroups unreserve groups timed out
+	ExpireGroups(now time.Time)
 }
 
+const (
+	// DefaultAssumeTTL is how long an assumed pod is kept after binding
+	// before informer sees it bound
+	DefaultAssumeTTL = 30 * time.Second
+	// releasedPodTTL is how long a released pod is remembered, so stale
+	// events of it do not add it back
+	releasedPodTTL = 5 * time.Minute
+)
+
 func NewScheduleCache() *scheduleCache {
 	return &scheduleCache{
 		nodeCache:   make(map[string]*NodeInfo),
-		assumePods:  sets.NewString(),
+		assumePods:  make(map[types.UID]*assumedPod),
 		mu:          new(sync.RWMutex),
 		podMaps:     make(map[types.UID]*v1.Pod),
-		releasedPod: make(map[types.UID]struct{}),
+		releasedPod: make(map[types.UID]time.Time),
+		groups:      make(map[string]*groupState),
+		AssumeTTL:   DefaultAssumeTTL,
 	}
 }
 
 type scheduleCache struct {
-	nodeCache   map[string]*NodeInfo
-	assumePods  sets.String
-	podMaps     map[types.UID]*v1.Pod
-	releasedPod map[types.UID]struct{}
+	nodeCache  map[string]*NodeInfo
+	assumePods map[types.UID]*assumedPod
+	podMaps    map[types.UID]*v1.Pod
+	// releasedPod is the time pods are released
+	releasedPod map[types.UID]time.Time
+	// groups is pod groups by namespace/name
+	groups map[string]*groupState
+
+	// AssumeTTL is how long an assumed pod is kept after binding
+	AssumeTTL time.Duration
 
 	mu *sync.RWMutex
 }
 
+// assumedPod is a pod bound by scheduler, but not seen bound by informer
+type assumedPod struct {
+	node string
+	// bindingFinished is set when pod is bound, it expires after deadline
+	bindingFinished bool
+	deadline        time.Time
+}
+
 func (c *scheduleCache) AddPod(pod *v1.Pod) error {
 	// find pod that use orin and has been scheduled
 	if pod.Spec.NodeName == "" {
@@ -180,6 +249,25 @@ func (c *scheduleCache) AddPod(pod *v1.Pod) error {
 	c.mu.Lock()
 
 	defer c.mu.Unlock()
+	if _, ok := c.releasedPod[pod.UID]; ok {
+		return nil
+	}
+	if ap, ok := c.assumePods[pod.UID]; ok {
+		delete(c.assumePods, pod.UID)
+		if ap.node == pod.Spec.NodeName {
+			// confirm assumed pod with the one informer sees bound
+			if ni, ok := c.nodeCache[ap.node]; ok {
+				if _, ok := ni.Pods[pod.UID]; ok {
+					ni.Pods[pod.UID] = pod
+				}
+			}
+			c.podMaps[pod.UID] = pod
+			klog.V(4).InfoS("assumed pod confirmed", "pod", klog.KObj(pod), "node", ap.node)
+			return nil
+		}
+		klog.InfoS("assumed pod is bound to another node", "pod", klog.KObj(pod), "assumed", ap.node, "node", pod.Spec.NodeName)
+		c.removePod(pod.UID, ap.node)
+	}
 	if _, ok := c.podMaps[pod.UID]; ok {
 		return nil
 	}
@@ -200,39 +288,51 @@ func (c *scheduleCache) AddPod(pod *v1.Pod) error {
 }
 
 func (c *scheduleCache) DeletePod(pod *v1.Pod) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	node := pod.Spec.NodeName
+	if ap, ok := c.assumePods[pod.UID]; ok {
+		// pod is deleted before informer sees it bound
+		node = ap.node
+	}
 	// find pod that use orin and has been scheduled
-	if pod.Spec.NodeName == "" {
+	if node == "" {
 		return nil
 	}
-	_, ok := pod.Annotations[common.AnnotationPodBindToBoard]
-	if !ok {
+	if _, ok := c.podMaps[pod.UID]; !ok {
+		if _, ok := pod.Annotations[common.AnnotationPodBindToBoard]; ok {
+			c.releasedPod[pod.UID] = time.Now()
+		}
 		return nil
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	err := c.removePod(pod.UID, node)
+	c.releasedPod[pod.UID] = time.Now()
+	return err
+}
+
+// removePod release orins of pod cached on node, and remove it from cache
+func (c *scheduleCache) removePod(uid types.UID, node string) error {
+	cached := c.podMaps[uid]
+	delete(c.podMaps, uid)
+	delete(c.assumePods, uid)
 	// if node is not in cache ingore
-	// TODO deal like kube-scheduler?
-	ni, ok := c.nodeCache[pod.Spec.NodeName]
-	if !ok {
+	ni, ok := c.nodeCache[node]
+	if !ok || cached == nil {
 		return nil
 	}
-	err := ni.deletePod(pod)
-	if err != nil {
-		return err
-	}
-	if _, ok := c.podMaps[pod.UID]; ok {
-		delete(c.podMaps, pod.UID)
-		c.releasedPod[pod.UID] = struct{}{}
+	if _, ok := ni.Pods[uid]; !ok {
+		return nil
 	}
-
-	return err
+	return ni.deletePod(cached)
 }
+
 func (c *scheduleCache) AddNode(node *v1.Node, pods ...*v1.Pod) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	ni := NewNodeInfo(node, pods...)
 	if ni != nil {
+		c.chargeReservations(ni)
 		c.nodeCache[node.Name] = ni
 	}
 
@@ -264,7 +364,13 @@ func (c *scheduleCache) UpdateNode(oldNode *v1.Node, newNode *v1.Node, pods ...*
 		} else {
 			newNi.Allocatable = newAllocate
 		}
+		c.chargeReservations(newNi)
 		c.nodeCache[newNode.Name] = newNi
+	} else if ni, ok := c.nodeCache[newNode.Name]; ok {
+		// board power and interconnect changes do not touch resources
+		ni.Node = newNode
+		ni.PoweredOff = newNi.PoweredOff
+		ni.Links = newNi.Links
 	}
 	return nil
 }
@@ -277,6 +383,12 @@ func (c *scheduleCache) DeleteNode(node *v1.Node) error {
 	}
 
 	delete(c.nodeCache, node.Name)
+	// groups placed on the node are placed again
+	for _, st := range c.groups {
+		if st.node == node.Name {
+			st.unplace()
+		}
+	}
 
 	return nil
 }
@@ -294,7 +406,7 @@ func (c *scheduleCache) AssumePod(pod *v1.Pod, node string) error {
 	if err := ni.addPod(pod); err != nil {
 		return err
 	}
-	c.assumePods.Insert(string(pod.UID))
+	c.assumePods[pod.UID] = &assumedPod{node: node}
 	c.podMaps[pod.UID] = pod
 	return nil
 }
@@ -302,20 +414,49 @@ func (c *scheduleCache) AssumePod(pod *v1.Pod, node string) error {
 func (c *scheduleCache) ForgetPod(pod *v1.Pod, node string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	ni, ok := c.nodeCache[node]
+	ap, ok := c.assumePods[pod.UID]
 	if !ok {
-		return fmt.Errorf("node %v is not found", node)
+		return fmt.Errorf("pod %v(%v) is not assumed, so can't be forgot", pod.Name, klog.KObj(pod))
 	}
-	if _, ok := c.podMaps[pod.UID]; !ok {
-		return fmt.Errorf("pod %v(%v) is not in the cache, so can't be forgot", pod.Name, klog.KObj(pod))
+	if ap.node != node {
+		return fmt.Errorf("pod %v(%v) is assumed on node %s, not %s", pod.Name, klog.KObj(pod), ap.node, node)
 	}
+	// cached pod has the orins charged, pod may not have bind annotation
+	return c.removePod(pod.UID, node)
+}
 
-	if err := ni.deletePod(pod); err != nil {
-		return err
+func (c *scheduleCache) FinishBinding(pod *v1.Pod) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ap, ok := c.assumePods[pod.UID]
+	if !ok {
+		// informer has seen it bound
+		return nil
 	}
-
+	ap.bindingFinished = true
+	ap.deadline = time.Now().Add(c.AssumeTTL)
 	return nil
 }
+
+func (c *scheduleCache) CleanupAssumedPods(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for uid, ap := range c.assumePods {
+		if !ap.bindingFinished || now.Before(ap.deadline) {
+			continue
+		}
+		klog.InfoS("assumed pod expired", "uid", uid, "node", ap.node)
+		if err := c.removePod(uid, ap.node); err != nil {
+			klog.ErrorS(err, "expire assumed pod error", "uid", uid, "node", ap.node)
+		}
+	}
+	for uid, released := range c.releasedPod {
+		if now.Sub(released) > releasedPodTTL {
+			delete(c.releasedPod, uid)
+		}
+	}
+}
+
 func (c *scheduleCache) KnownPod(pod *v1.Pod) bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -357,6 +498,8 @@ func NewNodeInfo(node *v1.Node, pods ...*v1.Pod) *NodeInfo {
 	ni.Total = totalBoardDetails
 	ni.Requested = topo.NewBoardDetails()
 	ni.Allocatable = ni.Total
+	ni.PoweredOff = poweredOffBoards(node)
+	ni.Links = ParseInterconnect(node)
 
 	for _, p := range pods {
 		if err := ni.addPod(p); err != nil {
@@ -2,6 +2,7 @@ package manager
 
 import (
 	"testing"
+	"time"
 
 	"github.com/superedge/orin-device-system/pkg/common"
 	"github.com/superedge/orin-device-system/pkg/scheduler/manager/topo"
@@ -119,3 +120,133 @@ func TestAddPod(t *testing.T) {
 		t.Fatalf("test add node error, expect %v, actual %v", ni1md, ni1.Allocatable)
 	}
 }
+
+func TestAssumePodLifecycle(t *testing.T) {
+	// board 0 has orin 1 and 2, board 1 has orin 1 2 3 4
+	node := powerTestNode("")
+	freeOf := func(c *scheduleCache) int {
+		free := 0
+		for _, od := range c.nodeCache["node-1"].Allocatable {
+			free += len(od)
+		}
+		return free
+	}
+	// assumed pod has no node name until it is bound
+	assumed := func(name string) *v1.Pod {
+		pod := priorityPod(name, 0, "1", 1, 2)
+		pod.Spec.NodeName = ""
+		return pod
+	}
+	bound := func(pod *v1.Pod) *v1.Pod {
+		pod = pod.DeepCopy()
+		pod.Spec.NodeName = "node-1"
+		return pod
+	}
+
+	testcases := []struct {
+		name         string
+		run          func(c *scheduleCache, pod *v1.Pod)
+		expectFree   int
+		expectKnown  bool
+		expectAssume bool
+	}{
+		{
+			name:         "1.assumed",
+			run:          func(c *scheduleCache, pod *v1.Pod) {},
+			expectFree:   4,
+			expectKnown:  true,
+			expectAssume: true,
+		},
+		{
+			name: "2.confirmed by informer is never expired",
+			run: func(c *scheduleCache, pod *v1.Pod) {
+				c.FinishBinding(pod)
+				c.AddPod(bound(pod))
+				c.CleanupAssumedPods(time.Now().Add(time.Hour))
+			},
+			expectFree:  4,
+			expectKnown: true,
+		},
+		{
+			name: "3.expired if informer does not see it bound",
+			run: func(c *scheduleCache, pod *v1.Pod) {
+				c.FinishBinding(pod)
+				c.CleanupAssumedPods(time.Now())
+				c.CleanupAssumedPods(time.Now().Add(DefaultAssumeTTL + time.Second))
+			},
+			expectFree: 6,
+		},
+		{
+			name: "4.not expired while binding",
+			run: func(c *scheduleCache, pod *v1.Pod) {
+				c.CleanupAssumedPods(time.Now().Add(time.Hour))
+			},
+			expectFree:   4,
+			expectKnown:  true,
+			expectAssume: true,
+		},
+		{
+			name: "5.forgot pod can be assumed again",
+			run: func(c *scheduleCache, pod *v1.Pod) {
+				unannotated := pod.DeepCopy()
+				unannotated.Annotations = map[string]string{}
+				if err := c.ForgetPod(unannotated, "node-1"); err != nil {
+					t.Fatal(err)
+				}
+				if err := c.AssumePod(pod, "node-1"); err != nil {
+					t.Fatal(err)
+				}
+			},
+			expectFree:   4,
+			expectKnown:  true,
+			expectAssume: true,
+		},
+		{
+			name: "6.deleted before informer sees it bound",
+			run: func(c *scheduleCache, pod *v1.Pod) {
+				c.DeletePod(pod)
+				c.AddPod(bound(pod))
+			},
+			expectFree: 6,
+		},
+	}
+	for _, tc := range testcases {
+		c := NewScheduleCache()
+		c.AddNode(node)
+		pod := assumed("p")
+		if err := c.AssumePod(pod, "node-1"); err != nil {
+			t.Fatal(err)
+		}
+		tc.run(c, pod)
+		if free := freeOf(c); free != tc.expectFree {
+			t.Errorf("test case %s, expect %d free orins, actual %d", tc.name, tc.expectFree, free)
+		}
+		if known := c.KnownPod(pod); known != tc.expectKnown {
+			t.Errorf("test case %s, expect known %v, actual %v", tc.name, tc.expectKnown, known)
+		}
+		if _, ok := c.assumePods[pod.UID]; ok != tc.expectAssume {
+			t.Errorf("test case %s, expect assumed %v, actual %v", tc.name, tc.expectAssume, ok)
+		}
+	}
+}
+
+func TestReleasedPodExpire(t *testing.T) {
+	node := powerTestNode("")
+	c := NewScheduleCache()
+	c.AddNode(node)
+	pod := priorityPod("p", 0, "1", 1)
+	c.AddPod(pod)
+	c.DeletePod(pod)
+	if len(c.releasedPod) != 1 || len(c.podMaps) != 0 {
+		t.Fatalf("expect pod released, actual released %v cached %v", c.releasedPod, c.podMaps)
+	}
+	// stale events do not add it back
+	c.AddPod(pod)
+	if c.KnownPod(pod) {
+		t.Fatalf("expect released pod not added back")
+	}
+	c.CleanupAssumedPods(time.Now().Add(releasedPodTTL + time.Second))
+	if len(c.releasedPod) != 0 {
+		t.Fatalf("expect released pod forgot, actual %v", c.releasedPod)
+	}
+}
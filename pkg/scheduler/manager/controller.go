@@ -11,6 +11,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -28,6 +29,11 @@ import (
 var (
 	KeyFunc      = clientgocache.DeletionHandlingMetaNamespaceKeyFunc
 	resyncPeriod = 30 * time.Second
+	// groupExpirePeriod is the period of releasing timed out pod group
+	// reservations
+	groupExpirePeriod = 10 * time.Second
+	// assumeExpirePeriod is the period of forgetting expired assumed pods
+	assumeExpirePeriod = time.Second
 )
 
 type Controller struct {
@@ -56,15 +62,24 @@ type Controller struct {
 
 	// nodeInformerSynced returns true if the service store has been synced at least once.
 	nodeInformerSynced clientgocache.InformerSynced
-}
 
-func NewController(clientset kubernetes.Interface, manager Manager, stopCh <-chan struct{}) (c *Controller, err error) {
-	informerFactory := informers.NewSharedInformerFactory(clientset, resyncPeriod)
+	// namespaceInformerSynced returns true if the namespace store has been synced at least once.
+	namespaceInformerSynced clientgocache.InformerSynced
+
+	// pdbInformerSynced returns true if the pod disruption budget store has been synced at least once.
+	pdbInformerSynced clientgocache.InformerSynced
+}
 
+// NewEventRecorder return event recorder shared by controller and manager
+func NewEventRecorder(clientset kubernetes.Interface) record.EventRecorder {
 	klog.Info("Creating event broadcaster")
 	eventBroadcaster := record.NewBroadcaster()
 	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: clientset.CoreV1().Events("")})
-	recorder := eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: "orin-device-system"})
+	return eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: "orin-device-system"})
+}
+
+func NewController(clientset kubernetes.Interface, manager Manager, recorder record.EventRecorder, stopCh <-chan struct{}) (c *Controller, err error) {
+	informerFactory := informers.NewSharedInformerFactory(clientset, resyncPeriod)
 
 	c = &Controller{
 		clientset: clientset,
@@ -110,6 +125,24 @@ func NewController(clientset kubernetes.Interface, manager Manager, stopCh <-cha
 	c.nodeLister = nodeInformer.Lister()
 	c.nodeInformerSynced = nodeInformer.Informer().HasSynced
 
+	// Create namespace informer for namespace default policy
+	namespaceInformer := informerFactory.Core().V1().Namespaces()
+	namespaceInformer.Informer().AddEventHandler(clientgocache.ResourceEventHandlerFuncs{
+		AddFunc:    c.setNamespacePolicy,
+		UpdateFunc: func(_, newObj interface{}) { c.setNamespacePolicy(newObj) },
+		DeleteFunc: c.deleteNamespacePolicy,
+	})
+	c.namespaceInformerSynced = namespaceInformer.Informer().HasSynced
+
+	// Create pod disruption budget informer for preemption
+	pdbInformer := informerFactory.Policy().V1().PodDisruptionBudgets()
+	pdbInformer.Informer().AddEventHandler(clientgocache.ResourceEventHandlerFuncs{
+		AddFunc:    c.setPodDisruptionBudget,
+		UpdateFunc: func(_, newObj interface{}) { c.setPodDisruptionBudget(newObj) },
+		DeleteFunc: c.deletePodDisruptionBudget,
+	})
+	c.pdbInformerSynced = pdbInformer.Informer().HasSynced
+
 	c.manager = manager
 	// Start informer goroutines.
 	go informerFactory.Start(stopCh)
@@ -128,6 +161,18 @@ func NewController(clientset kubernetes.Interface, manager Manager, stopCh <-cha
 		klog.Info("init the pod cache successfully")
 	}
 
+	if ok := clientgocache.WaitForCacheSync(stopCh, c.namespaceInformerSynced); !ok {
+		return nil, fmt.Errorf("failed to wait for namespace caches to sync")
+	} else {
+		klog.Info("init the namespace cache successfully")
+	}
+
+	if ok := clientgocache.WaitForCacheSync(stopCh, c.pdbInformerSynced); !ok {
+		return nil, fmt.Errorf("failed to wait for pod disruption budget caches to sync")
+	} else {
+		klog.Info("init the pod disruption budget cache successfully")
+	}
+
 	klog.Info("end to wait for cache")
 
 	return c, nil
@@ -146,6 +191,9 @@ func (c *Controller) Run(threadiness int, stopCh <-chan struct{}) error {
 		go wait.Until(c.runWorker, time.Second, stopCh)
 	}
 
+	go wait.Until(func() { c.manager.ExpireGroups(time.Now()) }, groupExpirePeriod, stopCh)
+	go wait.Until(func() { c.manager.CleanupAssumedPods(time.Now()) }, assumeExpirePeriod, stopCh)
+
 	klog.Info("Started workers")
 	<-stopCh
 	klog.Info("Shutting down workers")
@@ -264,8 +312,9 @@ func (c *Controller) updatePodInCache(oldObj, newObj interface{}) {
 		return
 	}
 
-	// find completed pod which binding board
-	if c.manager.KnownPod(oldPod) && IsCompletedPod(newPod) && IsBindingBoard(newPod) {
+	// find completed pod which binding board, or assumed pod now bound
+	bound := oldPod.Spec.NodeName == "" && newPod.Spec.NodeName != ""
+	if c.manager.KnownPod(oldPod) && (IsCompletedPod(newPod) || bound) && IsBindingBoard(newPod) {
 		podKey, err := KeyFunc(newPod)
 		if err != nil {
 			klog.Warningf("Failed to get the job key: %v", err)
@@ -463,7 +512,8 @@ func (c *Controller) getActivePodInNode(nodeName string) ([]*v1.Pod, error) {
 	return res, nil
 }
 func IsOrinPod(pod *v1.Pod) bool {
-	if IsResourceExists(pod, common.ExtendResouceTypeBoard) && IsResourceExists(pod, common.ExtendResouceTypeOrinPrefix) {
+	// superedge.io/device-orin is the prefix of superedge.io/device-orin-N too
+	if IsResourceExists(pod, common.ExtendResouceTypeBoard) && IsResourceExists(pod, common.ExtendResouceTypeOrin) {
 		return true
 	}
 	return false
@@ -495,3 +545,53 @@ func IsBindingBoard(pod *v1.Pod) bool {
 	_, ok := pod.Annotations[common.AnnotationPodBindToBoard]
 	return ok
 }
+
+func (c *Controller) setNamespacePolicy(obj interface{}) {
+	ns, ok := obj.(*v1.Namespace)
+	if !ok {
+		klog.Warningf("cannot convert to *v1.Namespace: %v", obj)
+		return
+	}
+	c.manager.SetNamespacePolicy(ns.Name, ns.Annotations[common.AnnotationNamespaceOrinPolicy])
+}
+
+func (c *Controller) deleteNamespacePolicy(obj interface{}) {
+	ns, ok := obj.(*v1.Namespace)
+	if !ok {
+		tombstone, ok := obj.(clientgocache.DeletedFinalStateUnknown)
+		if !ok {
+			runtime.HandleError(fmt.Errorf("couldn't get object from tombstone %#v", obj))
+			return
+		}
+		if ns, ok = tombstone.Obj.(*v1.Namespace); !ok {
+			runtime.HandleError(fmt.Errorf("tombstone contained object is not a namespace %#v", obj))
+			return
+		}
+	}
+	c.manager.SetNamespacePolicy(ns.Name, "")
+}
+
+func (c *Controller) setPodDisruptionBudget(obj interface{}) {
+	pdb, ok := obj.(*policyv1.PodDisruptionBudget)
+	if !ok {
+		klog.Warningf("cannot convert to *policyv1.PodDisruptionBudget: %v", obj)
+		return
+	}
+	c.manager.SetPodDisruptionBudget(pdb.Namespace+"/"+pdb.Name, pdb)
+}
+
+func (c *Controller) deletePodDisruptionBudget(obj interface{}) {
+	pdb, ok := obj.(*policyv1.PodDisruptionBudget)
+	if !ok {
+		tombstone, ok := obj.(clientgocache.DeletedFinalStateUnknown)
+		if !ok {
+			runtime.HandleError(fmt.Errorf("couldn't get object from tombstone %#v", obj))
+			return
+		}
+		if pdb, ok = tombstone.Obj.(*policyv1.PodDisruptionBudget); !ok {
+			runtime.HandleError(fmt.Errorf("tombstone contained object is not a pod disruption budget %#v", obj))
+			return
+		}
+	}
+	c.manager.SetPodDisruptionBudget(pdb.Namespace+"/"+pdb.Name, nil)
+}
@@ -0,0 +1,397 @@
+package manager
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/scheduler/manager/topo"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+const (
+	GroupScopeNode  = "node"
+	GroupScopeBoard = "board"
+
+	// DefaultGroupTimeout is how long members of a group are remembered and
+	// orins are reserved for members not bound yet
+	DefaultGroupTimeout = 5 * time.Minute
+)
+
+// PodGroup is the gang a pod belongs to, members land on one node, or one
+// board of board scope, or none of them starts
+type PodGroup struct {
+	// Key is namespace/name of group
+	Key       string
+	MinMember int
+	Scope     string
+}
+
+// BuildPodGroup return group of pod, nil if pod is not in a group
+func BuildPodGroup(pod *v1.Pod) (*PodGroup, error) {
+	name, ok := pod.Annotations[common.AnnotationPodGroup]
+	if !ok {
+		return nil, nil
+	}
+	if name == "" {
+		return nil, fmt.Errorf("annotation %s is empty", common.AnnotationPodGroup)
+	}
+	minMember, err := strconv.Atoi(pod.Annotations[common.AnnotationPodGroupMinMember])
+	if err != nil || minMember < 1 {
+		return nil, fmt.Errorf("annotation %s of pod group %s must be a positive number", common.AnnotationPodGroupMinMember, name)
+	}
+	scope := pod.Annotations[common.AnnotationPodGroupScope]
+	switch scope {
+	case "":
+		scope = GroupScopeNode
+	case GroupScopeNode, GroupScopeBoard:
+	default:
+		return nil, fmt.Errorf("annotation %s of pod group %s must be %s or %s", common.AnnotationPodGroupScope, name, GroupScopeNode, GroupScopeBoard)
+	}
+	return &PodGroup{Key: pod.Namespace + "/" + name, MinMember: minMember, Scope: scope}, nil
+}
+
+// GroupStatus is where members of a group are placed, Node is empty if the
+// group is not placed
+type GroupStatus struct {
+	Node string
+	// Board is the board of board scope, BoardIDNotFount for node scope
+	Board int
+	// Reserved is orins reserved for members not bound yet
+	Reserved map[int]sets.Int
+	// Bound is the number of members in cache
+	Bound int
+}
+
+// groupState is a pod group seen by scheduler extender
+type groupState struct {
+	// waiting is pending members seen by Predicate and when
+	waiting  map[types.UID]time.Time
+	node     string
+	board    int
+	reserved map[int]sets.Int
+	expire   time.Time
+}
+
+// unplace forget where group is placed and its reservation
+func (st *groupState) unplace() {
+	st.node, st.board, st.reserved, st.expire = "", BoardIDNotFount, nil, time.Time{}
+}
+
+func (c *scheduleCache) group(key string) *groupState {
+	st, ok := c.groups[key]
+	if !ok {
+		st = &groupState{waiting: make(map[types.UID]time.Time), board: BoardIDNotFount}
+		c.groups[key] = st
+	}
+	return st
+}
+
+// boundGroupMembers return members of group in cache
+func (c *scheduleCache) boundGroupMembers(key string) sets.String {
+	res := sets.NewString()
+	for uid, pod := range c.podMaps {
+		if g, err := BuildPodGroup(pod); err == nil && g != nil && g.Key == key {
+			res.Insert(string(uid))
+		}
+	}
+	return res
+}
+
+func (c *scheduleCache) WaitGroupMember(group *PodGroup, pod *v1.Pod, timeout time.Duration) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	st := c.group(group.Key)
+	st.waiting[pod.UID] = now
+	members := c.boundGroupMembers(group.Key)
+	for uid, seen := range st.waiting {
+		if uid != pod.UID && (now.Sub(seen) > timeout || members.Has(string(uid))) {
+			delete(st.waiting, uid)
+			continue
+		}
+		members.Insert(string(uid))
+	}
+	return members.Len()
+}
+
+func (c *scheduleCache) GroupStatus(key string) GroupStatus {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	res := GroupStatus{Board: BoardIDNotFount, Reserved: map[int]sets.Int{}, Bound: c.boundGroupMembers(key).Len()}
+	st, ok := c.groups[key]
+	if !ok {
+		return res
+	}
+	res.Node, res.Board = st.node, st.board
+	for boardID, orins := range st.reserved {
+		res.Reserved[boardID] = sets.NewInt(orins.UnsortedList()...)
+	}
+	return res
+}
+
+func (c *scheduleCache) ReserveGroup(key, node string, board int, reserved map[int]sets.Int, timeout time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ni, ok := c.nodeCache[node]
+	if !ok {
+		return fmt.Errorf("node %v is not found", node)
+	}
+	st := c.group(key)
+	if st.node != "" {
+		return fmt.Errorf("pod group %s is already placed on node %s", key, st.node)
+	}
+	if err := ni.charge(reserved); err != nil {
+		return err
+	}
+	st.node, st.board, st.reserved = node, board, reserved
+	st.expire = time.Now().Add(timeout)
+	klog.InfoS("pod group reserved", "group", key, "node", node, "board", board, "reserved", DescribeBoardOrins(reserved))
+	return nil
+}
+
+func (c *scheduleCache) AssumeGroupPod(pod *v1.Pod, node, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ni, ok := c.nodeCache[node]
+	if !ok {
+		return fmt.Errorf("node %v is not found", node)
+	}
+	if _, ok := c.podMaps[pod.UID]; ok {
+		return fmt.Errorf("pod %v(%v) is in the cache, so can't be assumed", pod.Name, klog.KObj(pod))
+	}
+	st, ok := c.groups[key]
+	if !ok || st.node != node {
+		return fmt.Errorf("pod group %s is not placed on node %s", key, node)
+	}
+	used := PodBoardOrins(pod)
+	for boardID, orins := range used {
+		if reserved, ok := st.reserved[boardID]; !ok || !reserved.IsSuperset(orins) {
+			return fmt.Errorf("%s of pod %s is not reserved for pod group %s", DescribeBoardOrins(used), klog.KObj(pod), key)
+		}
+	}
+	// move orins from the reservation to pod
+	if err := ni.release(used); err != nil {
+		return err
+	}
+	if err := ni.addPod(pod); err != nil {
+		if err := ni.charge(used); err != nil {
+			klog.ErrorS(err, "charge reservation back error", "group", key)
+		}
+		return err
+	}
+	for boardID, orins := range used {
+		st.reserved[boardID] = st.reserved[boardID].Difference(orins)
+		if st.reserved[boardID].Len() == 0 {
+			delete(st.reserved, boardID)
+		}
+	}
+	delete(st.waiting, pod.UID)
+	c.assumePods[pod.UID] = &assumedPod{node: node}
+	c.podMaps[pod.UID] = pod
+	return nil
+}
+
+func (c *scheduleCache) ForgetGroupPod(pod *v1.Pod, node, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ap, ok := c.assumePods[pod.UID]
+	if !ok {
+		return fmt.Errorf("pod %v(%v) is not assumed, so can't be forgot", pod.Name, klog.KObj(pod))
+	}
+	if ap.node != node {
+		return fmt.Errorf("pod %v(%v) is assumed on node %s, not %s", pod.Name, klog.KObj(pod), ap.node, node)
+	}
+	used := PodBoardOrins(c.podMaps[pod.UID])
+	if err := c.removePod(pod.UID, node); err != nil {
+		return err
+	}
+	st, ok := c.groups[key]
+	if !ok || st.node != node {
+		// reservation is gone, orins are back to node
+		return nil
+	}
+	// move orins from pod back to the reservation, and wait for the member
+	// again
+	if err := c.nodeCache[node].charge(used); err != nil {
+		return err
+	}
+	if st.reserved == nil {
+		st.reserved = map[int]sets.Int{}
+	}
+	for boardID, orins := range used {
+		if _, ok := st.reserved[boardID]; !ok {
+			st.reserved[boardID] = sets.NewInt()
+		}
+		st.reserved[boardID].Insert(orins.UnsortedList()...)
+	}
+	st.waiting[pod.UID] = time.Now()
+	return nil
+}
+
+func (c *scheduleCache) UnreserveGroup(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.unreserveGroup(key)
+}
+
+// unreserveGroup release orins reserved for group and forget where it is
+// placed, members waiting are kept
+func (c *scheduleCache) unreserveGroup(key string) {
+	st, ok := c.groups[key]
+	if !ok || st.node == "" {
+		return
+	}
+	if ni, ok := c.nodeCache[st.node]; ok && len(st.reserved) != 0 {
+		if err := ni.release(st.reserved); err != nil {
+			klog.ErrorS(err, "release pod group reservation error", "group", key, "node", st.node)
+		}
+	}
+	klog.InfoS("pod group unreserved", "group", key, "node", st.node, "reserved", DescribeBoardOrins(st.reserved))
+	st.unplace()
+}
+
+func (c *scheduleCache) ExpireGroups(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, st := range c.groups {
+		if !st.expire.IsZero() && now.After(st.expire) {
+			c.unreserveGroup(key)
+		}
+		if st.node == "" && len(st.waiting) == 0 {
+			delete(c.groups, key)
+		}
+	}
+}
+
+// chargeReservations charge orins reserved on the rebuilt node info, the
+// reservation is dropped if node can not hold it any more
+func (c *scheduleCache) chargeReservations(ni *NodeInfo) {
+	for key, st := range c.groups {
+		if st.node != ni.Node.Name || len(st.reserved) == 0 {
+			continue
+		}
+		if err := ni.charge(st.reserved); err != nil {
+			klog.ErrorS(err, "node can not hold pod group reservation", "group", key, "node", st.node)
+			st.unplace()
+		}
+	}
+}
+
+// allocateGroup allocate n copies of requests on node, on one board of board
+// scope, it return allocation of every copy and boards to wake
+func allocateGroup(allocator Allocator, ni *NodeInfo, requests []*OrinRequest, n int, board bool) ([][]*AllocatorResult, []int) {
+	if !board {
+		return allocateCopies(allocator, ni, requests, n)
+	}
+	for _, boardID := range ni.Allocatable.BoardSet().List() {
+		one := &NodeInfo{Allocatable: boardDetails(map[int]sets.Int{boardID: ni.Allocatable[boardID].OrinSet()}), PoweredOff: ni.PoweredOff, Links: ni.Links}
+		if res, wake := allocateCopies(allocator, one, requests, n); res != nil {
+			return res, wake
+		}
+	}
+	return nil, nil
+}
+
+func allocateCopies(allocator Allocator, ni *NodeInfo, requests []*OrinRequest, n int) ([][]*AllocatorResult, []int) {
+	work := &NodeInfo{Allocatable: ni.Allocatable, PoweredOff: ni.PoweredOff, Links: ni.Links}
+	wake := sets.NewInt()
+	res := make([][]*AllocatorResult, 0, n)
+	for i := 0; i < n; i++ {
+		r, w := allocateBoards(allocator, work, requests)
+		if r == nil {
+			return nil, nil
+		}
+		wake.Insert(w...)
+		res = append(res, r)
+		left, err := work.Allocatable.DifferenceFromSuperset(boardDetails(resultOrins(r)))
+		if err != nil {
+			return nil, nil
+		}
+		work = &NodeInfo{Allocatable: left, PoweredOff: ni.PoweredOff, Links: ni.Links}
+	}
+	return res, wake.List()
+}
+
+// resultOrins return orins allocated on every board
+func resultOrins(results ...[]*AllocatorResult) map[int]sets.Int {
+	res := map[int]sets.Int{}
+	for _, r := range results {
+		for _, ar := range r {
+			if _, ok := res[ar.boardID]; !ok {
+				res[ar.boardID] = sets.NewInt()
+			}
+			res[ar.boardID].Insert(ar.orins.UnsortedList()...)
+		}
+	}
+	return res
+}
+
+// memberAllocation is allocation of a pod group member, reserve is orins for
+// the other members if the member places the group
+type memberAllocation struct {
+	results         []*AllocatorResult
+	wake            []int
+	fromReservation bool
+	place           bool
+	board           int
+	reserve         map[int]sets.Int
+}
+
+// score is the mean score of the results, so that every board the member
+// needs is counted
+func (a *memberAllocation) score() int {
+	if len(a.results) == 0 {
+		return 0
+	}
+	sum := 0
+	for _, res := range a.results {
+		sum += res.score
+	}
+	return sum / len(a.results)
+}
+
+// allocateMember allocate member of group on node, from orins reserved for
+// the group, or on the node and board the group is placed on, or for all
+// members not bound yet if group is not placed, nil if node does not fit
+func allocateMember(allocator Allocator, ni *NodeInfo, group *PodGroup, status GroupStatus, requests []*OrinRequest) *memberAllocation {
+	if status.Node != "" {
+		if ni.Node.Name != status.Node {
+			return nil
+		}
+		pool := &NodeInfo{Allocatable: boardDetails(status.Reserved), PoweredOff: ni.PoweredOff, Links: ni.Links}
+		if res, wake := allocateBoards(allocator, pool, requests); res != nil {
+			return &memberAllocation{results: res, wake: wake, fromReservation: true}
+		}
+		// members over min member or different from the first one
+		placed := ni
+		if status.Board != BoardIDNotFount {
+			placed = &NodeInfo{Allocatable: topo.NewBoardDetails(), PoweredOff: ni.PoweredOff, Links: ni.Links}
+			if od, ok := ni.Allocatable[status.Board]; ok {
+				placed.Allocatable[status.Board] = od
+			}
+		}
+		if res, wake := allocateBoards(allocator, placed, requests); res != nil {
+			return &memberAllocation{results: res, wake: wake}
+		}
+		return nil
+	}
+	n := group.MinMember - status.Bound
+	if n < 1 {
+		n = 1
+	}
+	copies, wake := allocateGroup(allocator, ni, requests, n, group.Scope == GroupScopeBoard)
+	if copies == nil {
+		return nil
+	}
+	board := BoardIDNotFount
+	if group.Scope == GroupScopeBoard {
+		board = copies[0][0].boardID
+	}
+	return &memberAllocation{results: copies[0], wake: wake, place: true, board: board, reserve: resultOrins(copies[1:]...)}
+}
@@ -0,0 +1,243 @@
+package manager
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func groupAnnotations(group, minMember, scope string) map[string]string {
+	res := map[string]string{common.AnnotationPodGroup: group, common.AnnotationPodGroupMinMember: minMember}
+	if scope != "" {
+		res[common.AnnotationPodGroupScope] = scope
+	}
+	return res
+}
+
+func TestBuildPodGroup(t *testing.T) {
+	testcases := []struct {
+		name        string
+		annotations map[string]string
+		expected    *PodGroup
+		expectError bool
+	}{
+		{name: "1.not in group", annotations: map[string]string{}},
+		{name: "2.node scope by default", annotations: groupAnnotations("perception", "3", ""), expected: &PodGroup{Key: "default/perception", MinMember: 3, Scope: GroupScopeNode}},
+		{name: "3.board scope", annotations: groupAnnotations("perception", "2", GroupScopeBoard), expected: &PodGroup{Key: "default/perception", MinMember: 2, Scope: GroupScopeBoard}},
+		{name: "4.no min member", annotations: map[string]string{common.AnnotationPodGroup: "perception"}, expectError: true},
+		{name: "5.invalid scope", annotations: groupAnnotations("perception", "2", "rack"), expectError: true},
+	}
+	for _, tc := range testcases {
+		actual, err := BuildPodGroup(testPod("p", map[string]string{common.ExtendResouceTypeOrin: "1"}, tc.annotations))
+		if (err != nil) != tc.expectError {
+			t.Errorf("test case %s, expect error %v, actual %v", tc.name, tc.expectError, err)
+			continue
+		}
+		if (actual == nil) != (tc.expected == nil) || (actual != nil && *actual != *tc.expected) {
+			t.Errorf("test case %s, expect %+v, actual %+v", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func TestBindPodGroup(t *testing.T) {
+	// board 0 has orin 1 and 2, board 1 has orin 1 2 3 4
+	node := powerTestNode("")
+	members := []*v1.Pod{}
+	for _, name := range []string{"a", "b", "c"} {
+		members = append(members, testPod(name, map[string]string{common.ExtendResouceTypeOrin: "1"}, groupAnnotations("perception", "3", GroupScopeBoard)))
+	}
+	other := testPod("other", map[string]string{common.ExtendResouceTypeOrinPrefix + "3": "1"}, nil)
+	clientset := fake.NewSimpleClientset(node, members[0], members[1], members[2], other)
+	mng := NewManager(NewScheduleCache(), clientset)
+	mng.AddNode(node)
+
+	// members are held until the whole group is seen
+	for i, pod := range members {
+		nodes, failed, _ := mng.Predicate([]string{"node-1"}, pod)
+		if i < 2 && (len(nodes) != 0 || !strings.Contains(failed["node-1"], "waiting for members")) {
+			t.Fatalf("expect member %s held, actual %v %v", pod.Name, nodes, failed)
+		}
+		if i == 2 && len(nodes) != 1 {
+			t.Fatalf("expect member %s fits, actual %v", pod.Name, failed)
+		}
+	}
+
+	// the last member places the group on board 1, the only board for all
+	if err := mng.Bind("node-1", members[2].Name, members[2].Namespace, members[2].UID); err != nil {
+		t.Fatal(err)
+	}
+	status := mng.GroupStatus("default/perception")
+	if status.Node != "node-1" || status.Board != 1 || status.Bound != 1 {
+		t.Fatalf("unexpected group status %+v", status)
+	}
+	if od := mng.GetNode("node-1").Allocatable[1]; !od.OrinSet().Equal(sets.NewInt(4)) {
+		t.Fatalf("expect orins reserved for members, allocatable %v", od.OrinSet().List())
+	}
+	// reserved orins are not available to other pods
+	if nodes, _, _ := mng.Predicate([]string{"node-1"}, other); len(nodes) != 0 {
+		t.Fatalf("expect orin 3 reserved, actual %v", nodes)
+	}
+
+	for _, pod := range members[:2] {
+		if err := mng.Bind("node-1", pod.Name, pod.Namespace, pod.UID); err != nil {
+			t.Fatal(err)
+		}
+		if bound := mng.GetNode("node-1").Pods[pod.UID]; bound.Annotations[common.AnnotationPodBindToBoard] != "1" {
+			t.Fatalf("expect member %s on board 1, actual %v", pod.Name, bound.Annotations)
+		}
+	}
+	if status := mng.GroupStatus("default/perception"); len(status.Reserved) != 0 || status.Bound != 3 {
+		t.Fatalf("expect reservation taken by members, actual %+v", status)
+	}
+	if od := mng.GetNode("node-1").Allocatable[1]; !od.OrinSet().Equal(sets.NewInt(4)) {
+		t.Fatalf("expect orin 4 of board 1 allocatable, actual %v", od.OrinSet().List())
+	}
+}
+
+func TestBindPodGroupFailed(t *testing.T) {
+	node := powerTestNode("")
+	members := []*v1.Pod{}
+	for _, name := range []string{"a", "b"} {
+		members = append(members, testPod(name, map[string]string{common.ExtendResouceTypeOrin: "1"}, groupAnnotations("perception", "2", GroupScopeBoard)))
+	}
+	clientset := fake.NewSimpleClientset(node, members[0], members[1])
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "binding" {
+			return false, nil, nil
+		}
+		return true, nil, fmt.Errorf("binding refused")
+	})
+	mng := NewManager(NewScheduleCache(), clientset)
+	mng.AddNode(node)
+	for _, pod := range members {
+		mng.Predicate([]string{"node-1"}, pod)
+	}
+
+	if err := mng.Bind("node-1", members[1].Name, members[1].Namespace, members[1].UID); err == nil {
+		t.Fatal("expect bind failed")
+	}
+	if status := mng.GroupStatus("default/perception"); status.Node != "" || len(status.Reserved) != 0 {
+		t.Fatalf("expect group unplaced, actual %+v", status)
+	}
+	if ni := mng.GetNode("node-1"); !ni.Allocatable.Equal(ni.Total) {
+		t.Fatalf("expect reservation released, allocatable %v", ni.Allocatable)
+	}
+}
+
+func TestBindGroupMemberFailed(t *testing.T) {
+	// board 0 has orin 1 and 2, board 1 has orin 1 2 3 4
+	node := powerTestNode("")
+	members := []*v1.Pod{}
+	for _, name := range []string{"a", "b", "c"} {
+		members = append(members, testPod(name, map[string]string{common.ExtendResouceTypeOrin: "1"}, groupAnnotations("perception", "3", GroupScopeBoard)))
+	}
+	other := testPod("other", map[string]string{common.ExtendResouceTypeOrinPrefix + "3": "1"}, nil)
+	clientset := fake.NewSimpleClientset(node, members[0], members[1], members[2], other)
+	refuse := false
+	clientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "binding" || !refuse {
+			return false, nil, nil
+		}
+		return true, nil, fmt.Errorf("binding refused")
+	})
+	mng := NewManager(NewScheduleCache(), clientset)
+	mng.AddNode(node)
+	for _, pod := range members {
+		mng.Predicate([]string{"node-1"}, pod)
+	}
+	if err := mng.Bind("node-1", members[2].Name, members[2].Namespace, members[2].UID); err != nil {
+		t.Fatal(err)
+	}
+	reserved := mng.GroupStatus("default/perception").Reserved
+
+	// binding of a member from the reservation fails
+	refuse = true
+	if err := mng.Bind("node-1", members[0].Name, members[0].Namespace, members[0].UID); err == nil {
+		t.Fatal("expect bind failed")
+	}
+	status := mng.GroupStatus("default/perception")
+	if status.Node != "node-1" || !reflect.DeepEqual(status.Reserved, reserved) || status.Bound != 1 {
+		t.Fatalf("expect orins of member reserved again %v, actual %+v", reserved, status)
+	}
+	if mng.WaitGroupMember(&PodGroup{Key: "default/perception"}, members[1], time.Minute) != 3 {
+		t.Fatalf("expect failed member waiting again")
+	}
+	if nodes, _, _ := mng.Predicate([]string{"node-1"}, other); len(nodes) != 0 {
+		t.Fatalf("expect orin 3 still reserved, actual %v", nodes)
+	}
+
+	refuse = false
+	for _, pod := range members[:2] {
+		if err := mng.Bind("node-1", pod.Name, pod.Namespace, pod.UID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if status := mng.GroupStatus("default/perception"); len(status.Reserved) != 0 || status.Bound != 3 {
+		t.Fatalf("expect reservation taken by members, actual %+v", status)
+	}
+}
+
+func TestExpirePodGroup(t *testing.T) {
+	node := powerTestNode("")
+	a := testPod("a", map[string]string{common.ExtendResouceTypeOrin: "1"}, groupAnnotations("perception", "2", ""))
+	b := testPod("b", map[string]string{common.ExtendResouceTypeOrin: "1"}, groupAnnotations("perception", "2", ""))
+	clientset := fake.NewSimpleClientset(node, a, b)
+	mng := NewManager(NewScheduleCache(), clientset)
+	mng.AddNode(node)
+
+	mng.Predicate([]string{"node-1"}, a)
+	if nodes, _, _ := mng.Predicate([]string{"node-1"}, b); len(nodes) != 1 {
+		t.Fatalf("expect group fits")
+	}
+	if err := mng.Bind("node-1", b.Name, b.Namespace, b.UID); err != nil {
+		t.Fatal(err)
+	}
+	if status := mng.GroupStatus("default/perception"); status.Node != "node-1" || len(status.Reserved) != 1 {
+		t.Fatalf("expect one orin reserved, actual %+v", status)
+	}
+
+	// a never shows up again
+	mng.ExpireGroups(time.Now().Add(DefaultGroupTimeout + time.Second))
+	if status := mng.GroupStatus("default/perception"); status.Node != "" || len(status.Reserved) != 0 {
+		t.Fatalf("expect reservation released, actual %+v", status)
+	}
+	ni := mng.GetNode("node-1")
+	free := 0
+	for _, od := range ni.Allocatable {
+		free += len(od)
+	}
+	if free != 5 {
+		t.Fatalf("expect only orin of b held, allocatable %v", ni.Allocatable)
+	}
+}
+
+func TestMemberAllocationScore(t *testing.T) {
+	testCases := []struct {
+		name   string
+		scores []int
+		expect int
+	}{
+		{name: "no result", expect: 0},
+		{name: "one board", scores: []int{3}, expect: 3},
+		{name: "several boards", scores: []int{4, 1, 1}, expect: 2},
+	}
+	for _, tc := range testCases {
+		alloc := &memberAllocation{}
+		for _, s := range tc.scores {
+			alloc.results = append(alloc.results, &AllocatorResult{score: s})
+		}
+		if actual := alloc.score(); actual != tc.expect {
+			t.Errorf("test case %s, expect score %d, actual %d", tc.name, tc.expect, actual)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package manager
+
+import (
+	"encoding/json"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/scheduler/manager/topo"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+// ParseInterconnect parse links between orins of every board in node
+// annotation, boards not in it have no links
+func ParseInterconnect(node *v1.Node) topo.Interconnect {
+	res := topo.Interconnect{}
+	val, ok := node.Annotations[common.AnnotationNodeOrinInterconnect]
+	if !ok {
+		return res
+	}
+	if err := json.Unmarshal([]byte(val), &res); err != nil {
+		klog.ErrorS(err, "invalid interconnect annotation", "node", node.Name, "value", val)
+		return topo.Interconnect{}
+	}
+	return res
+}
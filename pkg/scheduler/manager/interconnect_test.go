@@ -0,0 +1,65 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestTopologyAllocate(t *testing.T) {
+	// board 0 has orin 1 and 2, board 1 has orin 1 2 3 4
+	testcases := []struct {
+		name         string
+		interconnect string
+		request      *OrinRequest
+		expectBoard  int
+		expectOrins  sets.Int
+	}{
+		{
+			name:        "1.no links, pack like binpack",
+			request:     NewOrinRequest(sets.NewInt(), 2),
+			expectBoard: 0,
+			expectOrins: sets.NewInt(1, 2),
+		},
+		{
+			name:         "2.prefer the best linked orins",
+			interconnect: `{"0":[{"a":1,"b":2,"bw":1000}],"1":[{"a":1,"b":2,"bw":1000},{"a":3,"b":4,"bw":10000,"lat":5}]}`,
+			request:      NewOrinRequest(sets.NewInt(), 2),
+			expectBoard:  1,
+			expectOrins:  sets.NewInt(3, 4),
+		},
+		{
+			name:         "3.count with required orin",
+			interconnect: `{"1":[{"a":1,"b":2,"bw":1000},{"a":1,"b":3,"bw":10000},{"a":3,"b":4,"bw":10000}]}`,
+			request:      NewOrinRequest(sets.NewInt(3), 1),
+			expectBoard:  1,
+			expectOrins:  sets.NewInt(1, 3),
+		},
+		{
+			name:         "4.equal links, lower latency",
+			interconnect: `{"1":[{"a":1,"b":2,"bw":1000,"lat":50},{"a":2,"b":3,"bw":1000,"lat":10}]}`,
+			request:      NewOrinRequest(sets.NewInt(2), 1),
+			expectBoard:  1,
+			expectOrins:  sets.NewInt(2, 3),
+		},
+		{
+			name:         "5.invalid annotation",
+			interconnect: `[`,
+			request:      NewOrinRequest(sets.NewInt(), 3),
+			expectBoard:  1,
+			expectOrins:  sets.NewInt(1, 2, 3),
+		},
+	}
+	for _, tc := range testcases {
+		node := powerTestNode("")
+		if tc.interconnect != "" {
+			node.Annotations[common.AnnotationNodeOrinInterconnect] = tc.interconnect
+		}
+		res, _ := allocate(&TopologyAllocator{}, NewNodeInfo(node), tc.request)
+		if res.boardID != tc.expectBoard || !res.orins.Equal(tc.expectOrins) {
+			t.Errorf("test case %s, expect board %d orins %v, actual board %d orins %v", tc.name, tc.expectBoard, tc.expectOrins.List(), res.boardID, res.orins.List())
+		}
+	}
+}
@@ -10,10 +10,12 @@ import (
 	"github.com/avast/retry-go"
 	"github.com/superedge/orin-device-system/pkg/common"
 	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/klog/v2"
 )
 
@@ -24,21 +26,107 @@ type Manager interface {
 	Priority(node []string, pod *v1.Pod) []int
 	// Bind will update pod annotaion and update local cache
 	Bind(node string, name, namespace string, podUID types.UID) error
+	// Preempt return victims of every node whose release make pod fit
+	Preempt(pod *v1.Pod, nodeVictims map[string][]types.UID) map[string][]types.UID
 	// GetPodFromApiserver use clientset to get newest pod info, instead localcache
 	GetPodFromApiserver(podName, podNamespace string, podUID types.UID) (*v1.Pod, error)
+	// SetNamespacePolicy set default allocation policy of namespace, empty
+	// policy removes it
+	SetNamespacePolicy(namespace, policy string)
+	// SetPodDisruptionBudget set pod disruption budget of key, pods covered
+	// by it are not added to victims of preemption, nil pdb removes it
+	SetPodDisruptionBudget(key string, pdb *policyv1.PodDisruptionBudget)
 	Cache
 }
 
 func NewManager(c Cache, clientSet kubernetes.Interface) *manager {
 	return &manager{
-		Cache:     c,
-		ClientSet: clientSet,
+		Cache:        c,
+		ClientSet:    clientSet,
+		Policies:     NewPolicyRegistry(),
+		GroupTimeout: DefaultGroupTimeout,
+		wakes:        make(map[string]time.Time),
+		pdbs:         make(map[string]*policyv1.PodDisruptionBudget),
 	}
 }
 
 type manager struct {
 	Cache
 	ClientSet kubernetes.Interface
+	Policies  *PolicyRegistry
+	// Recorder report invalid policy of pod, nil means disable
+	Recorder record.EventRecorder
+	// GroupTimeout is how long pod group members are remembered and orins
+	// are reserved for them
+	GroupTimeout time.Duration
+
+	wakeLock sync.Mutex
+	// wakes is the last wake request time of node/board
+	wakes map[string]time.Time
+
+	pdbLock sync.RWMutex
+	// pdbs is pod disruption budgets by namespace/name
+	pdbs map[string]*policyv1.PodDisruptionBudget
+}
+
+func (m *manager) SetNamespacePolicy(namespace, policy string) {
+	m.Policies.SetNamespaceDefault(namespace, policy)
+}
+
+func (m *manager) SetPodDisruptionBudget(key string, pdb *policyv1.PodDisruptionBudget) {
+	m.pdbLock.Lock()
+	defer m.pdbLock.Unlock()
+	if pdb == nil {
+		delete(m.pdbs, key)
+		return
+	}
+	m.pdbs[key] = pdb
+}
+
+// resolvePolicy return allocation policy of pod, and report an event if it
+// is invalid
+func (m *manager) resolvePolicy(pod *v1.Pod) (*Policy, error) {
+	policy, err := m.Policies.Resolve(pod)
+	if err != nil {
+		klog.ErrorS(err, "invalid orin policy", "pod", klog.KObj(pod))
+		if m.Recorder != nil {
+			m.Recorder.Event(pod, v1.EventTypeWarning, ReasonInvalidOrinPolicy, err.Error())
+		}
+	}
+	return policy, err
+}
+
+// buildRequests return orin requests of every board of pod and its group,
+// and report an event if they are invalid
+func (m *manager) buildRequests(pod *v1.Pod) ([]*OrinRequest, *PodGroup, error) {
+	requests, err := BuildOrinRequests(pod)
+	var group *PodGroup
+	if err == nil {
+		group, err = BuildPodGroup(pod)
+	}
+	if err == nil && group != nil && group.Scope == GroupScopeBoard && len(requests) > 1 {
+		err = fmt.Errorf("pod group %s of %s scope can not request several boards", group.Key, GroupScopeBoard)
+	}
+	if err != nil {
+		klog.ErrorS(err, "invalid orin request", "pod", klog.KObj(pod))
+		if m.Recorder != nil {
+			m.Recorder.Event(pod, v1.EventTypeWarning, ReasonInvalidOrinRequest, err.Error())
+		}
+	}
+	return requests, group, err
+}
+
+// allocate return allocation of pod on node, members of a group are
+// allocated by allocateMember
+func (m *manager) allocate(allocator Allocator, ni *NodeInfo, requests []*OrinRequest, group *PodGroup) *memberAllocation {
+	if group == nil {
+		res, wake := allocateBoards(allocator, ni, requests)
+		if res == nil {
+			return nil
+		}
+		return &memberAllocation{results: res, wake: wake}
+	}
+	return allocateMember(allocator, ni, group, m.GroupStatus(group.Key), requests)
 }
 
 func (m *manager) GetPodFromApiserver(name, namespace string, podUID types.UID) (*v1.Pod, error) {
@@ -71,13 +159,33 @@ func (m *manager) Predicate(nodes []string, pod *v1.Pod) ([]string, map[string]s
 	failNodes := make(map[string]string, len(nodes))
 	var predicateResultLock sync.Mutex
 	var filteredLen int32
-	allocatorPolicy, ok := pod.Annotations[common.AnnotationPodBindOrinPolicy]
-	if !ok {
-		allocatorPolicy = AllocatorPolicyBinPack
+	// wakeNodes is nodes which fit after waking boards
+	wakeNodes := map[string][]int{}
+	policy, err := m.resolvePolicy(pod)
+	if err != nil {
+		// fail the pod on every node, kube-scheduler reports it as the reason
+		for _, nodeName := range nodes {
+			failNodes[nodeName] = fmt.Sprintf("invalid orin policy: %v", err)
+		}
+		return []string{}, failNodes, nil
+	}
+	orinRequests, group, err := m.buildRequests(pod)
+	if err != nil {
+		for _, nodeName := range nodes {
+			failNodes[nodeName] = fmt.Sprintf("invalid orin request: %v", err)
+		}
+		return []string{}, failNodes, nil
+	}
+	if group != nil {
+		// hold members until the whole group is seen
+		if seen := m.WaitGroupMember(group, pod, m.GroupTimeout); seen < group.MinMember {
+			for _, nodeName := range nodes {
+				failNodes[nodeName] = fmt.Sprintf("pod group %s waiting for members, %d/%d", group.Key, seen, group.MinMember)
+			}
+			return []string{}, failNodes, nil
+		}
 	}
-	allocator := AllocatorMap[allocatorPolicy]
 
-	orinRequest := BuildRequestOrinSet(pod)
 	checkNodes := func(i int) {
 		nodeName := nodes[i]
 		ni := m.Cache.GetNode(nodeName)
@@ -88,37 +196,62 @@ func (m *manager) Predicate(nodes []string, pod *v1.Pod) ([]string, map[string]s
 			return
 		}
 
-		res := allocator.Allocate(ni.Allocatable, orinRequest)
+		res := m.allocate(policy.Allocator, ni, orinRequests, group)
 		klog.V(6).InfoS("allocator info",
-			"policy", allocatorPolicy,
+			"policy", policy,
 			"node", nodeName,
 			"allocatable", ni.Allocatable,
-			"request", orinRequest,
+			"request", orinRequests,
 			"result", res,
 		)
 
-		if res.boardID != BoardIDNotFount {
+		switch {
+		case res != nil && len(res.wake) == 0:
 			filterdNodes[atomic.AddInt32(&filteredLen, 1)-1] = nodes[i]
-		} else {
+		case res != nil:
+			// node fits after a board wake, it is used only if no node fits now
+			predicateResultLock.Lock()
+			wakeNodes[nodeName] = res.wake
+			failNodes[nodeName] = fmt.Sprintf("%s fits after wake of powered off boards %v", nodeName, res.wake)
+			predicateResultLock.Unlock()
+		default:
 			predicateResultLock.Lock()
 			failNodes[nodeName] = nodeName + " has not enough resource"
 			predicateResultLock.Unlock()
 		}
 	}
 	Parallelize(16, len(nodes), checkNodes)
-	klog.V(6).InfoS("after Predicate", "nodes", filterdNodes, "podName", pod.Name)
-	return filterdNodes, failNodes, nil
+	if filteredLen == 0 && len(wakeNodes) != 0 {
+		// hold the pod without binding until the boards are powered on, the
+		// node annotation update makes kube-scheduler retry it
+		node := wakeNode(nodes, wakeNodes)
+		for _, boardID := range wakeNodes[node] {
+			if err := m.requestWake(node, boardID); err != nil {
+				klog.ErrorS(err, "request board wake error", "node", node, "board", boardID)
+			}
+		}
+		klog.InfoS("no powered on board fits, wake requested", "node", node, "boards", wakeNodes[node], "pod", klog.KObj(pod))
+		failNodes[node] = fmt.Sprintf("%s waiting for wake of powered off boards %v", node, wakeNodes[node])
+	}
+	klog.V(6).InfoS("after Predicate", "nodes", filterdNodes[:filteredLen], "podName", pod.Name)
+	return filterdNodes[:filteredLen], failNodes, nil
 }
 
 func (m *manager) Priority(nodes []string, pod *v1.Pod) []int {
-	allocatorPolicy, ok := pod.Annotations[common.AnnotationPodBindOrinPolicy]
-	if !ok {
-		allocatorPolicy = AllocatorPolicyBinPack
-	}
-
 	scores := make([]int, len(nodes))
-	allocator := AllocatorMap[allocatorPolicy]
-	orinRequest := BuildRequestOrinSet(pod)
+	policy, err := m.Policies.Resolve(pod)
+	if err != nil {
+		// already reported by Predicate
+		return scores
+	}
+	orinRequests, err := BuildOrinRequests(pod)
+	if err != nil {
+		return scores
+	}
+	group, err := BuildPodGroup(pod)
+	if err != nil {
+		return scores
+	}
 	checkNodes := func(i int) {
 		nodeName := nodes[i]
 		ni := m.Cache.GetNode(nodeName)
@@ -126,10 +259,11 @@ func (m *manager) Priority(nodes []string, pod *v1.Pod) []int {
 			scores[i] = 0
 			return
 		}
-		res := allocator.Allocate(ni.Allocatable, orinRequest)
-		if res.boardID != BoardIDNotFount {
-			scores[i] = res.score
+		res := m.allocate(policy.Allocator, ni, orinRequests, group)
+		if res != nil && len(res.wake) == 0 {
+			scores[i] = res.score()
 		} else {
+			// node which needs a board wake is the last choice
 			scores[i] = 0
 		}
 	}
@@ -140,6 +274,24 @@ func (m *manager) Priority(nodes []string, pod *v1.Pod) []int {
 	return scores
 }
 
+// forgetPod forget pod assumed by Bind, orins of a member taken from the
+// group reservation go back to it, and a group placed by the member is
+// unreserved
+func (m *manager) forgetPod(pod *v1.Pod, node string, alloc *memberAllocation, group *PodGroup) {
+	var err error
+	if alloc.fromReservation {
+		err = m.ForgetGroupPod(pod, node, group.Key)
+	} else {
+		err = m.ForgetPod(pod, node)
+	}
+	if err != nil {
+		klog.ErrorS(err, "forgot pod error", "pod name", pod.Name, "node name", node)
+	}
+	if alloc.place {
+		m.UnreserveGroup(group.Key)
+	}
+}
+
 func (m *manager) Bind(node string, podName, podNamespace string, podUID types.UID) error {
 
 	ni := m.GetNode(node)
@@ -147,32 +299,69 @@ func (m *manager) Bind(node string, podName, podNamespace string, podUID types.U
 		return fmt.Errorf("could not find bind node %s", node)
 	}
 	var newPod *v1.Pod
+	// allocation of the last attempt, the group reservation is released if
+	// the binding fails
+	var alloc *memberAllocation
+	var group *PodGroup
 	bindPod := func() error {
 		pod, err := m.GetPodFromApiserver(podName, podNamespace, podUID)
 		if err != nil {
 			return err
 		}
 
-		AllocatorPolicy, ok := pod.Annotations[common.AnnotationPodBindOrinPolicy]
-		if !ok {
-			AllocatorPolicy = AllocatorPolicyBinPack
+		policy, err := m.resolvePolicy(pod)
+		if err != nil {
+			return retry.Unrecoverable(err)
+		}
+		var orinRequests []*OrinRequest
+		orinRequests, group, err = m.buildRequests(pod)
+		if err != nil {
+			return retry.Unrecoverable(err)
 		}
-		allocator := AllocatorMap[AllocatorPolicy]
-		orinRequest := BuildRequestOrinSet(pod)
-		res := allocator.Allocate(ni.Allocatable, orinRequest)
-		if res.boardID == BoardIDNotFount {
+		alloc = m.allocate(policy.Allocator, ni, orinRequests, group)
+		if alloc == nil {
 			return fmt.Errorf("could not find board %s", node)
 		}
-		newPod = AddPodBindAnnotation(pod, res.boardID)
+		if wake := alloc.wake; len(wake) != 0 {
+			// Predicate does not pass a node which needs a wake, but boards
+			// may be powered off since then, hold the pod until they are
+			// powered on, it will be scheduled again by kube-scheduler
+			for _, boardID := range wake {
+				if err := m.requestWake(node, boardID); err != nil {
+					return err
+				}
+			}
+			klog.InfoS("board powered off, wake requested", "node", node, "boards", wake, "pod", klog.KObj(pod))
+			return retry.Unrecoverable(fmt.Errorf("boards %v of node %s are powered off, waiting for wake", wake, node))
+		}
+		res := alloc.results
+		newPod = AddPodBindAnnotation(pod, res[0].boardID)
+		if _, ok := pod.Annotations[common.AnnotationPodRequestBoards]; ok {
+			newPod.Annotations[common.AnnotationPodBindBoards] = FormatBoardOrins(resultOrins(res))
+		} else if orinRequests[0].Count > 0 {
+			newPod.Annotations[common.AnnotationPodBindOrins] = FormatOrins(res[0].orins.Difference(orinRequests[0].Orins))
+		}
 
-		if err := m.AssumePod(newPod, node); err != nil {
+		if alloc.place {
+			// reserve orins for the other members before this one is bound
+			if err := m.ReserveGroup(group.Key, node, alloc.board, alloc.reserve, m.GroupTimeout); err != nil {
+				return err
+			}
+		}
+		if alloc.fromReservation {
+			err = m.AssumeGroupPod(newPod, node, group.Key)
+		} else {
+			err = m.AssumePod(newPod, node)
+		}
+		if err != nil {
+			if alloc.place {
+				m.UnreserveGroup(group.Key)
+			}
 			return err
 		}
 
 		if _, err := m.ClientSet.CoreV1().Pods(newPod.Namespace).Update(context.Background(), newPod, metav1.UpdateOptions{}); err != nil {
-			if err := m.ForgetPod(pod, node); err != nil {
-				klog.ErrorS(err, "forgot pod error", "pod name", pod.Name, "node name", node)
-			}
+			m.forgetPod(newPod, node, alloc, group)
 			return err
 		}
 		return nil
@@ -188,9 +377,7 @@ func (m *manager) Bind(node string, podName, podNamespace string, podUID types.U
 			Name: node,
 		},
 	}, metav1.CreateOptions{}); err != nil {
-		if err := m.ForgetPod(newPod, node); err != nil {
-			klog.ErrorS(err, "forgot pod error", "pod name", newPod.Name, "node name", node)
-		}
+		m.forgetPod(newPod, node, alloc, group)
 		recoverPod := RemovePodBindAnnotation(newPod)
 		if _, err := m.ClientSet.CoreV1().Pods(newPod.Namespace).Update(context.Background(), recoverPod, metav1.UpdateOptions{}); err != nil {
 			klog.ErrorS(err, "remove pod bin annotation error", "podname", recoverPod.Name)
@@ -198,6 +385,10 @@ func (m *manager) Bind(node string, podName, podNamespace string, podUID types.U
 		}
 		return err
 	}
+	// pod is forgot if informer does not see it bound in time
+	if err := m.FinishBinding(newPod); err != nil {
+		klog.ErrorS(err, "finish binding error", "pod name", newPod.Name, "node name", node)
+	}
 	klog.V(6).Infof("update pod %s to pods cache", podName)
 
 	return nil
@@ -8,8 +8,27 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
+// testPod build pod name of namespace default, whose single container limits
+// the resources by quantity string
+func testPod(name string, limits map[string]string, annotations map[string]string) *v1.Pod {
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	resources := v1.ResourceList{}
+	for res, quantity := range limits {
+		resources[v1.ResourceName(res)] = resource.MustParse(quantity)
+	}
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(name), Annotations: annotations},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "test", Resources: v1.ResourceRequirements{Limits: resources}}},
+		},
+	}
+}
+
 func TestPredicate(t *testing.T) {
 
 	scache := NewScheduleCache()
@@ -126,4 +145,14 @@ func TestPriority(t *testing.T) {
 		}
 
 	}
+
+	// spread prefers the node with more free orins
+	pod.Annotations = map[string]string{common.AnnotationPodBindOrinPolicy: AllocatorPolicySpread}
+	res = mng.Priority([]string{"node-1", "node-2"}, pod)
+	expect = []int{10, 5}
+	for i, s := range res {
+		if s != expect[i] {
+			t.Fatalf("failed spread priority, expect %d, actual %d", expect[i], s)
+		}
+	}
 }
@@ -0,0 +1,196 @@
+package manager
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ReasonInvalidOrinPolicy is the pod event of an unknown or invalid
+	// allocation policy
+	ReasonInvalidOrinPolicy = "InvalidOrinPolicy"
+
+	// policyVersionSeparator split policy name and version, e.g. spread@v1,
+	// a policy without version is its latest version
+	policyVersionSeparator = "@"
+)
+
+var (
+	policyNamePattern    = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	policyVersionPattern = regexp.MustCompile(`^v([1-9][0-9]*)$`)
+)
+
+// Policy is a named and versioned allocator
+type Policy struct {
+	Name    string
+	Version string
+	Allocator
+}
+
+func (p *Policy) String() string {
+	return p.Name + policyVersionSeparator + p.Version
+}
+
+// PolicyRegistry resolve allocation policy of pod, from pod annotation,
+// namespace annotation or the scheduler default in order
+type PolicyRegistry struct {
+	lock sync.RWMutex
+	// policies is policy name to its versions
+	policies map[string]map[string]*Policy
+	// latest is policy name to its highest version
+	latest        map[string]*Policy
+	defaultPolicy string
+	// namespaceDefaults is namespace to its policy annotation
+	namespaceDefaults map[string]string
+}
+
+// NewPolicyRegistry return registry of builtin policies, binpack is the
+// default
+func NewPolicyRegistry() *PolicyRegistry {
+	r := &PolicyRegistry{
+		policies:          make(map[string]map[string]*Policy),
+		latest:            make(map[string]*Policy),
+		defaultPolicy:     AllocatorPolicyBinPack,
+		namespaceDefaults: make(map[string]string),
+	}
+	r.MustRegister(AllocatorPolicyBinPack, "v1", &BinpackAllocator{})
+	r.MustRegister(AllocatorPolicySpread, "v1", &SpreadAllocator{})
+	r.MustRegister(AllocatorPolicyTopology, "v1", &TopologyAllocator{})
+	return r
+}
+
+// Register add allocator as version of policy name, version is like v1
+func (r *PolicyRegistry) Register(name, version string, allocator Allocator) error {
+	if !policyNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid policy name %q, it must be lowercase alphanumeric or '-'", name)
+	}
+	m := policyVersionPattern.FindStringSubmatch(version)
+	if m == nil {
+		return fmt.Errorf("invalid version %q of policy %s, it must be like v1", version, name)
+	}
+	if allocator == nil {
+		return fmt.Errorf("nil allocator of policy %s", name)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if _, ok := r.policies[name][version]; ok {
+		return fmt.Errorf("policy %s%s%s already registered", name, policyVersionSeparator, version)
+	}
+	if r.policies[name] == nil {
+		r.policies[name] = make(map[string]*Policy)
+	}
+	p := &Policy{Name: name, Version: version, Allocator: allocator}
+	r.policies[name][version] = p
+	if latest, ok := r.latest[name]; !ok || versionNumber(latest.Version) < versionNumber(version) {
+		r.latest[name] = p
+	}
+	return nil
+}
+
+func (r *PolicyRegistry) MustRegister(name, version string, allocator Allocator) {
+	if err := r.Register(name, version, allocator); err != nil {
+		panic(err)
+	}
+}
+
+func versionNumber(version string) int {
+	n, _ := strconv.Atoi(strings.TrimPrefix(version, "v"))
+	return n
+}
+
+// Lookup return policy of name or name@version
+func (r *PolicyRegistry) Lookup(policy string) (*Policy, error) {
+	name, version := policy, ""
+	if i := strings.Index(policy, policyVersionSeparator); i >= 0 {
+		name, version = policy[:i], policy[i+len(policyVersionSeparator):]
+	}
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	versions, ok := r.policies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown policy %q, known policies: %s", policy, strings.Join(r.names(), ", "))
+	}
+	if version == "" {
+		return r.latest[name], nil
+	}
+	p, ok := versions[version]
+	if !ok {
+		return nil, fmt.Errorf("unknown version %q of policy %s", version, name)
+	}
+	return p, nil
+}
+
+// Names return sorted names of registered policies
+func (r *PolicyRegistry) Names() []string {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.names()
+}
+
+func (r *PolicyRegistry) names() []string {
+	res := make([]string, 0, len(r.policies))
+	for name := range r.policies {
+		res = append(res, name)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// SetDefault set the scheduler default policy, it must be registered
+func (r *PolicyRegistry) SetDefault(policy string) error {
+	if _, err := r.Lookup(policy); err != nil {
+		return err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.defaultPolicy = policy
+	return nil
+}
+
+// SetNamespaceDefault set the policy annotation of namespace, empty policy
+// removes it, an invalid policy fails pods in the namespace without one
+func (r *PolicyRegistry) SetNamespaceDefault(namespace, policy string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if policy == "" {
+		delete(r.namespaceDefaults, namespace)
+		return
+	}
+	if r.namespaceDefaults[namespace] != policy {
+		klog.InfoS("namespace default orin policy", "namespace", namespace, "policy", policy)
+	}
+	r.namespaceDefaults[namespace] = policy
+}
+
+// Resolve return allocation policy of pod, the error tells which annotation
+// has the invalid policy
+func (r *PolicyRegistry) Resolve(pod *v1.Pod) (*Policy, error) {
+	if policy, ok := pod.Annotations[common.AnnotationPodBindOrinPolicy]; ok {
+		p, err := r.Lookup(policy)
+		if err != nil {
+			return nil, fmt.Errorf("pod annotation %s: %v", common.AnnotationPodBindOrinPolicy, err)
+		}
+		return p, nil
+	}
+	r.lock.RLock()
+	policy, ok := r.namespaceDefaults[pod.Namespace]
+	defaultPolicy := r.defaultPolicy
+	r.lock.RUnlock()
+	if ok {
+		p, err := r.Lookup(policy)
+		if err != nil {
+			return nil, fmt.Errorf("namespace %s annotation %s: %v", pod.Namespace, common.AnnotationNamespaceOrinPolicy, err)
+		}
+		return p, nil
+	}
+	return r.Lookup(defaultPolicy)
+}
@@ -0,0 +1,148 @@
+package manager
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestPolicyRegistry(t *testing.T) {
+	r := NewPolicyRegistry()
+	r.MustRegister(AllocatorPolicySpread, "v2", &BinpackAllocator{})
+	if err := r.Register(AllocatorPolicySpread, "v2", &SpreadAllocator{}); err == nil {
+		t.Fatalf("expect duplicate register error")
+	}
+	for _, invalid := range [][2]string{{"Spread", "v1"}, {"spread", "1"}, {"spread", "v0"}, {"", "v1"}} {
+		if err := r.Register(invalid[0], invalid[1], &SpreadAllocator{}); err == nil {
+			t.Errorf("expect register %s %s error", invalid[0], invalid[1])
+		}
+	}
+	r.SetNamespaceDefault("batch", AllocatorPolicySpread+"@v1")
+	r.SetNamespaceDefault("broken", "fastest")
+
+	testcases := []struct {
+		name        string
+		namespace   string
+		annotations map[string]string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:      "1.scheduler default",
+			namespace: "default",
+			expected:  "binpack@v1",
+		},
+		{
+			name:        "2.pod policy without version is the latest",
+			namespace:   "default",
+			annotations: map[string]string{common.AnnotationPodBindOrinPolicy: AllocatorPolicySpread},
+			expected:    "spread@v2",
+		},
+		{
+			name:        "3.pod policy with version",
+			namespace:   "default",
+			annotations: map[string]string{common.AnnotationPodBindOrinPolicy: "spread@v1"},
+			expected:    "spread@v1",
+		},
+		{
+			name:      "4.namespace default",
+			namespace: "batch",
+			expected:  "spread@v1",
+		},
+		{
+			name:        "5.pod policy overrides namespace default",
+			namespace:   "batch",
+			annotations: map[string]string{common.AnnotationPodBindOrinPolicy: AllocatorPolicyBinPack},
+			expected:    "binpack@v1",
+		},
+		{
+			name:        "6.unknown pod policy",
+			namespace:   "default",
+			annotations: map[string]string{common.AnnotationPodBindOrinPolicy: "fastest"},
+			expectedErr: `pod annotation superedge.io/pod-bind-orin-policy: unknown policy "fastest", known policies: binpack, spread, topology`,
+		},
+		{
+			name:        "7.unknown pod policy version",
+			namespace:   "default",
+			annotations: map[string]string{common.AnnotationPodBindOrinPolicy: "binpack@v3"},
+			expectedErr: `unknown version "v3" of policy binpack`,
+		},
+		{
+			name:        "8.unknown namespace policy",
+			namespace:   "broken",
+			expectedErr: "namespace broken annotation superedge.io/orin-policy",
+		},
+	}
+
+	for _, tc := range testcases {
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: tc.namespace, Annotations: tc.annotations}}
+		p, err := r.Resolve(pod)
+		if tc.expectedErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("test case %s, expect error %q, actual %v", tc.name, tc.expectedErr, err)
+			}
+			continue
+		}
+		if err != nil || p.String() != tc.expected {
+			t.Errorf("test case %s, expect policy %s, actual %v, %v", tc.name, tc.expected, p, err)
+		}
+	}
+
+	if err := r.SetDefault("fastest"); err == nil {
+		t.Errorf("expect unknown default policy error")
+	}
+	r.SetNamespaceDefault("batch", "")
+	if p, _ := r.Resolve(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "batch"}}); p.String() != "binpack@v1" {
+		t.Errorf("expect scheduler default after namespace default removed, actual %v", p)
+	}
+}
+
+func TestPredicateInvalidPolicy(t *testing.T) {
+	mng := NewManager(NewScheduleCache(), nil)
+	recorder := record.NewFakeRecorder(10)
+	mng.Recorder = recorder
+
+	q, _ := resource.ParseQuantity("1111")
+	mng.AddNode(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{Capacity: v1.ResourceList{
+			v1.ResourceName(common.ExtendResouceTypeBoardPrefix + "0"): q,
+		}},
+	})
+	reqQuan, _ := resource.ParseQuantity("1")
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "pod-1",
+			Namespace:   "default",
+			Annotations: map[string]string{common.AnnotationPodBindOrinPolicy: "fastest"},
+		},
+		Spec: v1.PodSpec{Containers: []v1.Container{{
+			Name: "test",
+			Resources: v1.ResourceRequirements{Limits: v1.ResourceList{
+				v1.ResourceName(common.ExtendResouceTypeOrinPrefix + "1"): reqQuan,
+			}},
+		}}},
+	}
+
+	nodes, failNodes, err := mng.Predicate([]string{"node-1"}, pod)
+	if err != nil || len(nodes) != 0 || !strings.Contains(failNodes["node-1"], `unknown policy "fastest"`) {
+		t.Fatalf("expect node-1 failed by invalid policy, actual %v, %v, %v", nodes, failNodes, err)
+	}
+	select {
+	case e := <-recorder.Events:
+		if !strings.Contains(e, ReasonInvalidOrinPolicy) {
+			t.Errorf("unexpected event %s", e)
+		}
+	default:
+		t.Errorf("expect %s event", ReasonInvalidOrinPolicy)
+	}
+	if scores := mng.Priority([]string{"node-1"}, pod); scores[0] != 0 {
+		t.Errorf("expect zero score of invalid policy, actual %v", scores)
+	}
+}
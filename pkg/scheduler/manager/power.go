@@ -0,0 +1,181 @@
+package manager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	"github.com/superedge/orin-device-system/pkg/scheduler/manager/topo"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// wakeRequestInterval is the minimal interval of wake requests of a board
+const wakeRequestInterval = 30 * time.Second
+
+// WakeBoardAnnotation return node annotation asking device plugin to power
+// on the board
+func WakeBoardAnnotation(boardID int) string {
+	return fmt.Sprintf("%s%d", common.AnnotationNodeOrinWakeBoardPrefix, boardID)
+}
+
+// ParseBoardPower parse board power states in node annotation, boards not in
+// it are powered on
+func ParseBoardPower(node *v1.Node) map[int]string {
+	res := map[int]string{}
+	val, ok := node.Annotations[common.AnnotationNodeOrinBoardPower]
+	if !ok {
+		return res
+	}
+	states := map[string]string{}
+	if err := json.Unmarshal([]byte(val), &states); err != nil {
+		klog.ErrorS(err, "invalid board power annotation", "node", node.Name, "value", val)
+		return res
+	}
+	for k, state := range states {
+		boardID, err := strconv.Atoi(k)
+		if err != nil {
+			klog.ErrorS(err, "invalid board id in power annotation", "node", node.Name, "board", k)
+			continue
+		}
+		res[boardID] = state
+	}
+	return res
+}
+
+// poweredOffBoards return boards need a wake before use, waking boards are
+// included as their socs are not up yet, and so are boards powering off which
+// device plugin is about to switch off
+func poweredOffBoards(node *v1.Node) sets.Int {
+	res := sets.NewInt()
+	for boardID, state := range ParseBoardPower(node) {
+		if state != common.BoardPowerOn {
+			res.Insert(boardID)
+		}
+	}
+	return res
+}
+
+// allocate prefer powered on boards, and fall back to boards available on
+// wake, wake is true if the board allocated is powered off
+func allocate(allocator Allocator, ni *NodeInfo, orinRequest *OrinRequest) (*AllocatorResult, bool) {
+	if ni.PoweredOff.Len() == 0 {
+		return allocateOn(allocator, ni.Allocatable, ni.Links, orinRequest), false
+	}
+	powered := topo.NewBoardDetails()
+	for boardID, od := range ni.Allocatable {
+		if !ni.PoweredOff.Has(boardID) {
+			powered[boardID] = od
+		}
+	}
+	if res := allocateOn(allocator, powered, ni.Links, orinRequest); res.boardID != BoardIDNotFount {
+		return res, false
+	}
+	res := allocateOn(allocator, ni.Allocatable, ni.Links, orinRequest)
+	return res, res.boardID != BoardIDNotFount
+}
+
+// allocateBoards allocate every request on a different board of node, boards
+// to wake are the powered off boards allocated, nil results if node does not
+// fit
+func allocateBoards(allocator Allocator, ni *NodeInfo, requests []*OrinRequest) ([]*AllocatorResult, []int) {
+	if len(requests) == 1 {
+		res, wake := allocate(allocator, ni, requests[0])
+		if res.boardID == BoardIDNotFount {
+			return nil, nil
+		}
+		if wake {
+			return []*AllocatorResult{res}, []int{res.boardID}
+		}
+		return []*AllocatorResult{res}, nil
+	}
+	results, wake := allocateEach(allocator, ni, requests, sets.NewInt())
+	if results == nil {
+		return nil, nil
+	}
+	return results, wake.List()
+}
+
+// allocateEach allocate requests in order on boards not used, and try the
+// next best board of a request if the rest do not fit on the others
+func allocateEach(allocator Allocator, ni *NodeInfo, requests []*OrinRequest, used sets.Int) ([]*AllocatorResult, sets.Int) {
+	if len(requests) == 0 {
+		return []*AllocatorResult{}, sets.NewInt()
+	}
+	excluded := sets.NewInt(used.UnsortedList()...)
+	for {
+		rest := &NodeInfo{Allocatable: topo.NewBoardDetails(), PoweredOff: ni.PoweredOff, Links: ni.Links}
+		for boardID, od := range ni.Allocatable {
+			if !excluded.Has(boardID) {
+				rest.Allocatable[boardID] = od
+			}
+		}
+		res, wake := allocate(allocator, rest, requests[0])
+		if res.boardID == BoardIDNotFount {
+			return nil, nil
+		}
+		if others, othersWake := allocateEach(allocator, ni, requests[1:], used.Union(sets.NewInt(res.boardID))); others != nil {
+			if wake {
+				othersWake.Insert(res.boardID)
+			}
+			return append([]*AllocatorResult{res}, others...), othersWake
+		}
+		excluded.Insert(res.boardID)
+	}
+}
+
+// allocateOn give the node interconnect to allocator aware of it
+func allocateOn(allocator Allocator, canAlloc topo.BoardDetails, links topo.Interconnect, orinRequest *OrinRequest) *AllocatorResult {
+	if ta, ok := allocator.(TopologyAwareAllocator); ok {
+		return ta.AllocateOnTopology(canAlloc, links, orinRequest)
+	}
+	return allocator.Allocate(canAlloc, orinRequest)
+}
+
+// wakeNode return the node to wake for pod, which needs the fewest boards
+// powered on, nodes is in the order of kube-scheduler
+func wakeNode(nodes []string, wakeNodes map[string][]int) string {
+	res := ""
+	for _, node := range nodes {
+		wake, ok := wakeNodes[node]
+		if ok && (res == "" || len(wake) < len(wakeNodes[res])) {
+			res = node
+		}
+	}
+	return res
+}
+
+// requestWake annotate node to ask device plugin powering on board, a board
+// is requested at most once in wakeRequestInterval, as every pending pod
+// asks again on each scheduling attempt
+func (m *manager) requestWake(node string, boardID int) error {
+	key := fmt.Sprintf("%s/%d", node, boardID)
+	now := time.Now()
+	m.wakeLock.Lock()
+	defer m.wakeLock.Unlock()
+	for k, t := range m.wakes {
+		if now.Sub(t) >= wakeRequestInterval {
+			delete(m.wakes, k)
+		}
+	}
+	if _, ok := m.wakes[key]; ok {
+		return nil
+	}
+	patch, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{WakeBoardAnnotation(boardID): time.Now().Format(time.RFC3339)},
+		},
+	})
+	if _, err := m.ClientSet.CoreV1().Nodes().Patch(context.Background(), node, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return err
+	}
+	m.wakes[key] = now
+	return nil
+}
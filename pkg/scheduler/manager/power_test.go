@@ -0,0 +1,144 @@
+package manager
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func powerTestNode(power string) *v1.Node {
+	// board 0 has orin 1 and 2, board 1 has orin 1 2 3 4
+	q0, _ := resource.ParseQuantity("11")
+	q1, _ := resource.ParseQuantity("1111")
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1", Annotations: map[string]string{}},
+		Status: v1.NodeStatus{
+			Capacity: v1.ResourceList{
+				v1.ResourceName(common.ExtendResouceTypeBoardPrefix + "0"): q0,
+				v1.ResourceName(common.ExtendResouceTypeBoardPrefix + "1"): q1,
+			},
+		},
+	}
+	if power != "" {
+		node.Annotations[common.AnnotationNodeOrinBoardPower] = power
+	}
+	return node
+}
+
+func TestAllocateWithPower(t *testing.T) {
+	testcases := []struct {
+		name        string
+		power       string
+		request     sets.Int
+		expectBoard int
+		expectWake  bool
+	}{
+		{
+			name:        "1.all boards powered on",
+			request:     sets.NewInt(1),
+			expectBoard: 0,
+		},
+		{
+			name:        "2.prefer powered on board",
+			power:       `{"0":"off","1":"on"}`,
+			request:     sets.NewInt(1),
+			expectBoard: 1,
+		},
+		{
+			name:        "3.only powered off board fits",
+			power:       `{"1":"off"}`,
+			request:     sets.NewInt(3),
+			expectBoard: 1,
+			expectWake:  true,
+		},
+		{
+			name:        "4.waking board still needs wake",
+			power:       `{"1":"waking"}`,
+			request:     sets.NewInt(4),
+			expectBoard: 1,
+			expectWake:  true,
+		},
+		{
+			name:        "5.no board fits",
+			power:       `{"1":"off"}`,
+			request:     sets.NewInt(5),
+			expectBoard: BoardIDNotFount,
+		},
+		{
+			name:        "6.invalid annotation means powered on",
+			power:       `off`,
+			request:     sets.NewInt(1),
+			expectBoard: 0,
+		},
+	}
+	for _, tc := range testcases {
+		ni := NewNodeInfo(powerTestNode(tc.power))
+		res, wake := allocate(&BinpackAllocator{}, ni, NewOrinRequest(tc.request, 0))
+		if res.boardID != tc.expectBoard || wake != tc.expectWake {
+			t.Errorf("test case %s, expect board %d wake %v, actual board %d wake %v", tc.name, tc.expectBoard, tc.expectWake, res.boardID, wake)
+		}
+	}
+}
+
+func TestUpdateNodePower(t *testing.T) {
+	scache := NewScheduleCache()
+	old := powerTestNode("")
+	scache.AddNode(old)
+	scache.UpdateNode(old, powerTestNode(`{"1":"off"}`))
+	if ni := scache.GetNode("node-1"); !ni.PoweredOff.Equal(sets.NewInt(1)) {
+		t.Fatalf("expect board 1 powered off, actual %v", ni.PoweredOff.List())
+	}
+}
+
+func TestBindWakeBoard(t *testing.T) {
+	node := powerTestNode(`{"0":"off","1":"off"}`)
+	pod := testPod("pod-1", map[string]string{common.ExtendResouceTypeOrinPrefix + "1": "1"}, nil)
+	clientset := fake.NewSimpleClientset(node, pod)
+	mng := NewManager(NewScheduleCache(), clientset)
+	mng.AddNode(node)
+
+	if err := mng.Bind("node-1", pod.Name, pod.Namespace, pod.UID); err == nil {
+		t.Fatalf("expect bind to powered off board held")
+	}
+	if mng.KnownPod(pod) {
+		t.Fatalf("expect pod not assumed before board wakes")
+	}
+	newNode, _ := clientset.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+	if _, ok := newNode.Annotations[WakeBoardAnnotation(0)]; !ok {
+		t.Fatalf("expect wake of board 0 requested, annotations %v", newNode.Annotations)
+	}
+}
+
+func TestPredicateWakeBoard(t *testing.T) {
+	node := powerTestNode(`{"0":"off","1":"powering-off"}`)
+	pod := testPod("pod-1", map[string]string{common.ExtendResouceTypeOrinPrefix + "1": "1"}, nil)
+	clientset := fake.NewSimpleClientset(node, pod)
+	mng := NewManager(NewScheduleCache(), clientset)
+	mng.AddNode(node)
+
+	for i := 0; i < 2; i++ {
+		nodes, failNodes, err := mng.Predicate([]string{"node-1"}, pod)
+		if err != nil || len(nodes) != 0 {
+			t.Fatalf("expect pod held before board wakes, nodes %v, err %v", nodes, err)
+		}
+		if reason := failNodes["node-1"]; !strings.Contains(reason, "waiting for wake") {
+			t.Fatalf("expect waiting for wake reason, actual %q", reason)
+		}
+	}
+	patches := 0
+	for _, action := range clientset.Actions() {
+		if action.GetVerb() == "patch" {
+			patches++
+		}
+	}
+	if patches != 1 {
+		t.Fatalf("expect one wake request of repeated attempts, actual %d", patches)
+	}
+}
@@ -0,0 +1,178 @@
+package manager
+
+import (
+	"math"
+	"sort"
+
+	"github.com/superedge/orin-device-system/pkg/scheduler/manager/topo"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// maxPreemptCandidates bound the pods searched for victims on a node, the
+// lowest priority ones are searched
+const maxPreemptCandidates = 16
+
+func podPriority(pod *v1.Pod) int32 {
+	if pod.Spec.Priority != nil {
+		return *pod.Spec.Priority
+	}
+	return 0
+}
+
+// Preempt return victims of every node whose release make the orin request
+// of pod fit. Victims chosen by kube-scheduler are kept, since they may be
+// needed for other resources, and the fewest lower priority pods holding
+// orins are added to them. Pods covered by a pod disruption budget are never
+// added, so the violations counted by kube-scheduler stay right. Nodes pod
+// can not fit on are dropped.
+func (m *manager) Preempt(pod *v1.Pod, nodeVictims map[string][]types.UID) map[string][]types.UID {
+	res := map[string][]types.UID{}
+	policy, err := m.Policies.Resolve(pod)
+	if err != nil {
+		return res
+	}
+	requests, err := BuildOrinRequests(pod)
+	if err != nil {
+		return res
+	}
+	for node, victims := range nodeVictims {
+		ni := m.GetNode(node)
+		if ni == nil {
+			continue
+		}
+		added, ok := preemptOn(policy.Allocator, ni, requests, podPriority(pod), victims, m.pdbCovered)
+		if !ok {
+			klog.V(4).InfoS("pod does not fit on node by preemption", "pod", klog.KObj(pod), "node", node)
+			continue
+		}
+		res[node] = append(append([]types.UID{}, victims...), added...)
+	}
+	klog.V(6).InfoS("after Preempt", "victims", res, "podName", pod.Name)
+	return res
+}
+
+// pdbCovered return true if pod is selected by a pod disruption budget, a pdb
+// with empty selector selects nothing as kube-scheduler treats it
+func (m *manager) pdbCovered(pod *v1.Pod) bool {
+	m.pdbLock.RLock()
+	defer m.pdbLock.RUnlock()
+	for _, pdb := range m.pdbs {
+		if pdb.Namespace != pod.Namespace {
+			continue
+		}
+		selector, err := metav1.LabelSelectorAsSelector(pdb.Spec.Selector)
+		if err != nil || selector.Empty() {
+			continue
+		}
+		if selector.Matches(labels.Set(pod.Labels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// preemptOn return the fewest pods of lower priority than priority, besides
+// victims, whose release make requests fit on ni. Of the same number, pods of
+// lower priority are preferred. Pods protected are never chosen.
+func preemptOn(allocator Allocator, ni *NodeInfo, requests []*OrinRequest, priority int32, victims []types.UID, protected func(*v1.Pod) bool) ([]types.UID, bool) {
+	freed := map[int]sets.Int{}
+	chosen := sets.NewString()
+	for _, uid := range victims {
+		chosen.Insert(string(uid))
+		if pod, ok := ni.Pods[uid]; ok {
+			mergeBoardOrins(freed, PodBoardOrins(pod))
+		}
+	}
+	candidates := []*v1.Pod{}
+	for uid, pod := range ni.Pods {
+		if chosen.Has(string(uid)) || podPriority(pod) >= priority || len(PodBoardOrins(pod)) == 0 || protected(pod) {
+			continue
+		}
+		candidates = append(candidates, pod)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if pi, pj := podPriority(candidates[i]), podPriority(candidates[j]); pi != pj {
+			return pi < pj
+		}
+		return candidates[i].UID < candidates[j].UID
+	})
+	if len(candidates) > maxPreemptCandidates {
+		candidates = candidates[:maxPreemptCandidates]
+	}
+
+	indexes := make([]int, len(candidates))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	for k := 0; k <= len(candidates); k++ {
+		var best []int
+		var bestMax, bestSum int64
+		combinations(indexes, k, func(picked []int) {
+			released := map[int]sets.Int{}
+			mergeBoardOrins(released, freed)
+			var max, sum int64 = math.MinInt32, 0
+			for _, i := range picked {
+				mergeBoardOrins(released, PodBoardOrins(candidates[i]))
+				p := int64(podPriority(candidates[i]))
+				if p > max {
+					max = p
+				}
+				sum += p
+			}
+			if best != nil && (max > bestMax || max == bestMax && sum >= bestSum) {
+				return
+			}
+			if res, _ := allocateBoards(allocator, releasedNodeInfo(ni, released), requests); res != nil {
+				best, bestMax, bestSum = append([]int{}, picked...), max, sum
+			}
+		})
+		if best != nil {
+			added := make([]types.UID, 0, len(best))
+			for _, i := range best {
+				added = append(added, candidates[i].UID)
+			}
+			return added, true
+		}
+	}
+	return nil, false
+}
+
+// releasedNodeInfo return a node info to allocate on, whose allocatable has
+// released orins of every board back, ni is not changed
+func releasedNodeInfo(ni *NodeInfo, released map[int]sets.Int) *NodeInfo {
+	allocatable := topo.NewBoardDetails()
+	for boardID, od := range ni.Allocatable {
+		newOd := topo.NewOrinDetails()
+		for _, o := range od.OrinSet().UnsortedList() {
+			newOd.Add(boardID, o)
+		}
+		allocatable[boardID] = newOd
+	}
+	for boardID, od := range boardDetails(released) {
+		if _, ok := ni.Total[boardID]; !ok {
+			continue
+		}
+		if _, ok := allocatable[boardID]; !ok {
+			allocatable[boardID] = topo.NewOrinDetails()
+		}
+		for o := range od {
+			allocatable[boardID].Add(boardID, o)
+		}
+	}
+	return &NodeInfo{Node: ni.Node, Allocatable: allocatable, PoweredOff: ni.PoweredOff, Links: ni.Links}
+}
+
+func mergeBoardOrins(dst, src map[int]sets.Int) {
+	for boardID, orins := range src {
+		if dst[boardID] == nil {
+			dst[boardID] = sets.NewInt()
+		}
+		dst[boardID].Insert(orins.UnsortedList()...)
+	}
+}
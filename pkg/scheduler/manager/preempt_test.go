@@ -0,0 +1,131 @@
+package manager
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+
+	v1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func priorityPod(name string, priority int32, board string, orins ...int) *v1.Pod {
+	limits := map[string]string{common.ExtendResouceTypeBoard: "1"}
+	for _, o := range orins {
+		limits[common.ExtendResouceTypeOrinPrefix+strconv.Itoa(o)] = "1"
+	}
+	pod := testPod(name, limits, nil)
+	pod.Spec.NodeName = "node-1"
+	pod.Spec.Priority = &priority
+	if board != "" {
+		pod.Annotations[common.AnnotationPodBindToBoard] = board
+	}
+	return pod
+}
+
+func TestPreempt(t *testing.T) {
+	// board 0 has orin 1 and 2, board 1 has orin 1 2 3 4
+	running := []*v1.Pod{
+		priorityPod("low-0", 1, "0", 1, 2),
+		priorityPod("low-1", 1, "1", 1),
+		priorityPod("lower-1", 0, "1", 2),
+		priorityPod("mid-1", 5, "1", 3, 4),
+		priorityPod("high-1", 20, "1", 4),
+	}
+	running[2].Labels = map[string]string{"app": "protected"}
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{Name: "protected", Namespace: "default"},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "protected"}},
+		},
+	}
+	testcases := []struct {
+		name     string
+		running  []*v1.Pod
+		pod      *v1.Pod
+		victims  []types.UID
+		pdbs     []*policyv1.PodDisruptionBudget
+		expected []types.UID
+		fit      bool
+	}{
+		{
+			name:     "1.fits without victims",
+			running:  running[:1],
+			pod:      priorityPod("p", 10, "", 1, 2),
+			expected: []types.UID{},
+			fit:      true,
+		},
+		{
+			name:     "2.fewest victims on a single board",
+			running:  running[:4],
+			pod:      priorityPod("p", 10, "", 1, 2),
+			expected: []types.UID{"low-0"},
+			fit:      true,
+		},
+		{
+			name:     "3.lower priority preferred",
+			running:  running[:4],
+			pod:      priorityPod("p", 10, "", 2),
+			expected: []types.UID{"lower-1"},
+			fit:      true,
+		},
+		{
+			name:     "4.victims of kube-scheduler are kept",
+			running:  running[:4],
+			pod:      priorityPod("p", 10, "", 2),
+			victims:  []types.UID{"low-0"},
+			expected: []types.UID{"low-0"},
+			fit:      true,
+		},
+		{
+			name:    "5.higher priority pods are not preempted",
+			running: []*v1.Pod{running[0], running[1], running[4]},
+			pod:     priorityPod("p", 10, "", 3, 4),
+		},
+		{
+			name:     "6.several pods freed for a board",
+			running:  running[1:4],
+			pod:      priorityPod("p", 10, "", 1, 2, 3),
+			expected: []types.UID{"lower-1", "low-1", "mid-1"},
+			fit:      true,
+		},
+		{
+			name:     "7.pods covered by a pdb are not added",
+			running:  running[:4],
+			pod:      priorityPod("p", 10, "", 2),
+			pdbs:     []*policyv1.PodDisruptionBudget{pdb},
+			expected: []types.UID{"low-0"},
+			fit:      true,
+		},
+	}
+	for _, tc := range testcases {
+		node := powerTestNode("")
+		mng := NewManager(NewScheduleCache(), fake.NewSimpleClientset(node))
+		mng.AddNode(node, tc.running...)
+		for _, p := range tc.pdbs {
+			mng.SetPodDisruptionBudget(p.Namespace+"/"+p.Name, p)
+		}
+		res := mng.Preempt(tc.pod, map[string][]types.UID{"node-1": tc.victims})
+		actual, ok := res["node-1"]
+		if ok != tc.fit {
+			t.Errorf("test case %s, expect fit %v, actual %v", tc.name, tc.fit, res)
+			continue
+		}
+		if ok && !sets.NewString(uidStrings(actual)...).Equal(sets.NewString(uidStrings(tc.expected)...)) {
+			t.Errorf("test case %s, expect victims %v, actual %v", tc.name, tc.expected, actual)
+		}
+	}
+}
+
+func uidStrings(uids []types.UID) []string {
+	res := []string{}
+	for _, uid := range uids {
+		res = append(res, string(uid))
+	}
+	return res
+}
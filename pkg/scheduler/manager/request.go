@@ -0,0 +1,212 @@
+package manager
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog/v2"
+)
+
+// ReasonInvalidOrinRequest is the pod event of an invalid multi-board request
+const ReasonInvalidOrinRequest = "InvalidOrinRequest"
+
+// OrinRequest is the orins a pod needs on one board, Orins are the indices
+// requested by superedge.io/device-orin-N, Count is the quantity of
+// superedge.io/device-orin and allocator chooses the indices of them
+type OrinRequest struct {
+	Orins sets.Int
+	Count int
+}
+
+func NewOrinRequest(orins sets.Int, count int) *OrinRequest {
+	return &OrinRequest{Orins: orins, Count: count}
+}
+
+// BoardRequest is one element of pod annotation superedge.io/pod-request-boards
+type BoardRequest struct {
+	Orins []int `json:"orins,omitempty"`
+	Count int   `json:"count,omitempty"`
+}
+
+// BuildOrinRequests return orin request of every board pod needs, a pod
+// without superedge.io/pod-request-boards needs one board, a pod with it
+// needs a different board per element, and must request only
+// superedge.io/device-orin with the sum of them
+func BuildOrinRequests(pod *v1.Pod) ([]*OrinRequest, error) {
+	val, ok := pod.Annotations[common.AnnotationPodRequestBoards]
+	if !ok {
+		return []*OrinRequest{BuildOrinRequest(pod)}, nil
+	}
+	boards := []*BoardRequest{}
+	if err := json.Unmarshal([]byte(val), &boards); err != nil {
+		return nil, fmt.Errorf("annotation %s: %v", common.AnnotationPodRequestBoards, err)
+	}
+	if len(boards) == 0 {
+		return nil, fmt.Errorf("annotation %s has no board", common.AnnotationPodRequestBoards)
+	}
+	if orins := BuildRequestOrinSet(pod); orins.Len() != 0 {
+		return nil, fmt.Errorf("pod with annotation %s can not request orins %v by %sN", common.AnnotationPodRequestBoards, orins.List(), common.ExtendResouceTypeOrinPrefix)
+	}
+	res := make([]*OrinRequest, 0, len(boards))
+	total := 0
+	for i, b := range boards {
+		orins := sets.NewInt(b.Orins...)
+		if b.Count < 0 || orins.Len() != len(b.Orins) || orins.Len()+b.Count == 0 {
+			return nil, fmt.Errorf("annotation %s: invalid board %d, it needs distinct orins or a positive count", common.AnnotationPodRequestBoards, i)
+		}
+		total += orins.Len() + b.Count
+		res = append(res, NewOrinRequest(orins, b.Count))
+	}
+	if count := BuildRequestOrinCount(pod); count != total {
+		return nil, fmt.Errorf("annotation %s needs %d orins, but pod requests %d %s", common.AnnotationPodRequestBoards, total, count, common.ExtendResouceTypeOrin)
+	}
+	return res, nil
+}
+
+// BuildOrinRequest return orin request of all containers of pod
+func BuildOrinRequest(pod *v1.Pod) *OrinRequest {
+	return NewOrinRequest(BuildRequestOrinSet(pod), BuildRequestOrinCount(pod))
+}
+
+func (r *OrinRequest) String() string {
+	return fmt.Sprintf("orins %v count %d", r.Orins.List(), r.Count)
+}
+
+// Fit return orins to allocate from free orins of a board, they are the
+// requested indices and the lowest free others for count, false if the
+// board does not fit
+func (r *OrinRequest) Fit(free sets.Int) (sets.Int, bool) {
+	if !free.IsSuperset(r.Orins) {
+		return nil, false
+	}
+	others := free.Difference(r.Orins).List()
+	if len(others) < r.Count {
+		return nil, false
+	}
+	return sets.NewInt(others[:r.Count]...).Union(r.Orins), true
+}
+
+// BuildRequestOrinCount return the quantity of superedge.io/device-orin of
+// all containers
+func BuildRequestOrinCount(pod *v1.Pod) int {
+	count := 0
+	for _, c := range pod.Spec.Containers {
+		if q, ok := c.Resources.Limits[v1.ResourceName(common.ExtendResouceTypeOrin)]; ok {
+			count += int(q.Value())
+		}
+	}
+	return count
+}
+
+// PodBoundOrins return orins chosen by scheduler extender for count request
+// of pod
+func PodBoundOrins(pod *v1.Pod) sets.Int {
+	res := sets.NewInt()
+	val, ok := pod.Annotations[common.AnnotationPodBindOrins]
+	if !ok || val == "" {
+		return res
+	}
+	for _, s := range strings.Split(val, ",") {
+		orinID, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil {
+			klog.ErrorS(err, "find a invalid pod bind orin", "pod", klog.KObj(pod), "annotation value", val)
+			continue
+		}
+		res.Insert(orinID)
+	}
+	return res
+}
+
+// PodOrinSet return every orin pod holds on its bound board
+func PodOrinSet(pod *v1.Pod) sets.Int {
+	return BuildRequestOrinSet(pod).Union(PodBoundOrins(pod))
+}
+
+// PodBoardOrins return every orin pod holds on every bound board, empty if
+// pod is not bound
+func PodBoardOrins(pod *v1.Pod) map[int]sets.Int {
+	if _, ok := pod.Annotations[common.AnnotationPodBindBoards]; ok {
+		return podBindBoards(pod)
+	}
+	res := map[int]sets.Int{}
+	val, ok := pod.Annotations[common.AnnotationPodBindToBoard]
+	if !ok {
+		return res
+	}
+	boardID, err := strconv.Atoi(val)
+	if err != nil {
+		klog.ErrorS(err, "find a invalid pod bind boardID", "pod", klog.KObj(pod), "annotation value", val)
+		return res
+	}
+	res[boardID] = PodOrinSet(pod)
+	return res
+}
+
+// PodCountOrins return orins of superedge.io/device-orin of pod on every
+// bound board
+func PodCountOrins(pod *v1.Pod) map[int]sets.Int {
+	if _, ok := pod.Annotations[common.AnnotationPodBindBoards]; ok {
+		return podBindBoards(pod)
+	}
+	res := map[int]sets.Int{}
+	if boardID, err := strconv.Atoi(pod.Annotations[common.AnnotationPodBindToBoard]); err == nil {
+		res[boardID] = PodBoundOrins(pod)
+	}
+	return res
+}
+
+func podBindBoards(pod *v1.Pod) map[int]sets.Int {
+	res := map[int]sets.Int{}
+	val := pod.Annotations[common.AnnotationPodBindBoards]
+	boards := map[int][]int{}
+	if err := json.Unmarshal([]byte(val), &boards); err != nil {
+		klog.ErrorS(err, "find a invalid pod bind boards", "pod", klog.KObj(pod), "annotation value", val)
+		return res
+	}
+	for boardID, orins := range boards {
+		res[boardID] = sets.NewInt(orins...)
+	}
+	return res
+}
+
+// FormatBoardOrins return orins of every board in the format of pod bind
+// boards annotation
+func FormatBoardOrins(boards map[int]sets.Int) string {
+	res := make(map[int][]int, len(boards))
+	for boardID, orins := range boards {
+		res[boardID] = orins.List()
+	}
+	data, _ := json.Marshal(res)
+	return string(data)
+}
+
+// DescribeBoardOrins return orins of every board for messages, in order of
+// board
+func DescribeBoardOrins(boards map[int]sets.Int) string {
+	boardIDs := make([]int, 0, len(boards))
+	for boardID := range boards {
+		boardIDs = append(boardIDs, boardID)
+	}
+	sort.Ints(boardIDs)
+	res := make([]string, 0, len(boardIDs))
+	for _, boardID := range boardIDs {
+		res = append(res, fmt.Sprintf("orin %v on board %d", boards[boardID].List(), boardID))
+	}
+	return strings.Join(res, ", ")
+}
+
+// FormatOrins return orins in the format of pod bind orins annotation
+func FormatOrins(orins sets.Int) string {
+	res := make([]string, 0, orins.Len())
+	for _, orinID := range orins.List() {
+		res = append(res, strconv.Itoa(orinID))
+	}
+	return strings.Join(res, ",")
+}
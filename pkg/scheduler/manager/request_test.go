@@ -0,0 +1,206 @@
+package manager
+
+import (
+	"testing"
+
+	"github.com/superedge/orin-device-system/pkg/common"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestOrinRequestFit(t *testing.T) {
+	testcases := []struct {
+		name     string
+		request  *OrinRequest
+		free     sets.Int
+		expected sets.Int
+	}{
+		{
+			name:     "1.specific orins",
+			request:  NewOrinRequest(sets.NewInt(1, 3), 0),
+			free:     sets.NewInt(1, 2, 3),
+			expected: sets.NewInt(1, 3),
+		},
+		{
+			name:    "2.specific orin not free",
+			request: NewOrinRequest(sets.NewInt(4), 0),
+			free:    sets.NewInt(1, 2, 3),
+		},
+		{
+			name:     "3.count chooses the lowest free orins",
+			request:  NewOrinRequest(sets.NewInt(), 2),
+			free:     sets.NewInt(4, 2, 3),
+			expected: sets.NewInt(2, 3),
+		},
+		{
+			name:     "4.count does not choose specific orins",
+			request:  NewOrinRequest(sets.NewInt(2), 2),
+			free:     sets.NewInt(1, 2, 3),
+			expected: sets.NewInt(1, 2, 3),
+		},
+		{
+			name:    "5.count not enough",
+			request: NewOrinRequest(sets.NewInt(2), 2),
+			free:    sets.NewInt(2, 3),
+		},
+	}
+	for _, tc := range testcases {
+		orins, ok := tc.request.Fit(tc.free)
+		if ok != (tc.expected != nil) || (ok && !orins.Equal(tc.expected)) {
+			t.Errorf("test case %s, expect %v, actual %v %v", tc.name, tc.expected, orins, ok)
+		}
+	}
+}
+
+func TestBindOrinCount(t *testing.T) {
+	// board 0 has orin 1 and 2, board 1 has orin 1 2 3 4
+	node := powerTestNode("")
+	count, _ := resource.ParseQuantity("2")
+	one, _ := resource.ParseQuantity("1")
+	pod := testPod("pod-1", map[string]string{common.ExtendResouceTypeOrinPrefix + "1": "1", common.ExtendResouceTypeOrin: "2"}, nil)
+	if !IsOrinPod(&v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{{Resources: v1.ResourceRequirements{Limits: v1.ResourceList{
+		v1.ResourceName(common.ExtendResouceTypeBoard): one,
+		v1.ResourceName(common.ExtendResouceTypeOrin):  count,
+	}}}}}}) {
+		t.Fatalf("expect pod with orin count is orin pod")
+	}
+	clientset := fake.NewSimpleClientset(node, pod)
+	mng := NewManager(NewScheduleCache(), clientset)
+	mng.AddNode(node)
+
+	if err := mng.Bind("node-1", pod.Name, pod.Namespace, pod.UID); err != nil {
+		t.Fatal(err)
+	}
+	// fake clientset stores the binding as the pod, check the assumed pod
+	bound := mng.GetNode("node-1").Pods[pod.UID]
+	// only board 1 has orin 1 and two others
+	if bound.Annotations[common.AnnotationPodBindToBoard] != "1" || bound.Annotations[common.AnnotationPodBindOrins] != "2,3" {
+		t.Fatalf("unexpected bind annotations %v", bound.Annotations)
+	}
+	if od := mng.GetNode("node-1").Allocatable[1]; !od.OrinSet().Equal(sets.NewInt(4)) {
+		t.Fatalf("expect orin 4 of board 1 allocatable, actual %v", od.OrinSet().List())
+	}
+	// the same count request fits no board now
+	if nodes, _, _ := mng.Predicate([]string{"node-1"}, pod); len(nodes) != 0 {
+		t.Fatalf("expect node-1 full, actual %v", nodes)
+	}
+}
+
+func multiBoardPod(boards string, count string) *v1.Pod {
+	return testPod("pod-1", map[string]string{common.ExtendResouceTypeOrin: count},
+		map[string]string{common.AnnotationPodRequestBoards: boards})
+}
+
+func TestBuildOrinRequests(t *testing.T) {
+	testcases := []struct {
+		name        string
+		pod         *v1.Pod
+		expected    []*OrinRequest
+		expectError bool
+	}{
+		{
+			name:     "1.one board and count",
+			pod:      multiBoardPod(`[{"count":4},{"orins":[1]}]`, "5"),
+			expected: []*OrinRequest{NewOrinRequest(sets.NewInt(), 4), NewOrinRequest(sets.NewInt(1), 0)},
+		},
+		{
+			name:        "2.count mismatch",
+			pod:         multiBoardPod(`[{"count":4},{"orins":[1]}]`, "4"),
+			expectError: true,
+		},
+		{
+			name:        "3.empty board",
+			pod:         multiBoardPod(`[{"count":2},{}]`, "2"),
+			expectError: true,
+		},
+		{
+			name:        "4.invalid json",
+			pod:         multiBoardPod(`{"count":2}`, "2"),
+			expectError: true,
+		},
+	}
+	for _, tc := range testcases {
+		actual, err := BuildOrinRequests(tc.pod)
+		if (err != nil) != tc.expectError {
+			t.Errorf("test case %s, expect error %v, actual %v", tc.name, tc.expectError, err)
+			continue
+		}
+		if len(actual) != len(tc.expected) {
+			t.Errorf("test case %s, expect %v, actual %v", tc.name, tc.expected, actual)
+			continue
+		}
+		for i := range actual {
+			if !actual[i].Orins.Equal(tc.expected[i].Orins) || actual[i].Count != tc.expected[i].Count {
+				t.Errorf("test case %s, expect %v, actual %v", tc.name, tc.expected[i], actual[i])
+			}
+		}
+	}
+}
+
+func TestBindMultiBoard(t *testing.T) {
+	// board 0 has orin 1 and 2, board 1 has orin 1 2 3 4
+	testcases := []struct {
+		name              string
+		boards            string
+		count             string
+		policy            string
+		expectBoard       string
+		expectBoards      string
+		expectAllocatable map[int]sets.Int
+	}{
+		{
+			name:              "1.full board and one orin of another",
+			boards:            `[{"count":4},{"orins":[1]}]`,
+			count:             "5",
+			expectBoard:       "1",
+			expectBoards:      `{"0":[1],"1":[1,2,3,4]}`,
+			expectAllocatable: map[int]sets.Int{0: sets.NewInt(2), 1: sets.NewInt()},
+		},
+		{
+			name:              "2.first board chosen by spread does not leave room",
+			boards:            `[{"count":1},{"count":3}]`,
+			count:             "4",
+			policy:            AllocatorPolicySpread,
+			expectBoard:       "0",
+			expectBoards:      `{"0":[1],"1":[1,2,3]}`,
+			expectAllocatable: map[int]sets.Int{0: sets.NewInt(2), 1: sets.NewInt(4)},
+		},
+	}
+	for _, tc := range testcases {
+		node := powerTestNode("")
+		pod := multiBoardPod(tc.boards, tc.count)
+		if tc.policy != "" {
+			pod.Annotations[common.AnnotationPodBindOrinPolicy] = tc.policy
+		}
+		clientset := fake.NewSimpleClientset(node, pod)
+		mng := NewManager(NewScheduleCache(), clientset)
+		mng.AddNode(node)
+
+		if nodes, _, _ := mng.Predicate([]string{"node-1"}, pod); len(nodes) != 1 {
+			t.Fatalf("test case %s, expect node-1 fits, actual %v", tc.name, nodes)
+		}
+		if err := mng.Bind("node-1", pod.Name, pod.Namespace, pod.UID); err != nil {
+			t.Fatalf("test case %s, %v", tc.name, err)
+		}
+		bound := mng.GetNode("node-1").Pods[pod.UID]
+		if bound.Annotations[common.AnnotationPodBindToBoard] != tc.expectBoard || bound.Annotations[common.AnnotationPodBindBoards] != tc.expectBoards {
+			t.Fatalf("test case %s, unexpected bind annotations %v", tc.name, bound.Annotations)
+		}
+		for boardID, orins := range tc.expectAllocatable {
+			if actual := mng.GetNode("node-1").Allocatable[boardID].OrinSet(); !actual.Equal(orins) {
+				t.Errorf("test case %s, expect board %d allocatable %v, actual %v", tc.name, boardID, orins.List(), actual.List())
+			}
+		}
+		// the informer sees the pod on node after binding
+		deleted := bound.DeepCopy()
+		deleted.Spec.NodeName = "node-1"
+		if err := mng.DeletePod(deleted); err != nil {
+			t.Fatalf("test case %s, %v", tc.name, err)
+		}
+		if !mng.GetNode("node-1").Allocatable.Equal(mng.GetNode("node-1").Total) {
+			t.Errorf("test case %s, expect all orins released, actual %v", tc.name, mng.GetNode("node-1").Allocatable)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package manager
+
+// SocTelemetry is the compact telemetry of one soc in node annotation, values
+// are rounded so the annotation changes only on a real difference
+type SocTelemetry struct {
+	// GPU is gpu utilization in percent
+	GPU int `json:"gpu"`
+	// Memory is used memory in percent
+	Memory int `json:"mem"`
+	// Temperature is the highest thermal zone in celsius
+	Temperature int `json:"temp"`
+	// Power is the sum of all rails in milliwatts
+	Power int `json:"power"`
+}
@@ -0,0 +1,75 @@
+package topo
+
+import (
+	"math"
+)
+
+// Link is a direct interconnect between two orins of one board, like a
+// shared pcie switch or a fast ethernet link
+type Link struct {
+	A int `json:"a"`
+	B int `json:"b"`
+	// BandwidthMbps is the link bandwidth in Mbit/s
+	BandwidthMbps int `json:"bw"`
+	// LatencyMicros is the link latency in microseconds
+	LatencyMicros int `json:"lat,omitempty"`
+}
+
+// Interconnect is board id to links between its orins, orins without a link
+// talk through the default network
+type Interconnect map[int][]Link
+
+// link return the link between orin a and b of board
+func (ic Interconnect) link(boardID, a, b int) (Link, bool) {
+	for _, l := range ic[boardID] {
+		if (l.A == a && l.B == b) || (l.A == b && l.B == a) {
+			return l, true
+		}
+	}
+	return Link{}, false
+}
+
+// Connectivity of a set of orins on one board, the slowest pair bounds
+// collective traffic of the set
+type Connectivity struct {
+	// MinBandwidthMbps is the lowest bandwidth of every pair, zero if some
+	// pair has no link
+	MinBandwidthMbps int
+	// Unlinked is the number of pairs without a link
+	Unlinked int
+	// LatencyMicros is the sum of latency of linked pairs
+	LatencyMicros int
+}
+
+// Connectivity return connectivity of orins on board, a set of one orin has
+// the best connectivity
+func (ic Interconnect) Connectivity(boardID int, orins []int) Connectivity {
+	c := Connectivity{MinBandwidthMbps: math.MaxInt}
+	for i := range orins {
+		for j := i + 1; j < len(orins); j++ {
+			l, ok := ic.link(boardID, orins[i], orins[j])
+			if !ok {
+				c.Unlinked++
+				c.MinBandwidthMbps = 0
+				continue
+			}
+			if l.BandwidthMbps < c.MinBandwidthMbps {
+				c.MinBandwidthMbps = l.BandwidthMbps
+			}
+			c.LatencyMicros += l.LatencyMicros
+		}
+	}
+	return c
+}
+
+// Better return true if c is better than o, by higher bandwidth, fewer
+// unlinked pairs and lower latency in order
+func (c Connectivity) Better(o Connectivity) bool {
+	if c.MinBandwidthMbps != o.MinBandwidthMbps {
+		return c.MinBandwidthMbps > o.MinBandwidthMbps
+	}
+	if c.Unlinked != o.Unlinked {
+		return c.Unlinked < o.Unlinked
+	}
+	return c.LatencyMicros < o.LatencyMicros
+}
@@ -0,0 +1,35 @@
+package topo
+
+import (
+	"testing"
+)
+
+func TestConnectivity(t *testing.T) {
+	ic := Interconnect{0: {
+		{A: 1, B: 2, BandwidthMbps: 10000, LatencyMicros: 5},
+		{A: 3, B: 4, BandwidthMbps: 10000, LatencyMicros: 8},
+		{A: 2, B: 3, BandwidthMbps: 1000, LatencyMicros: 50},
+	}}
+	testcases := []struct {
+		name   string
+		orins  []int
+		expect Connectivity
+	}{
+		{name: "1.one orin", orins: []int{1}, expect: Connectivity{MinBandwidthMbps: 1<<63 - 1}},
+		{name: "2.linked pair", orins: []int{2, 1}, expect: Connectivity{MinBandwidthMbps: 10000, LatencyMicros: 5}},
+		{name: "3.slow pair bounds", orins: []int{1, 2, 3}, expect: Connectivity{MinBandwidthMbps: 0, Unlinked: 1, LatencyMicros: 55}},
+		{name: "4.unlinked pair", orins: []int{1, 4}, expect: Connectivity{MinBandwidthMbps: 0, Unlinked: 1}},
+	}
+	for _, tc := range testcases {
+		if actual := ic.Connectivity(0, tc.orins); actual != tc.expect {
+			t.Errorf("test case %s, expect %+v, actual %+v", tc.name, tc.expect, actual)
+		}
+	}
+
+	if !ic.Connectivity(0, []int{1, 2}).Better(ic.Connectivity(0, []int{3, 4})) {
+		t.Errorf("expect lower latency better")
+	}
+	if !ic.Connectivity(0, []int{3, 4}).Better(ic.Connectivity(0, []int{2, 3})) {
+		t.Errorf("expect higher bandwidth better")
+	}
+}
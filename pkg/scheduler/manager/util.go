@@ -87,6 +87,8 @@ func RemovePodBindAnnotation(oldPod *v1.Pod) *v1.Pod {
 		return newPod
 	}
 	delete(newPod.Annotations, common.AnnotationPodBindToBoard)
+	delete(newPod.Annotations, common.AnnotationPodBindOrins)
+	delete(newPod.Annotations, common.AnnotationPodBindBoards)
 	delete(newPod.Labels, common.AnnotationPodBindToBoard)
 	return newPod
 }
@@ -1,6 +1,8 @@
 package routes
 
 import (
+	"fmt"
+
 	"github.com/superedge/orin-device-system/pkg/scheduler/manager"
 	schedulerapi "k8s.io/kube-scheduler/extender/v1"
 )
@@ -8,9 +10,18 @@ import (
 type Bind struct {
 	Name    string
 	Manager manager.Manager
+	// IsLeader return false on standby replicas, which do not bind, nil
+	// means always bind
+	IsLeader func() bool
 }
 
 func (b Bind) Handler(args schedulerapi.ExtenderBindingArgs) *schedulerapi.ExtenderBindingResult {
+	if b.IsLeader != nil && !b.IsLeader() {
+		// kube-scheduler will schedule the pod again
+		return &schedulerapi.ExtenderBindingResult{
+			Error: fmt.Sprintf("%s is not the leader, can not bind pod %s/%s", b.Name, args.PodNamespace, args.PodName),
+		}
+	}
 	errMsg := ""
 	err := b.Manager.Bind(args.Node, args.PodName, args.PodNamespace, args.PodUID)
 	if err != nil {
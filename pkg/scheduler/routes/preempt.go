@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"github.com/superedge/orin-device-system/pkg/scheduler/manager"
+	"k8s.io/apimachinery/pkg/types"
+	schedulerapi "k8s.io/kube-scheduler/extender/v1"
+)
+
+type Preempt struct {
+	Name    string
+	Manager manager.Manager
+}
+
+func (p Preempt) Handler(args schedulerapi.ExtenderPreemptionArgs) *schedulerapi.ExtenderPreemptionResult {
+	// victims are sent as uid when extender is node cache capable, otherwise
+	// as pods
+	nodeVictims := map[string][]types.UID{}
+	violations := map[string]int64{}
+	if args.NodeNameToMetaVictims != nil {
+		for node, victims := range args.NodeNameToMetaVictims {
+			nodeVictims[node] = []types.UID{}
+			for _, pod := range victims.Pods {
+				nodeVictims[node] = append(nodeVictims[node], types.UID(pod.UID))
+			}
+			violations[node] = victims.NumPDBViolations
+		}
+	} else {
+		for node, victims := range args.NodeNameToVictims {
+			nodeVictims[node] = []types.UID{}
+			for _, pod := range victims.Pods {
+				nodeVictims[node] = append(nodeVictims[node], pod.UID)
+			}
+			violations[node] = victims.NumPDBViolations
+		}
+	}
+
+	result := &schedulerapi.ExtenderPreemptionResult{NodeNameToMetaVictims: map[string]*schedulerapi.MetaVictims{}}
+	for node, uids := range p.Manager.Preempt(args.Pod, nodeVictims) {
+		// added victims are never covered by a pdb, violations stay those of kube-scheduler
+		victims := &schedulerapi.MetaVictims{Pods: []*schedulerapi.MetaPod{}, NumPDBViolations: violations[node]}
+		for _, uid := range uids {
+			victims.Pods = append(victims.Pods, &schedulerapi.MetaPod{UID: string(uid)})
+		}
+		result.NodeNameToMetaVictims[node] = victims
+	}
+	return result
+}
+
+func NewPreempt(name string, m manager.Manager) Preempt {
+	return Preempt{Name: name, Manager: m}
+}
@@ -17,6 +17,9 @@ const (
 	bindPath         = apiPrefix + "/bind"
 	predicatesPrefix = apiPrefix + "/predicates"
 	prioritiesPrefix = apiPrefix + "/priorities"
+	preemptPath      = apiPrefix + "/preempt"
+	healthzPath      = "/healthz"
+	readyzPath       = "/readyz"
 )
 
 func checkBody(w http.ResponseWriter, r *http.Request) {
@@ -120,6 +123,35 @@ func BindRoute(bind Bind) httprouter.Handle {
 	}
 }
 
+func PreemptRoute(preempt Preempt) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		checkBody(w, r)
+
+		var buf bytes.Buffer
+		body := io.TeeReader(r.Body, &buf)
+		log.Print("info: ", preempt.Name, " ExtenderPreemptionArgs = ", buf.String())
+
+		var extenderPreemptionArgs schedulerapi.ExtenderPreemptionArgs
+		var extenderPreemptionResult *schedulerapi.ExtenderPreemptionResult
+
+		if err := json.NewDecoder(body).Decode(&extenderPreemptionArgs); err != nil {
+			// no node is left for preemption
+			extenderPreemptionResult = &schedulerapi.ExtenderPreemptionResult{}
+		} else {
+			extenderPreemptionResult = preempt.Handler(extenderPreemptionArgs)
+		}
+
+		if resultBody, err := json.Marshal(extenderPreemptionResult); err != nil {
+			panic(err)
+		} else {
+			log.Print("info: ", preempt.Name, " extenderPreemptionResult = ", string(resultBody))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write(resultBody)
+		}
+	}
+}
+
 func DebugLogging(h httprouter.Handle, path string) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
 		log.Print("debug: ", path, " request body = ", r.Body)
@@ -142,3 +174,24 @@ func AddBind(router *httprouter.Router, bind Bind) {
 	path := bindPath
 	router.POST(path, DebugLogging(BindRoute(bind), path))
 }
+
+func AddPreempt(router *httprouter.Router, preempt Preempt) {
+	path := preemptPath
+	router.POST(path, DebugLogging(PreemptRoute(preempt), path))
+}
+
+// AddHealth add /healthz, and /readyz which succeeds when ready return nil
+func AddHealth(router *httprouter.Router, ready func() error) {
+	router.GET(healthzPath, func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+	router.GET(readyzPath, func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		if err := ready(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+}